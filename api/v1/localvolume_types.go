@@ -19,6 +19,8 @@ package v1
 import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,6 +41,61 @@ type LocalVolumeSpec struct {
 	// If specified, a list of tolerations to pass to the diskmaker and provisioner DaemonSets.
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// PVLabelPolicy adds attributes of the devices as labels to the PersistentVolumes created for them.
+	// If it is not specified, no device attributes are added.
+	// +optional
+	PVLabelPolicy *PVLabelPolicy `json:"pvLabelPolicy,omitempty"`
+	// PVTopology copies labels of the nodes onto the PersistentVolumes created on them.
+	// +optional
+	PVTopology *PVTopology `json:"pvTopology,omitempty"`
+}
+
+// PVTopology copies node labels onto the PersistentVolumes created on the node, so that the
+// failure domain of a PersistentVolume is known without looking up its node
+type PVTopology struct {
+	// NodeLabels are the keys of the node labels to copy, for example "topology.kubernetes.io/zone"
+	// or "topology.kubernetes.io/rack". Labels that a node does not have are skipped.
+	// Copied labels take precedence over pvLabels and pvLabelPolicy labels with the same key.
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=16
+	NodeLabels []string `json:"nodeLabels"`
+	// AddToNodeAffinity also requires the copied labels in the node affinity of new PersistentVolumes,
+	// next to the hostname. The node affinity of existing PersistentVolumes is immutable and not changed.
+	// +optional
+	AddToNodeAffinity bool `json:"addToNodeAffinity,omitempty"`
+}
+
+// PVLabelAttribute is a device attribute that can be added as label to PersistentVolumes
+// +kubebuilder:validation:Enum=Rotational;Transport;Vendor;Model;SizeBucket;Slot
+type PVLabelAttribute string
+
+const (
+	// PVLabelRotational adds storage.openshift.com/device-rotational, "true" or "false"
+	PVLabelRotational PVLabelAttribute = "Rotational"
+	// PVLabelTransport adds storage.openshift.com/device-transport, e.g. "nvme", "sata" or "sas"
+	PVLabelTransport PVLabelAttribute = "Transport"
+	// PVLabelVendor adds storage.openshift.com/device-vendor
+	PVLabelVendor PVLabelAttribute = "Vendor"
+	// PVLabelModel adds storage.openshift.com/device-model
+	PVLabelModel PVLabelAttribute = "Model"
+	// PVLabelSizeBucket adds storage.openshift.com/device-size-bucket, the device size rounded
+	// up to a power of two, e.g. "1Ti" for a 960GB device or "4Ti" for a 3.84TB device
+	PVLabelSizeBucket PVLabelAttribute = "SizeBucket"
+	// PVLabelSlot adds storage.openshift.com/device-slot, the first /dev/disk/by-path link name of the device
+	PVLabelSlot PVLabelAttribute = "Slot"
+)
+
+// PVLabelPolicy adds attributes of the devices as labels to the PersistentVolumes created for them,
+// so that PersistentVolumeClaims can select volumes of one storage class by them with spec.selector.
+// Values are normalized to valid label values: characters other than alphanumerics, '-', '_' and '.'
+// are replaced by '_', and leading or trailing non-alphanumerics are removed. Attributes that are
+// unknown for a device are not added.
+type PVLabelPolicy struct {
+	// Attributes are the device attributes to add as labels.
+	// If it is empty, all attributes are added.
+	// +optional
+	// +listType=set
+	Attributes []PVLabelAttribute `json:"attributes,omitempty"`
 }
 
 // PersistentVolumeMode describes how a volume is intended to be consumed, either Block or Filesystem.
@@ -63,10 +120,159 @@ type StorageClassDevice struct {
 	FSType string `json:"fsType,omitempty"`
 	// A list of device paths which would be chosen for local storage.
 	// For example - ["/dev/sda", "/dev/sdb", "/dev/disk/by-id/ata-crucial"]
+	// Paths may be glob patterns following filepath.Match syntax, which are expanded on every node,
+	// for example "/dev/disk/by-path/pci-0000:3b:00.0-nvme-*". When several paths that a pattern
+	// expands to point to the same device, only the first one in lexical order is used.
 	DevicePaths []string `json:"devicePaths,omitempty"`
+	// NodeDevicePaths maps nodes to additional device paths that are only used on those nodes.
+	// Keys are node names, for example "worker-3", or label selectors in kubectl syntax prefixed with
+	// "selector:", for example "selector:topology.kubernetes.io/zone=rack-1" or
+	// "selector:node-role.kubernetes.io/storage". A key matches a node if it equals the node name or if
+	// its selector matches the node labels. Keys that are neither are reported as invalid and ignored.
+	// The paths of all matching keys are added to DevicePaths and may be glob patterns as well.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=64
+	NodeDevicePaths map[string][]string `json:"nodeDevicePaths,omitempty"`
+	// Devices lists device paths with settings that override those of the StorageClassDevice for
+	// the matched devices. The paths are added to DevicePaths and may be glob patterns as well.
+	// A device path listed in DevicePaths or NodeDevicePaths uses the overrides of the first entry
+	// whose path points to the same device.
+	// +optional
+	// +kubebuilder:validation:MaxItems=256
+	Devices []DeviceOverride `json:"devices,omitempty"`
 	// This option will destroy all leftover data on the devices before they're used as PersistentVolumes. Use with care.
 	// +optional
 	ForceWipeDevicesAndDestroyAllData bool `json:"forceWipeDevicesAndDestroyAllData,omitempty"`
+	// StorageClassTemplate customizes the StorageClass created for StorageClassName.
+	// +optional
+	StorageClassTemplate *StorageClassTemplate `json:"storageClassTemplate,omitempty"`
+	// Partitioning splits the matched disks into GPT partitions that are provisioned as separate PersistentVolumes.
+	// The settings of Devices apply to all partitions of a disk, except for the claimRef.
+	// +optional
+	Partitioning *Partitioning `json:"partitioning,omitempty"`
+}
+
+// Partitioning splits empty whole disks into GPT partitions, each of which is provisioned as its own
+// PersistentVolume. The partitions are named with the prefix "lso-part-", which marks them as created
+// by the operator. Disks that already carry a partition table, a filesystem or partitions are not
+// partitioned, unless a LocalVolume force wipes them. Disks of a LocalVolumeSet with a filesystem that
+// its provisioning policy allows are provisioned as they are.
+// +kubebuilder:validation:XValidation:rule="has(self.count) != has(self.partitionSize)",message="exactly one of count and partitionSize must be set"
+type Partitioning struct {
+	// Count splits each disk into this number of partitions of equal size.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=128
+	Count *int32 `json:"count,omitempty"`
+	// PartitionSize splits each disk into as many partitions of this size as fit, up to 128.
+	// It is rounded down to a multiple of 1Mi. Space that is left over stays unused.
+	// +optional
+	PartitionSize *resource.Quantity `json:"partitionSize,omitempty"`
+}
+
+// StorageClassTemplate customizes a StorageClass created by the operator.
+// The operator reverts changes to these fields that are made to the StorageClass directly.
+type StorageClassTemplate struct {
+	// ReclaimPolicy of the StorageClass and of the PersistentVolumes created for it. Defaults to Delete.
+	// Changing it requires the StorageClass to be recreated, see AllowRecreate.
+	// Existing PersistentVolumes keep their reclaim policy.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Retain
+	ReclaimPolicy *corev1.PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+	// VolumeBindingMode of the StorageClass. Defaults to WaitForFirstConsumer.
+	// Changing it requires the StorageClass to be recreated, see AllowRecreate.
+	// +optional
+	// +kubebuilder:validation:Enum=WaitForFirstConsumer;Immediate
+	VolumeBindingMode *storagev1.VolumeBindingMode `json:"volumeBindingMode,omitempty"`
+	// MountOptions of the StorageClass, used when Filesystem volumes are mounted
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty"`
+	// Labels are added to the StorageClass. The labels identifying the owner take precedence.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the StorageClass.
+	// Labels and annotations that are removed from the template are removed from the StorageClass.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// IsDefaultClass makes the StorageClass the default StorageClass of the cluster.
+	// When it is cleared again, the StorageClass stops being the default.
+	// +optional
+	IsDefaultClass bool `json:"isDefaultClass,omitempty"`
+	// PopulateAllowedTopologies restricts the allowedTopologies of the StorageClass to the nodes
+	// that hold PersistentVolumes of the StorageClass, so that pods with claims that no node can
+	// satisfy fail to schedule instead of waiting for a PersistentVolume.
+	// +optional
+	PopulateAllowedTopologies bool `json:"populateAllowedTopologies,omitempty"`
+	// AllowedTopologiesUpdateInterval is the minimum time between two updates of the allowedTopologies
+	// of the StorageClass when PopulateAllowedTopologies is set. Defaults to 10m.
+	// +optional
+	AllowedTopologiesUpdateInterval *metav1.Duration `json:"allowedTopologiesUpdateInterval,omitempty"`
+	// AllowRecreate allows the operator to delete and recreate the StorageClass when its ReclaimPolicy
+	// or VolumeBindingMode differ from the template, because they can't be updated. Until the StorageClass
+	// is created again, claims that use it can't be bound. Without it, the StorageClass is left as it is
+	// and the owner reports the difference in its Available condition.
+	// +optional
+	AllowRecreate bool `json:"allowRecreate,omitempty"`
+}
+
+// DeviceOverride selects devices by path and overrides settings of the StorageClassDevice for them
+// +kubebuilder:validation:XValidation:rule="!has(self.claimRef) || !self.path.matches('[*?[]')",message="claimRef can't be set for glob patterns"
+type DeviceOverride struct {
+	// Path of the device, for example "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4".
+	// May be a glob pattern following filepath.Match syntax.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+	// FSType overrides the file system type of the StorageClassDevice
+	// +optional
+	FSType string `json:"fsType,omitempty"`
+	// VolumeMode overrides the volume mode of the StorageClassDevice
+	// +optional
+	// +kubebuilder:validation:Enum=Block;Filesystem
+	VolumeMode PersistentVolumeMode `json:"volumeMode,omitempty"`
+	// PVLabels are added to the labels of the PersistentVolumes created for the devices.
+	// Labels set by the operator take precedence.
+	// +optional
+	PVLabels map[string]string `json:"pvLabels,omitempty"`
+	// PVAnnotations are added to the annotations of the PersistentVolumes created for the devices.
+	// Annotations set by the operator take precedence.
+	// +optional
+	PVAnnotations map[string]string `json:"pvAnnotations,omitempty"`
+	// ForceWipe overrides ForceWipeDevicesAndDestroyAllData of the StorageClassDevice.
+	// This option will destroy all leftover data on the devices before they're used as PersistentVolumes. Use with care.
+	// +optional
+	ForceWipe *bool `json:"forceWipe,omitempty"`
+	// ClaimRef pre-binds the PersistentVolume created for the device to a PersistentVolumeClaim,
+	// so that no other claim can be bound to it. Devices whose claim is referenced by another device
+	// as well, or is bound or reserved by a PersistentVolume of someone else, are not provisioned;
+	// the conflict is reported in the status of the LocalVolume. Removing the claimRef removes the
+	// reservation from a PersistentVolume that is not bound yet.
+	// +optional
+	ClaimRef *PVClaimRef `json:"claimRef,omitempty"`
+}
+
+// PVClaimRef references the PersistentVolumeClaim a PersistentVolume is reserved for
+type PVClaimRef struct {
+	// Namespace of the PersistentVolumeClaim
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// Name of the PersistentVolumeClaim
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// String returns the claim as namespace/name
+func (c PVClaimRef) String() string {
+	return c.Namespace + "/" + c.Name
+}
+
+// ClaimRefConflict is a claimRef of the devices of a LocalVolume that was rejected
+type ClaimRefConflict struct {
+	// ClaimRef is the rejected claim
+	ClaimRef PVClaimRef `json:"claimRef"`
+	// Paths of the devices of the LocalVolume that reference the claim
+	Paths []string `json:"paths"`
+	// Message describes the conflict
+	Message string `json:"message"`
 }
 
 // LocalVolumeStatus defines the observed state of LocalVolume
@@ -87,6 +293,9 @@ type LocalVolumeStatus struct {
 	// generations are used to determine when an item needs to be reconciled or has changed in a way that needs a reaction.
 	// +optional
 	Generations []operatorv1.GenerationStatus `json:"generations,omitempty"`
+	// ClaimRefConflicts lists the claimRefs of the devices that were rejected.
+	// +optional
+	ClaimRefConflicts []ClaimRefConflict `json:"claimRefConflicts,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -23,9 +23,71 @@ package v1
 import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaimRefConflict) DeepCopyInto(out *ClaimRefConflict) {
+	*out = *in
+	out.ClaimRef = in.ClaimRef
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimRefConflict.
+func (in *ClaimRefConflict) DeepCopy() *ClaimRefConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaimRefConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceOverride) DeepCopyInto(out *DeviceOverride) {
+	*out = *in
+	if in.PVLabels != nil {
+		in, out := &in.PVLabels, &out.PVLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PVAnnotations != nil {
+		in, out := &in.PVAnnotations, &out.PVAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ForceWipe != nil {
+		in, out := &in.ForceWipe, &out.ForceWipe
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClaimRef != nil {
+		in, out := &in.ClaimRef, &out.ClaimRef
+		*out = new(PVClaimRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceOverride.
+func (in *DeviceOverride) DeepCopy() *DeviceOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolume) DeepCopyInto(out *LocalVolume) {
 	*out = *in
@@ -208,6 +270,16 @@ func (in *LocalVolumeSpec) DeepCopyInto(out *LocalVolumeSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PVLabelPolicy != nil {
+		in, out := &in.PVLabelPolicy, &out.PVLabelPolicy
+		*out = new(PVLabelPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVTopology != nil {
+		in, out := &in.PVTopology, &out.PVTopology
+		*out = new(PVTopology)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSpec.
@@ -240,6 +312,13 @@ func (in *LocalVolumeStatus) DeepCopyInto(out *LocalVolumeStatus) {
 		*out = make([]operatorv1.GenerationStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClaimRefConflicts != nil {
+		in, out := &in.ClaimRefConflicts, &out.ClaimRefConflicts
+		*out = make([]ClaimRefConflict, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeStatus.
@@ -252,6 +331,86 @@ func (in *LocalVolumeStatus) DeepCopy() *LocalVolumeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVClaimRef) DeepCopyInto(out *PVClaimRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVClaimRef.
+func (in *PVClaimRef) DeepCopy() *PVClaimRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PVClaimRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVLabelPolicy) DeepCopyInto(out *PVLabelPolicy) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make([]PVLabelAttribute, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVLabelPolicy.
+func (in *PVLabelPolicy) DeepCopy() *PVLabelPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PVLabelPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVTopology) DeepCopyInto(out *PVTopology) {
+	*out = *in
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVTopology.
+func (in *PVTopology) DeepCopy() *PVTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(PVTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Partitioning) DeepCopyInto(out *Partitioning) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PartitionSize != nil {
+		in, out := &in.PartitionSize, &out.PartitionSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Partitioning.
+func (in *Partitioning) DeepCopy() *Partitioning {
+	if in == nil {
+		return nil
+	}
+	out := new(Partitioning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageClassDevice) DeepCopyInto(out *StorageClassDevice) {
 	*out = *in
@@ -260,6 +419,39 @@ func (in *StorageClassDevice) DeepCopyInto(out *StorageClassDevice) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NodeDevicePaths != nil {
+		in, out := &in.NodeDevicePaths, &out.NodeDevicePaths
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]DeviceOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageClassTemplate != nil {
+		in, out := &in.StorageClassTemplate, &out.StorageClassTemplate
+		*out = new(StorageClassTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partitioning != nil {
+		in, out := &in.Partitioning, &out.Partitioning
+		*out = new(Partitioning)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassDevice.
@@ -271,3 +463,52 @@ func (in *StorageClassDevice) DeepCopy() *StorageClassDevice {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassTemplate) DeepCopyInto(out *StorageClassTemplate) {
+	*out = *in
+	if in.ReclaimPolicy != nil {
+		in, out := &in.ReclaimPolicy, &out.ReclaimPolicy
+		*out = new(corev1.PersistentVolumeReclaimPolicy)
+		**out = **in
+	}
+	if in.VolumeBindingMode != nil {
+		in, out := &in.VolumeBindingMode, &out.VolumeBindingMode
+		*out = new(storagev1.VolumeBindingMode)
+		**out = **in
+	}
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AllowedTopologiesUpdateInterval != nil {
+		in, out := &in.AllowedTopologiesUpdateInterval, &out.AllowedTopologiesUpdateInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassTemplate.
+func (in *StorageClassTemplate) DeepCopy() *StorageClassTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LocalVolumeDeviceBlocklistSpec lists devices that the local storage operator must never
+// use on any node. A device is blocked if any of its identifiers is listed.
+type LocalVolumeDeviceBlocklistSpec struct {
+	// serials is a list of device serial numbers, as reported by lsblk.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=256
+	Serials []string `json:"serials,omitempty"`
+	// wwns is a list of device World Wide Names, as reported by lsblk,
+	// e.g. "0x5000c500a1b2c3d4". Matching is case insensitive.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=256
+	WWNs []string `json:"wwns,omitempty"`
+	// byIDNames is a list of symlink names in /dev/disk/by-id, e.g. "wwn-0x5000c500a1b2c3d4".
+	// Full paths are accepted too.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=256
+	ByIDNames []string `json:"byIDNames,omitempty"`
+	// modelRegexes is a list of regular expressions matched against the device model.
+	// The expressions are not anchored, use ^ and $ to match the whole model.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=64
+	ModelRegexes []string `json:"modelRegexes,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=localvolumedeviceblocklists,scope=Cluster
+// LocalVolumeDeviceBlocklist is the Schema for the localvolumedeviceblocklists API
+type LocalVolumeDeviceBlocklist struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LocalVolumeDeviceBlocklistSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LocalVolumeDeviceBlocklistList contains a list of LocalVolumeDeviceBlocklist
+type LocalVolumeDeviceBlocklistList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeDeviceBlocklist `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeDeviceBlocklist{}, &LocalVolumeDeviceBlocklistList{})
+}
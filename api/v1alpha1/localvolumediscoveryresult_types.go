@@ -60,6 +60,10 @@ type DiscoveredDevice struct {
 	FSType string `json:"fstype"`
 	// Status defines whether the device is available for use or not
 	Status DeviceStatus `json:"status"`
+	// Owner describes what is likely using a device that is not available,
+	// for example "Ceph OSD (bluestore)", "LVM PV of VG foo" or "mdraid member"
+	// +optional
+	Owner string `json:"owner,omitempty"`
 }
 
 // LocalVolumeDiscoveryResultSpec defines the desired state of LocalVolumeDiscoveryResult
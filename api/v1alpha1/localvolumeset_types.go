@@ -47,13 +47,44 @@ const (
 	Loop DeviceType = "loop"
 	// Multipath device type
 	MultiPath DeviceType = "mpath"
+	// LVM represents a device-type of LVM logical volume
+	LVM DeviceType = "lvm"
+)
+
+// ZonedModel is the zoned model of a device as reported by lsblk
+// +kubebuilder:validation:Enum=none;host-aware;host-managed
+type ZonedModel string
+
+const (
+	// NotZoned refers to conventional devices
+	NotZoned ZonedModel = "none"
+	// HostAware refers to zoned devices that also accept random writes, e.g. host-aware SMR drives
+	HostAware ZonedModel = "host-aware"
+	// HostManaged refers to zoned devices that only accept sequential writes, e.g. host-managed SMR and ZNS drives
+	HostManaged ZonedModel = "host-managed"
+)
+
+// SelectionStrategy is the order in which a LocalVolumeSet claims matching devices on a node
+// +kubebuilder:validation:Enum=SmallestFirst;LargestFirst;BySlotOrder;NonRotationalFirst
+type SelectionStrategy string
+
+const (
+	// SmallestFirst claims the smallest devices first
+	SmallestFirst SelectionStrategy = "SmallestFirst"
+	// LargestFirst claims the largest devices first
+	LargestFirst SelectionStrategy = "LargestFirst"
+	// BySlotOrder claims devices in the order of their /dev/disk/by-path links,
+	// which follows the controller, port and enclosure slot the device is attached to
+	BySlotOrder SelectionStrategy = "BySlotOrder"
+	// NonRotationalFirst claims non-rotational devices before rotational ones
+	NonRotationalFirst SelectionStrategy = "NonRotationalFirst"
 )
 
 // DeviceInclusionSpec holds the inclusion filter spec
 type DeviceInclusionSpec struct {
 	// Devices is the list of devices that should be used for automatic detection.
 	// This would be one of the types supported by the local-storage operator.
-	// Currently, the supported types are: disk, part, loop, mpath.
+	// Currently, the supported types are: disk, part, loop, mpath, lvm.
 	// If the list is empty only `disk` types will be selected.
 	// +optional
 	DeviceTypes []DeviceType `json:"deviceTypes,omitempty"`
@@ -75,6 +106,85 @@ type DeviceInclusionSpec struct {
 	// to contain at least one of these strings.
 	// +optional
 	Vendors []string `json:"vendors,omitempty"`
+	// Transports is a list of device transports, for example nvme, sata, sas, usb or iscsi.
+	// If not empty, the device's transport as outputted by lsblk needs to be one of these values.
+	// lsblk only reports the transport of whole devices, so partitions never match this filter.
+	// +optional
+	Transports []string `json:"transports,omitempty"`
+	// LogicalSectorSizes is a list of logical sector sizes in bytes, for example 512 or 4096.
+	// If not empty, the device's logical sector size needs to be one of these values.
+	// +optional
+	LogicalSectorSizes []int64 `json:"logicalSectorSizes,omitempty"`
+	// ZonedModels is the list of zoned models that should be used. Valid values are none, host-aware and host-managed.
+	// If the list is empty devices are selected regardless of their zoned model.
+	// +optional
+	ZonedModels []ZonedModel `json:"zonedModels,omitempty"`
+	// RequireDiscard selects only devices that support discard (TRIM/UNMAP) when set to true.
+	// +optional
+	RequireDiscard bool `json:"requireDiscard,omitempty"`
+	// UdevProperties is a map of udev property names to values, for example
+	// {"ID_BUS": "scsi", "ID_MODEL_ID": "regex:^0x1af4$"}. The properties are read from the
+	// udev database of the node. Every property needs to be set on the device and its value needs
+	// to match, either exactly, as a glob or, when prefixed with `regex:`, as a regular expression.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=32
+	UdevProperties map[string]string `json:"udevProperties,omitempty"`
+	// DeviceIdentifierSpec restricts the devices to the ones whose identifiers match.
+	// Each non-empty list needs to match the device for it to be included.
+	DeviceIdentifierSpec `json:",inline"`
+}
+
+// DeviceIdentifierSpec holds pattern lists that are matched against stable device identifiers.
+// Each pattern is a glob following filepath.Match syntax, unless it is prefixed with `regex:`,
+// in which case the rest of the pattern is a regular expression (RE2 syntax).
+// Example: ["S3EV*", "regex:^Z1[0-9]{6}$"]
+type DeviceIdentifierSpec struct {
+	// Serials is a list of patterns matched against the device serial number as outputted by lsblk.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Serials []string `json:"serials,omitempty"`
+	// WWNs is a list of patterns matched against the device World Wide Name as outputted by lsblk,
+	// for example "0x5000c500a1b2c3d4".
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	WWNs []string `json:"wwns,omitempty"`
+	// ByPathNames is a list of patterns matched against the names of the device symlinks
+	// in /dev/disk/by-path, for example "pci-0000:3b:00.0-sas-phy4-lun-0".
+	// Useful for selecting specific enclosure slots.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	ByPathNames []string `json:"byPathNames,omitempty"`
+	// ByIDNames is a list of patterns matched against the names of the device symlinks
+	// in /dev/disk/by-id, for example "wwn-0x5000c500a1b2c3d4".
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	ByIDNames []string `json:"byIDNames,omitempty"`
+	// VolumeGroups is a list of patterns matched against the volume group name of LVM logical
+	// volumes. Devices that are not LVM logical volumes never match.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	VolumeGroups []string `json:"volumeGroups,omitempty"`
+	// LogicalVolumes is a list of patterns matched against the name of LVM logical volumes,
+	// without the volume group. Devices that are not LVM logical volumes never match.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	LogicalVolumes []string `json:"logicalVolumes,omitempty"`
+	// PartLabels is a list of patterns matched against the partition label (PARTLABEL) of partitions.
+	// Devices that are not partitions or have no label never match.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	PartLabels []string `json:"partLabels,omitempty"`
+	// PartTypes is a list of patterns matched against the partition type (PARTTYPE) of partitions,
+	// for example "0fc63daf-8483-4772-8e79-3d69d8477de4" for GPT or "0x83" for DOS partition tables.
+	// Matching is case insensitive.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	PartTypes []string `json:"partTypes,omitempty"`
+	// PartUUIDs is a list of patterns matched against the partition UUID (PARTUUID) of partitions.
+	// Matching is case insensitive.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	PartUUIDs []string `json:"partUUIDs,omitempty"`
 }
 
 // DeviceExclusionSpec holds the exclusion filter spec
@@ -87,9 +197,65 @@ type DeviceExclusionSpec struct {
 	// +optional
 	// +kubebuilder:validation:MaxItems=64
 	DeviceNameFilter []string `json:"deviceNameFilter,omitempty"`
+	// Models is a list of device models. Devices whose model as outputted by lsblk contains
+	// any of these strings are excluded.
+	// +optional
+	Models []string `json:"models,omitempty"`
+	// Vendors is a list of device vendors. Devices whose vendor as outputted by lsblk contains
+	// any of these strings are excluded.
+	// +optional
+	Vendors []string `json:"vendors,omitempty"`
+	// MinSize is the lower bound of the size range to exclude.
+	// If only MaxSize is set, all devices up to MaxSize are excluded.
+	// +optional
+	MinSize *resource.Quantity `json:"minSize,omitempty"`
+	// MaxSize is the upper bound of the size range to exclude.
+	// If only MinSize is set, all devices from MinSize up are excluded.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// DeviceIdentifierSpec excludes the devices whose identifiers match.
+	// A device is excluded if it matches any pattern in any of the lists.
+	DeviceIdentifierSpec `json:",inline"`
+}
+
+// DeviceSelector holds an expression based device selection rule
+type DeviceSelector struct {
+	// Expression is a CEL expression that is evaluated against every candidate device after
+	// DeviceInclusionSpec and DeviceExclusionSpec have matched it. The device is selected only if the
+	// expression evaluates to true. The device is available as the `device` variable with the fields:
+	// name, kname, type, model, vendor, serial, wwn, partLabel, partType, partUUID, fsType, transport,
+	// zoned (strings),
+	// size, logicalSectorSize, physicalSectorSize, discardMax (bytes, int), rotational (bool),
+	// byIDLinks (list of /dev/disk/by-id names), byPathLinks (list of /dev/disk/by-path names)
+	// and udev (map of udev property names to values).
+	// The function quantity(string) converts a resource quantity such as "1Ti" to bytes.
+	// Example: `device.type == "disk" && !device.rotational && device.size > quantity("1Ti")`
+	// +optional
+	// +kubebuilder:validation:MaxLength=4096
+	Expression string `json:"expression,omitempty"`
+}
+
+// ProvisioningPolicy relaxes the safety filters that every device has to pass before it is provisioned.
+// Relaxing a filter can expose removable media or existing data to the consumers of the PVs,
+// the active relaxations are therefore reported in the LocalVolumeSet status.
+type ProvisioningPolicy struct {
+	// AllowRemovableDevices accepts devices that the kernel reports as removable, for example
+	// USB or SD card storage on edge hardware.
+	// +optional
+	AllowRemovableDevices bool `json:"allowRemovableDevices,omitempty"`
+	// AllowedFilesystemTypes is a list of filesystem signatures, for example xfs or ext4.
+	// Devices whose only signature is one of these filesystem types are accepted even though they are
+	// not empty. The existing filesystem is neither wiped nor checked, it is handed over to the PV as is.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	AllowedFilesystemTypes []string `json:"allowedFilesystemTypes,omitempty"`
 }
 
 // LocalVolumeSetSpec defines the desired state of LocalVolumeSet
+// +kubebuilder:validation:XValidation:rule="!has(self.classes) || self.classes.all(c, c.storageClassName != self.storageClassName)",message="the storageClassName of classes must differ from storageClassName"
+// +kubebuilder:validation:XValidation:rule="!has(self.pool) || !has(self.partitioning)",message="pool and partitioning are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.raid) || (!has(self.pool) && !has(self.partitioning))",message="raid is mutually exclusive with pool and partitioning"
+// +kubebuilder:validation:XValidation:rule="!has(self.cache) || (!has(self.pool) && !has(self.partitioning) && !has(self.raid))",message="cache is mutually exclusive with pool, partitioning and raid"
 type LocalVolumeSetSpec struct {
 	// Nodes on which the automatic detection policies must run.
 	// +optional
@@ -98,8 +264,33 @@ type LocalVolumeSetSpec struct {
 	StorageClassName string `json:"storageClassName"`
 	// MaxDeviceCount is the maximum number of Devices that needs to be detected per node.
 	// If it is not specified, there will be no limit to the number of provisioned devices.
+	// With Pool or Cache, it limits the devices that are added to the volume group instead.
 	// +optional
 	MaxDeviceCount *int32 `json:"maxDeviceCount,omitempty"`
+	// MaxCapacityPerNode is the maximum total size of the devices that are provisioned per node.
+	// Devices that would exceed it are not provisioned.
+	// If it is not specified, there will be no limit to the provisioned capacity.
+	// With Pool or Cache, it limits the devices that are added to the volume group instead.
+	// +optional
+	MaxCapacityPerNode *resource.Quantity `json:"maxCapacityPerNode,omitempty"`
+	// SelectionStrategy is the order in which matching devices are claimed on a node. It decides which
+	// devices are provisioned once MaxDeviceCount or MaxCapacityPerNode is reached.
+	// Valid values are SmallestFirst, LargestFirst, BySlotOrder and NonRotationalFirst.
+	// Devices that are equal for the strategy are claimed in the order of their serial numbers.
+	// If it is not specified, devices are claimed in the order lsblk lists them.
+	// +optional
+	SelectionStrategy SelectionStrategy `json:"selectionStrategy,omitempty"`
+	// DeviceSettleDuration is how long a matching device has to be present on a node before it is claimed,
+	// so that a device that some other entity has just attached is not claimed before that entity does.
+	// The time a device was first seen is kept on the node and survives restarts of the diskmaker.
+	// If it is not specified, it defaults to 1m. It must not be negative.
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="deviceSettleDuration must not be negative"
+	// +optional
+	DeviceSettleDuration *metav1.Duration `json:"deviceSettleDuration,omitempty"`
+	// UdevSettle claims a matching device before DeviceSettleDuration has passed once `udevadm settle`
+	// reports that udev has processed all pending events and the device is in the udev database.
+	// +optional
+	UdevSettle bool `json:"udevSettle,omitempty"`
 	// VolumeMode determines whether the PV created is Block or Filesystem.
 	// It will default to Filesystem.
 	// +optional
@@ -116,6 +307,176 @@ type LocalVolumeSetSpec struct {
 	// DeviceExclusionSpec is the filtration rule for excluding a device in the device discovery
 	// +optional
 	DeviceExclusionSpec *DeviceExclusionSpec `json:"deviceExclusionSpec,omitempty"`
+	// DeviceSelector is an expression based rule that further narrows down the devices
+	// matched by DeviceInclusionSpec and DeviceExclusionSpec
+	// +optional
+	DeviceSelector *DeviceSelector `json:"deviceSelector,omitempty"`
+	// ProvisioningPolicy relaxes some of the filters that reject devices which are
+	// unsafe to provision by default
+	// +optional
+	ProvisioningPolicy *ProvisioningPolicy `json:"provisioningPolicy,omitempty"`
+	// PVLabelPolicy adds attributes of the devices as labels to the PersistentVolumes created for them.
+	// If it is not specified, no device attributes are added.
+	// +optional
+	PVLabelPolicy *localv1.PVLabelPolicy `json:"pvLabelPolicy,omitempty"`
+	// PVTopology copies labels of the nodes onto the PersistentVolumes created on them.
+	// +optional
+	PVTopology *localv1.PVTopology `json:"pvTopology,omitempty"`
+	// StorageClassTemplate customizes the StorageClass created for StorageClassName and the ones of Classes.
+	// IsDefaultClass only applies to the StorageClass of StorageClassName.
+	// +optional
+	StorageClassTemplate *localv1.StorageClassTemplate `json:"storageClassTemplate,omitempty"`
+	// Partitioning splits the matched disks into GPT partitions that are provisioned as separate PersistentVolumes.
+	// The partitions of a disk are matched by the DeviceInclusionSpec, DeviceExclusionSpec and DeviceSelector of the disk.
+	// +optional
+	Partitioning *localv1.Partitioning `json:"partitioning,omitempty"`
+	// Pool adds the matched devices to an LVM volume group per storage class on each node, and provisions
+	// logical volumes of the same size from it instead of the devices.
+	// +optional
+	Pool *LocalVolumeSetPool `json:"pool,omitempty"`
+	// Raid assembles the matched devices into mdadm software RAID arrays that are provisioned
+	// as PersistentVolumes instead of the devices.
+	// +optional
+	Raid *LocalVolumeSetRaid `json:"raid,omitempty"`
+	// Cache puts a slice of a NonRotational device in front of each Rotational device as a dm-cache,
+	// and provisions the cached devices instead of the devices.
+	// +optional
+	Cache *LocalVolumeSetCache `json:"cache,omitempty"`
+	// NodeOverrides replace parts of this spec on the nodes they select, so that nodes with
+	// different hardware can provision devices into the same storage class.
+	// The first override whose NodeSelector matches a node is used on that node.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	NodeOverrides []NodeOverride `json:"nodeOverrides,omitempty"`
+	// Classes sort the matched devices into further storage classes. Each device is provisioned for
+	// the first class whose DeviceInclusionSpec matches it, devices that match none of the classes
+	// are provisioned for StorageClassName. DeviceExclusionSpec and DeviceSelector apply to all classes.
+	// +optional
+	// +listType=map
+	// +listMapKey=storageClassName
+	// +kubebuilder:validation:MaxItems=16
+	Classes []LocalVolumeSetClass `json:"classes,omitempty"`
+}
+
+// LocalVolumeSetPool pools the matched devices of a storage class in an LVM volume group named "lso-"
+// followed by the name of the storage class, and provisions logical volumes of VolumeSize from it.
+// Devices of different sizes thereby become PersistentVolumes of the same size. The logical volume of
+// a PersistentVolume is removed once the PersistentVolume is deleted, returning its space to the volume group.
+// Devices with a filesystem or partition table signature are never added to the volume group.
+// The volume group is not removed when the pool is removed from the spec or the LocalVolumeSet is deleted:
+// its devices carry an LVM signature and are not provisioned again until an administrator removes the
+// volume group with vgremove and wipes them, once the PersistentVolumes of its logical volumes are gone.
+type LocalVolumeSetPool struct {
+	// VolumeSize is the size of the logical volumes. It is rounded up to a multiple of the extent size
+	// of the volume group, 4Mi by default.
+	VolumeSize resource.Quantity `json:"volumeSize"`
+	// MaxVolumes limits the number of logical volumes per node and storage class.
+	// If it is not specified, logical volumes are created until the volume group is full.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxVolumes *int32 `json:"maxVolumes,omitempty"`
+}
+
+// RaidLevel is the level of the software RAID arrays assembled by a LocalVolumeSet
+// +kubebuilder:validation:Enum=0;1;10
+type RaidLevel int32
+
+const (
+	// Raid0 stripes data across the members without redundancy
+	Raid0 RaidLevel = 0
+	// Raid1 mirrors data on all members
+	Raid1 RaidLevel = 1
+	// Raid10 stripes data across mirrored pairs of members
+	Raid10 RaidLevel = 10
+)
+
+// LocalVolumeSetRaid assembles the matched devices of a storage class into mdadm arrays of Members devices
+// each. Every array /dev/md/<name> is provisioned as a PersistentVolume through its /dev/disk/by-id/md-uuid-*
+// link. Devices of similar size are assembled together, devices that don't make up a whole array wait for more.
+// Arrays are reassembled when the node boots, degraded arrays are reported by events and the
+// lso_lvset_degraded_raid_array_count metric.
+// +kubebuilder:validation:XValidation:rule="self.level != 10 || self.members >= 4",message="raid level 10 needs at least 4 members"
+type LocalVolumeSetRaid struct {
+	// Level is the RAID level of the arrays.
+	Level RaidLevel `json:"level"`
+	// Members is the number of devices in each array.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=32
+	Members int32 `json:"members"`
+}
+
+// CacheMode is the write policy of the caches of a LocalVolumeSet
+// +kubebuilder:validation:Enum=writethrough;writeback
+type CacheMode string
+
+const (
+	// WriteThrough writes to the cache and the cached device at once, the cache only speeds up reads
+	WriteThrough CacheMode = "writethrough"
+	// WriteBack acknowledges writes once they are in the cache and writes them to the cached device later.
+	// Data that has not been written back is lost if the cache device fails.
+	WriteBack CacheMode = "writeback"
+)
+
+// LocalVolumeSetCache caches the matched Rotational devices of a storage class with the matched NonRotational
+// devices. They are added to an LVM volume group named "lso-" followed by the name of the storage class, the
+// Rotational devices once a NonRotational device is matched and the NonRotational devices once a Rotational
+// device is matched. An ErrorNoCacheDevice event is reported while no NonRotational device is matched.
+// A logical volume spanning each Rotational device is cached by a logical volume of CacheSize on a
+// NonRotational device, and provisioned as a PersistentVolume through its /dev/mapper link.
+// Rotational devices wait until a NonRotational device has room for their cache. The volume group is
+// activated again when the node boots. The cached logical volume of a PersistentVolume is removed once the
+// PersistentVolume is deleted, and the Rotational device is cached again with a new cache.
+type LocalVolumeSetCache struct {
+	// Mode is the cache mode, writethrough or writeback. Defaults to writethrough.
+	// +kubebuilder:default=writethrough
+	// +optional
+	Mode CacheMode `json:"mode,omitempty"`
+	// CacheSize is the size of the cache of each PersistentVolume. It is rounded up to a multiple of the extent
+	// size of the volume group, 4Mi by default.
+	CacheSize resource.Quantity `json:"cacheSize"`
+}
+
+// LocalVolumeSetClass is a storage class of a LocalVolumeSet together with the rule that selects its devices.
+// Fields that are not set keep the value of the LocalVolumeSet spec.
+type LocalVolumeSetClass struct {
+	// StorageClassName to use for the devices of the class
+	// +kubebuilder:validation:MinLength=1
+	StorageClassName string `json:"storageClassName"`
+	// DeviceInclusionSpec is the filtration rule for including a device in the class.
+	// Fields that are not set keep the value of the DeviceInclusionSpec of the LocalVolumeSet,
+	// including the one of a NodeOverride. If it is not specified, the class matches the devices
+	// the LocalVolumeSet matches.
+	// +optional
+	DeviceInclusionSpec *DeviceInclusionSpec `json:"deviceInclusionSpec,omitempty"`
+	// VolumeMode replaces the VolumeMode of the LocalVolumeSet for the class.
+	// +optional
+	VolumeMode localv1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+	// FSType replaces the FSType of the LocalVolumeSet for the class.
+	// +optional
+	FSType string `json:"fsType,omitempty"`
+	// MaxDeviceCount replaces the MaxDeviceCount of the LocalVolumeSet for the class.
+	// +optional
+	MaxDeviceCount *int32 `json:"maxDeviceCount,omitempty"`
+}
+
+// NodeOverride holds values that replace the LocalVolumeSet spec on the nodes selected by NodeSelector.
+// Fields that are not set keep the value of the LocalVolumeSet spec.
+type NodeOverride struct {
+	// NodeSelector selects the nodes the override applies to.
+	// +required
+	NodeSelector *corev1.NodeSelector `json:"nodeSelector"`
+	// DeviceInclusionSpec replaces the DeviceInclusionSpec of the LocalVolumeSet on the selected nodes.
+	// +optional
+	DeviceInclusionSpec *DeviceInclusionSpec `json:"deviceInclusionSpec,omitempty"`
+	// DeviceExclusionSpec replaces the DeviceExclusionSpec of the LocalVolumeSet on the selected nodes.
+	// +optional
+	DeviceExclusionSpec *DeviceExclusionSpec `json:"deviceExclusionSpec,omitempty"`
+	// MaxDeviceCount replaces the MaxDeviceCount of the LocalVolumeSet on the selected nodes.
+	// +optional
+	MaxDeviceCount *int32 `json:"maxDeviceCount,omitempty"`
+	// FSType replaces the FSType of the LocalVolumeSet on the selected nodes.
+	// +optional
+	FSType string `json:"fsType,omitempty"`
 }
 
 // LocalVolumeSetStatus defines the observed state of LocalVolumeSet
@@ -127,6 +488,9 @@ type LocalVolumeSetStatus struct {
 	// observedGeneration is the last generation change the operator has dealt with
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ActiveRelaxations lists the filters that are relaxed by spec.provisioningPolicy
+	// +optional
+	ActiveRelaxations []string `json:"activeRelaxations,omitempty"`
 }
 
 // +kubebuilder:object:root=true
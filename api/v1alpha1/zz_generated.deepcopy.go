@@ -22,7 +22,9 @@ package v1alpha1
 
 import (
 	operatorv1 "github.com/openshift/api/operator/v1"
+	apiv1 "github.com/openshift/local-storage-operator/api/v1"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -34,6 +36,27 @@ func (in *DeviceExclusionSpec) DeepCopyInto(out *DeviceExclusionSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Vendors != nil {
+		in, out := &in.Vendors, &out.Vendors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	in.DeviceIdentifierSpec.DeepCopyInto(&out.DeviceIdentifierSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceExclusionSpec.
@@ -46,6 +69,66 @@ func (in *DeviceExclusionSpec) DeepCopy() *DeviceExclusionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceIdentifierSpec) DeepCopyInto(out *DeviceIdentifierSpec) {
+	*out = *in
+	if in.Serials != nil {
+		in, out := &in.Serials, &out.Serials
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WWNs != nil {
+		in, out := &in.WWNs, &out.WWNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ByPathNames != nil {
+		in, out := &in.ByPathNames, &out.ByPathNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ByIDNames != nil {
+		in, out := &in.ByIDNames, &out.ByIDNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeGroups != nil {
+		in, out := &in.VolumeGroups, &out.VolumeGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogicalVolumes != nil {
+		in, out := &in.LogicalVolumes, &out.LogicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PartLabels != nil {
+		in, out := &in.PartLabels, &out.PartLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PartTypes != nil {
+		in, out := &in.PartTypes, &out.PartTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PartUUIDs != nil {
+		in, out := &in.PartUUIDs, &out.PartUUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceIdentifierSpec.
+func (in *DeviceIdentifierSpec) DeepCopy() *DeviceIdentifierSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceIdentifierSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceInclusionSpec) DeepCopyInto(out *DeviceInclusionSpec) {
 	*out = *in
@@ -79,6 +162,29 @@ func (in *DeviceInclusionSpec) DeepCopyInto(out *DeviceInclusionSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Transports != nil {
+		in, out := &in.Transports, &out.Transports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogicalSectorSizes != nil {
+		in, out := &in.LogicalSectorSizes, &out.LogicalSectorSizes
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.ZonedModels != nil {
+		in, out := &in.ZonedModels, &out.ZonedModels
+		*out = make([]ZonedModel, len(*in))
+		copy(*out, *in)
+	}
+	if in.UdevProperties != nil {
+		in, out := &in.UdevProperties, &out.UdevProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.DeviceIdentifierSpec.DeepCopyInto(&out.DeviceIdentifierSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceInclusionSpec.
@@ -91,6 +197,21 @@ func (in *DeviceInclusionSpec) DeepCopy() *DeviceInclusionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceSelector) DeepCopyInto(out *DeviceSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceSelector.
+func (in *DeviceSelector) DeepCopy() *DeviceSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
 	*out = *in
@@ -122,6 +243,99 @@ func (in *DiscoveredDevice) DeepCopy() *DiscoveredDevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeDeviceBlocklist) DeepCopyInto(out *LocalVolumeDeviceBlocklist) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDeviceBlocklist.
+func (in *LocalVolumeDeviceBlocklist) DeepCopy() *LocalVolumeDeviceBlocklist {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeDeviceBlocklist)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeDeviceBlocklist) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeDeviceBlocklistList) DeepCopyInto(out *LocalVolumeDeviceBlocklistList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeDeviceBlocklist, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDeviceBlocklistList.
+func (in *LocalVolumeDeviceBlocklistList) DeepCopy() *LocalVolumeDeviceBlocklistList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeDeviceBlocklistList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeDeviceBlocklistList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeDeviceBlocklistSpec) DeepCopyInto(out *LocalVolumeDeviceBlocklistSpec) {
+	*out = *in
+	if in.Serials != nil {
+		in, out := &in.Serials, &out.Serials
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WWNs != nil {
+		in, out := &in.WWNs, &out.WWNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ByIDNames != nil {
+		in, out := &in.ByIDNames, &out.ByIDNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ModelRegexes != nil {
+		in, out := &in.ModelRegexes, &out.ModelRegexes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDeviceBlocklistSpec.
+func (in *LocalVolumeDeviceBlocklistSpec) DeepCopy() *LocalVolumeDeviceBlocklistSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeDeviceBlocklistSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolumeDiscovery) DeepCopyInto(out *LocalVolumeDiscovery) {
 	*out = *in
@@ -351,6 +565,47 @@ func (in *LocalVolumeSet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetCache) DeepCopyInto(out *LocalVolumeSetCache) {
+	*out = *in
+	out.CacheSize = in.CacheSize.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetCache.
+func (in *LocalVolumeSetCache) DeepCopy() *LocalVolumeSetCache {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetClass) DeepCopyInto(out *LocalVolumeSetClass) {
+	*out = *in
+	if in.DeviceInclusionSpec != nil {
+		in, out := &in.DeviceInclusionSpec, &out.DeviceInclusionSpec
+		*out = new(DeviceInclusionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxDeviceCount != nil {
+		in, out := &in.MaxDeviceCount, &out.MaxDeviceCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetClass.
+func (in *LocalVolumeSetClass) DeepCopy() *LocalVolumeSetClass {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolumeSetList) DeepCopyInto(out *LocalVolumeSetList) {
 	*out = *in
@@ -383,6 +638,42 @@ func (in *LocalVolumeSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetPool) DeepCopyInto(out *LocalVolumeSetPool) {
+	*out = *in
+	out.VolumeSize = in.VolumeSize.DeepCopy()
+	if in.MaxVolumes != nil {
+		in, out := &in.MaxVolumes, &out.MaxVolumes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetPool.
+func (in *LocalVolumeSetPool) DeepCopy() *LocalVolumeSetPool {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetRaid) DeepCopyInto(out *LocalVolumeSetRaid) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetRaid.
+func (in *LocalVolumeSetRaid) DeepCopy() *LocalVolumeSetRaid {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetRaid)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolumeSetSpec) DeepCopyInto(out *LocalVolumeSetSpec) {
 	*out = *in
@@ -396,6 +687,16 @@ func (in *LocalVolumeSetSpec) DeepCopyInto(out *LocalVolumeSetSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxCapacityPerNode != nil {
+		in, out := &in.MaxCapacityPerNode, &out.MaxCapacityPerNode
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DeviceSettleDuration != nil {
+		in, out := &in.DeviceSettleDuration, &out.DeviceSettleDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -413,6 +714,65 @@ func (in *LocalVolumeSetSpec) DeepCopyInto(out *LocalVolumeSetSpec) {
 		*out = new(DeviceExclusionSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeviceSelector != nil {
+		in, out := &in.DeviceSelector, &out.DeviceSelector
+		*out = new(DeviceSelector)
+		**out = **in
+	}
+	if in.ProvisioningPolicy != nil {
+		in, out := &in.ProvisioningPolicy, &out.ProvisioningPolicy
+		*out = new(ProvisioningPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVLabelPolicy != nil {
+		in, out := &in.PVLabelPolicy, &out.PVLabelPolicy
+		*out = new(apiv1.PVLabelPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVTopology != nil {
+		in, out := &in.PVTopology, &out.PVTopology
+		*out = new(apiv1.PVTopology)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageClassTemplate != nil {
+		in, out := &in.StorageClassTemplate, &out.StorageClassTemplate
+		*out = new(apiv1.StorageClassTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partitioning != nil {
+		in, out := &in.Partitioning, &out.Partitioning
+		*out = new(apiv1.Partitioning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pool != nil {
+		in, out := &in.Pool, &out.Pool
+		*out = new(LocalVolumeSetPool)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Raid != nil {
+		in, out := &in.Raid, &out.Raid
+		*out = new(LocalVolumeSetRaid)
+		**out = **in
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(LocalVolumeSetCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeOverrides != nil {
+		in, out := &in.NodeOverrides, &out.NodeOverrides
+		*out = make([]NodeOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Classes != nil {
+		in, out := &in.Classes, &out.Classes
+		*out = make([]LocalVolumeSetClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetSpec.
@@ -440,6 +800,11 @@ func (in *LocalVolumeSetStatus) DeepCopyInto(out *LocalVolumeSetStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ActiveRelaxations != nil {
+		in, out := &in.ActiveRelaxations, &out.ActiveRelaxations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetStatus.
@@ -451,3 +816,58 @@ func (in *LocalVolumeSetStatus) DeepCopy() *LocalVolumeSetStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeOverride) DeepCopyInto(out *NodeOverride) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeviceInclusionSpec != nil {
+		in, out := &in.DeviceInclusionSpec, &out.DeviceInclusionSpec
+		*out = new(DeviceInclusionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeviceExclusionSpec != nil {
+		in, out := &in.DeviceExclusionSpec, &out.DeviceExclusionSpec
+		*out = new(DeviceExclusionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxDeviceCount != nil {
+		in, out := &in.MaxDeviceCount, &out.MaxDeviceCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeOverride.
+func (in *NodeOverride) DeepCopy() *NodeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningPolicy) DeepCopyInto(out *ProvisioningPolicy) {
+	*out = *in
+	if in.AllowedFilesystemTypes != nil {
+		in, out := &in.AllowedFilesystemTypes, &out.AllowedFilesystemTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningPolicy.
+func (in *ProvisioningPolicy) DeepCopy() *ProvisioningPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
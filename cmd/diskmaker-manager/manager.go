@@ -137,6 +137,7 @@ func startManager(cmd *cobra.Command, args []string) error {
 		mgr.GetClient(),
 		mgr.GetAPIReader(),
 		mgr.GetScheme(),
+		common.GetLocalDiskLocationPath(),
 		&diskmakerControllerLvSet.WallTime{},
 		&provDeleter.CleanupStatusTracker{ProcTable: provDeleter.NewProcTable()},
 		getRuntimeConfig(diskmakerControllerLvSet.ComponentName, mgr),
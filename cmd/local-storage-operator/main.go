@@ -121,9 +121,10 @@ func main() {
 	}
 
 	if err = (&lvcontroller.LocalVolumeReconciler{
-		Client: mgr.GetClient(),
-		LvMap:  &common.StorageClassOwnerMap{},
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		ClientReader: mgr.GetAPIReader(),
+		LvMap:        &common.StorageClassOwnerMap{},
+		Scheme:       mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		klog.ErrorS(err, "unable to create LocalVolume controller")
 		os.Exit(1)
@@ -0,0 +1,77 @@
+package common
+
+import (
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// AllowedTopologiesUpdatedAnnotation records when the operator last changed the allowedTopologies of a StorageClass
+	AllowedTopologiesUpdatedAnnotation = "local.storage.openshift.io/allowed-topologies-updated"
+
+	defaultAllowedTopologiesUpdateInterval = 10 * time.Minute
+)
+
+// SyncAllowedTopologies sets the allowedTopologies of required to the nodes that hold pvs of the StorageClass,
+// if template enables it. Updates are rate limited by the update interval of template: if the allowedTopologies
+// of existing were changed less than the interval ago, they are kept and the time until they can be
+// changed again is returned. existing is nil if the StorageClass does not exist yet.
+func SyncAllowedTopologies(required, existing *storagev1.StorageClass, template *localv1.StorageClassTemplate, pvs []corev1.PersistentVolume, now time.Time) time.Duration {
+	if template == nil || !template.PopulateAllowedTopologies {
+		return 0
+	}
+	desired := allowedTopologiesForPVs(required.Name, pvs)
+	if existing != nil {
+		if equality.Semantic.DeepEqual(existing.AllowedTopologies, desired) {
+			required.AllowedTopologies = existing.AllowedTopologies
+			return 0
+		}
+		interval := defaultAllowedTopologiesUpdateInterval
+		if template.AllowedTopologiesUpdateInterval != nil {
+			interval = template.AllowedTopologiesUpdateInterval.Duration
+		}
+		if lastUpdated, err := time.Parse(time.RFC3339, existing.Annotations[AllowedTopologiesUpdatedAnnotation]); err == nil {
+			if elapsed := now.Sub(lastUpdated); elapsed < interval {
+				required.AllowedTopologies = existing.AllowedTopologies
+				return interval - elapsed
+			}
+		}
+	}
+	required.AllowedTopologies = desired
+	if required.Annotations == nil {
+		required.Annotations = map[string]string{}
+	}
+	required.Annotations[AllowedTopologiesUpdatedAnnotation] = now.UTC().Format(time.RFC3339)
+	return 0
+}
+
+// allowedTopologiesForPVs returns a topology term that matches the hostnames of the PVs of storageClassName,
+// or nil if there are none, which allows every node
+func allowedTopologiesForPVs(storageClassName string, pvs []corev1.PersistentVolume) []corev1.TopologySelectorTerm {
+	nodes := sets.New[string]()
+	for _, pv := range pvs {
+		if pv.Spec.StorageClassName != storageClassName {
+			continue
+		}
+		if hostname := pv.Labels[corev1.LabelHostname]; hostname != "" {
+			nodes.Insert(hostname)
+		}
+	}
+	if nodes.Len() == 0 {
+		return nil
+	}
+	// sets.List returns the hostnames sorted, so the terms only change when the nodes do
+	hostnames := sets.List(nodes)
+	return []corev1.TopologySelectorTerm{
+		{
+			MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{
+				{Key: corev1.LabelHostname, Values: hostnames},
+			},
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func hostnameTopology(hostnames ...string) []corev1.TopologySelectorTerm {
+	return []corev1.TopologySelectorTerm{
+		{
+			MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{
+				{Key: corev1.LabelHostname, Values: hostnames},
+			},
+		},
+	}
+}
+
+func TestSyncAllowedTopologies(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pv := func(storageClassName, hostname string) corev1.PersistentVolume {
+		return corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelHostname: hostname}},
+			Spec:       corev1.PersistentVolumeSpec{StorageClassName: storageClassName},
+		}
+	}
+	pvs := []corev1.PersistentVolume{
+		pv("local-sc", "worker-2"),
+		pv("local-sc", "worker-1"),
+		pv("local-sc", "worker-2"),
+		pv("other-sc", "worker-3"),
+	}
+	storageClass := func(updated time.Time, topologies []corev1.TopologySelectorTerm) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "local-sc",
+				Annotations: map[string]string{AllowedTopologiesUpdatedAnnotation: updated.Format(time.RFC3339)},
+			},
+			AllowedTopologies: topologies,
+		}
+	}
+	enabled := &localv1.StorageClassTemplate{PopulateAllowedTopologies: true}
+
+	testcases := []struct {
+		label                string
+		template             *localv1.StorageClassTemplate
+		existing             *storagev1.StorageClass
+		pvs                  []corev1.PersistentVolume
+		expected             []corev1.TopologySelectorTerm
+		expectedUpdated      bool
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			label:    "disabled",
+			template: &localv1.StorageClassTemplate{},
+			existing: storageClass(now.Add(-time.Hour), hostnameTopology("worker-1")),
+			pvs:      pvs,
+		},
+		{
+			label:           "new StorageClass",
+			template:        enabled,
+			pvs:             pvs,
+			expected:        hostnameTopology("worker-1", "worker-2"),
+			expectedUpdated: true,
+		},
+		{
+			label:    "no PVs allow every node",
+			template: enabled,
+			existing: &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "local-sc"}},
+		},
+		{
+			label:    "unchanged",
+			template: enabled,
+			existing: storageClass(now.Add(-time.Minute), hostnameTopology("worker-1", "worker-2")),
+			pvs:      pvs,
+			expected: hostnameTopology("worker-1", "worker-2"),
+		},
+		{
+			label:                "rate limited",
+			template:             enabled,
+			existing:             storageClass(now.Add(-time.Minute), hostnameTopology("worker-1")),
+			pvs:                  pvs,
+			expected:             hostnameTopology("worker-1"),
+			expectedRequeueAfter: 9 * time.Minute,
+		},
+		{
+			label:           "interval elapsed",
+			template:        &localv1.StorageClassTemplate{PopulateAllowedTopologies: true, AllowedTopologiesUpdateInterval: &metav1.Duration{Duration: time.Minute}},
+			existing:        storageClass(now.Add(-time.Minute), hostnameTopology("worker-1")),
+			pvs:             pvs,
+			expected:        hostnameTopology("worker-1", "worker-2"),
+			expectedUpdated: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			required := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "local-sc"}}
+			requeueAfter := SyncAllowedTopologies(required, tc.existing, tc.template, tc.pvs, now)
+			assert.Equal(t, tc.expectedRequeueAfter, requeueAfter)
+			assert.Equal(t, tc.expected, required.AllowedTopologies)
+			if tc.expectedUpdated {
+				assert.Equal(t, now.Format(time.RFC3339), required.Annotations[AllowedTopologiesUpdatedAnnotation])
+			} else {
+				assert.NotContains(t, required.Annotations, AllowedTopologiesUpdatedAnnotation)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClaimRefConflict is the reason of events about claimRefs that are not applied
+	ClaimRefConflict = "ClaimRefConflict"
+
+	// PreBoundClaimAnnotation records the claim that the diskmaker pre-bound a PV to, so that the
+	// pre-binding can be undone when the claimRef is removed from the device
+	PreBoundClaimAnnotation = "storage.openshift.com/pre-bound-claim"
+)
+
+// LocalVolumeClaimRefConflicts returns the claimRefs of the devices of lv that are rejected, because devices
+// of LocalVolumes in any namespace reference them as well, or because the claims are bound or pre-bound to
+// PersistentVolumes that do not belong to lv. LocalVolumes and PersistentVolumeClaims are only cached in the
+// watch namespace, so they are read through apiReader. Only the claims that claimRefs name are read.
+func LocalVolumeClaimRefConflicts(ctx context.Context, c client.Reader, apiReader client.Reader, lv *localv1.LocalVolume) ([]localv1.ClaimRefConflict, error) {
+	paths := map[localv1.PVClaimRef][]string{}
+	claimRefs := make([]localv1.PVClaimRef, 0)
+	for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+		for _, device := range storageClassDevice.Devices {
+			if device.ClaimRef == nil {
+				continue
+			}
+			if _, found := paths[*device.ClaimRef]; !found {
+				claimRefs = append(claimRefs, *device.ClaimRef)
+			}
+			paths[*device.ClaimRef] = append(paths[*device.ClaimRef], device.Path)
+		}
+	}
+	if len(claimRefs) == 0 {
+		return nil, nil
+	}
+
+	lvList := &localv1.LocalVolumeList{}
+	if err := apiReader.List(ctx, lvList); err != nil {
+		return nil, fmt.Errorf("error listing LocalVolumes: %w", err)
+	}
+	duplicates := ClaimRefConflicts(lvList.Items)
+
+	pvList := &corev1.PersistentVolumeList{}
+	if err := c.List(ctx, pvList); err != nil {
+		return nil, fmt.Errorf("error listing PersistentVolumes: %w", err)
+	}
+	preBound := map[localv1.PVClaimRef]string{}
+	for _, pv := range pvList.Items {
+		if pv.Spec.ClaimRef == nil || ownedByLocalVolume(&pv, lv) {
+			continue
+		}
+		preBound[localv1.PVClaimRef{Namespace: pv.Spec.ClaimRef.Namespace, Name: pv.Spec.ClaimRef.Name}] = pv.Name
+	}
+
+	conflicts := make([]localv1.ClaimRefConflict, 0)
+	for _, claimRef := range claimRefs {
+		conflict := localv1.ClaimRefConflict{ClaimRef: claimRef, Paths: paths[claimRef]}
+		if devices, found := duplicates[claimRef]; found {
+			conflict.Message = fmt.Sprintf("the claim is referenced by more than one device: %s", strings.Join(devices, ", "))
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+		if pvName, found := preBound[claimRef]; found {
+			conflict.Message = fmt.Sprintf("the claim is reserved by PersistentVolume %s, which does not belong to this LocalVolume", pvName)
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := apiReader.Get(ctx, types.NamespacedName{Namespace: claimRef.Namespace, Name: claimRef.Name}, pvc)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting PersistentVolumeClaim %s: %w", claimRef, err)
+		}
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv := &corev1.PersistentVolume{}
+		err = c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting PersistentVolume %s: %w", pvc.Spec.VolumeName, err)
+		}
+		if err == nil && ownedByLocalVolume(pv, lv) {
+			continue
+		}
+		conflict.Message = fmt.Sprintf("the claim is bound to PersistentVolume %s, which does not belong to this LocalVolume", pvc.Spec.VolumeName)
+		conflicts = append(conflicts, conflict)
+	}
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+	return conflicts, nil
+}
+
+func ownedByLocalVolume(pv *corev1.PersistentVolume, lv *localv1.LocalVolume) bool {
+	return pv.Labels[LocalVolumeOwnerNameForPV] == lv.Name && pv.Labels[LocalVolumeOwnerNamespaceForPV] == lv.Namespace
+}
+
+// ClaimRefConflicts returns the claims that more than one device of lvs references, mapped to
+// descriptions of those devices. No PersistentVolume is pre-bound to these claims.
+func ClaimRefConflicts(lvs []localv1.LocalVolume) map[localv1.PVClaimRef][]string {
+	devices := map[localv1.PVClaimRef][]string{}
+	for _, lv := range lvs {
+		for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+			for _, device := range storageClassDevice.Devices {
+				if device.ClaimRef == nil {
+					continue
+				}
+				devices[*device.ClaimRef] = append(devices[*device.ClaimRef], fmt.Sprintf("%s/%s:%s", lv.Namespace, lv.Name, device.Path))
+			}
+		}
+	}
+	for claimRef, paths := range devices {
+		if len(paths) < 2 {
+			delete(devices, claimRef)
+			continue
+		}
+		slices.Sort(paths)
+	}
+	return devices
+}
+
+// PVClaimReference returns the claimRef of a PersistentVolume that is pre-bound to claimRef
+func PVClaimReference(claimRef localv1.PVClaimRef) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind:       "PersistentVolumeClaim",
+		APIVersion: "v1",
+		Namespace:  claimRef.Namespace,
+		Name:       claimRef.Name,
+	}
+}
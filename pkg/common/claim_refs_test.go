@@ -0,0 +1,137 @@
+package common
+
+import (
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClaimRefConflicts(t *testing.T) {
+	db0 := &localv1.PVClaimRef{Namespace: "db", Name: "data-db-0"}
+	db1 := &localv1.PVClaimRef{Namespace: "db", Name: "data-db-1"}
+	db2 := &localv1.PVClaimRef{Namespace: "db", Name: "data-db-2"}
+	localVolume := func(name string, devices ...localv1.DeviceOverride) localv1.LocalVolume {
+		return localv1.LocalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openshift-local-storage"},
+			Spec: localv1.LocalVolumeSpec{
+				StorageClassDevices: []localv1.StorageClassDevice{
+					{StorageClassName: "local-sc", Devices: devices},
+				},
+			},
+		}
+	}
+	lvs := []localv1.LocalVolume{
+		localVolume("lv-a",
+			localv1.DeviceOverride{Path: "/dev/disk/by-id/wwn-a", ClaimRef: db0},
+			localv1.DeviceOverride{Path: "/dev/disk/by-id/wwn-b", ClaimRef: db1},
+			localv1.DeviceOverride{Path: "/dev/disk/by-id/wwn-c", ClaimRef: db1},
+			localv1.DeviceOverride{Path: "/dev/disk/by-id/wwn-d"},
+		),
+		localVolume("lv-b",
+			localv1.DeviceOverride{Path: "/dev/disk/by-id/wwn-e", ClaimRef: db2},
+			localv1.DeviceOverride{Path: "/dev/disk/by-id/wwn-f", ClaimRef: db0},
+		),
+	}
+
+	assert.Equal(t, map[localv1.PVClaimRef][]string{
+		*db0: {
+			"openshift-local-storage/lv-a:/dev/disk/by-id/wwn-a",
+			"openshift-local-storage/lv-b:/dev/disk/by-id/wwn-f",
+		},
+		*db1: {
+			"openshift-local-storage/lv-a:/dev/disk/by-id/wwn-b",
+			"openshift-local-storage/lv-a:/dev/disk/by-id/wwn-c",
+		},
+	}, ClaimRefConflicts(lvs))
+	assert.Empty(t, ClaimRefConflicts(lvs[1:]))
+}
+
+func TestLocalVolumeClaimRefConflicts(t *testing.T) {
+	claimRef := func(name string) *localv1.PVClaimRef {
+		return &localv1.PVClaimRef{Namespace: "db", Name: name}
+	}
+	lv := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "lv-a", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{
+			StorageClassDevices: []localv1.StorageClassDevice{
+				{
+					StorageClassName: "local-sc",
+					Devices: []localv1.DeviceOverride{
+						{Path: "/dev/disk/by-id/wwn-a", ClaimRef: claimRef("duplicate")},
+						{Path: "/dev/disk/by-id/wwn-b", ClaimRef: claimRef("pre-bound")},
+						{Path: "/dev/disk/by-id/wwn-c", ClaimRef: claimRef("bound")},
+						{Path: "/dev/disk/by-id/wwn-d", ClaimRef: claimRef("bound-here")},
+						{Path: "/dev/disk/by-id/wwn-e", ClaimRef: claimRef("unbound")},
+						{Path: "/dev/disk/by-id/wwn-f", ClaimRef: claimRef("missing")},
+						{Path: "/dev/disk/by-id/wwn-g"},
+					},
+				},
+			},
+		},
+	}
+	// a LocalVolume in another namespace referencing the same claim
+	other := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "lv-b", Namespace: "other"},
+		Spec: localv1.LocalVolumeSpec{
+			StorageClassDevices: []localv1.StorageClassDevice{
+				{
+					StorageClassName: "other-sc",
+					Devices:          []localv1.DeviceOverride{{Path: "/dev/disk/by-id/wwn-z", ClaimRef: claimRef("duplicate")}},
+				},
+			},
+		},
+	}
+	ownedLabels := map[string]string{LocalVolumeOwnerNameForPV: lv.Name, LocalVolumeOwnerNamespaceForPV: lv.Namespace}
+	pvc := func(name, volumeName string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		}
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	// the cache holds the PersistentVolumes, the LocalVolumes and claims of other namespaces are read from the apiserver
+	cachedClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-pre-bound"},
+			Spec:       corev1.PersistentVolumeSpec{ClaimRef: PVClaimReference(*claimRef("pre-bound"))},
+		},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-elsewhere"}},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-here", Labels: ownedLabels},
+			Spec:       corev1.PersistentVolumeSpec{ClaimRef: PVClaimReference(*claimRef("bound-here"))},
+		},
+	).Build()
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		lv, other,
+		pvc("bound", "pv-elsewhere"),
+		pvc("bound-here", "pv-here"),
+		pvc("unbound", ""),
+	).Build()
+
+	conflicts, err := LocalVolumeClaimRefConflicts(t.Context(), cachedClient, apiReader, lv)
+	assert.NoError(t, err)
+	assert.Equal(t, []localv1.ClaimRefConflict{
+		{
+			ClaimRef: *claimRef("duplicate"),
+			Paths:    []string{"/dev/disk/by-id/wwn-a"},
+			Message:  "the claim is referenced by more than one device: openshift-local-storage/lv-a:/dev/disk/by-id/wwn-a, other/lv-b:/dev/disk/by-id/wwn-z",
+		},
+		{
+			ClaimRef: *claimRef("pre-bound"),
+			Paths:    []string{"/dev/disk/by-id/wwn-b"},
+			Message:  "the claim is reserved by PersistentVolume pv-pre-bound, which does not belong to this LocalVolume",
+		},
+		{
+			ClaimRef: *claimRef("bound"),
+			Paths:    []string{"/dev/disk/by-id/wwn-c"},
+			Message:  "the claim is bound to PersistentVolume pv-elsewhere, which does not belong to this LocalVolume",
+		},
+	}, conflicts)
+}
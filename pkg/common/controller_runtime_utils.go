@@ -48,11 +48,10 @@ func appLabelIn(meta metav1.Object, components []string) bool {
 
 // InitMapIfNil allocates memory to a map if it is nil
 func InitMapIfNil(m *map[string]string) {
-	if len(*m) > 1 {
+	if *m != nil {
 		return
 	}
 	*m = make(map[string]string)
-	return
 }
 
 // GetNodeNameEnvVar returns the node name from env vars
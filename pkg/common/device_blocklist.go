@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DeviceBlocklist is the union of all LocalVolumeDeviceBlocklist objects in the cluster
+type DeviceBlocklist struct {
+	serials   map[string]string
+	wwns      map[string]string
+	byIDNames map[string]string
+	models    []blockedModel
+}
+
+type blockedModel struct {
+	regex     *regexp.Regexp
+	blocklist string
+}
+
+// LoadDeviceBlocklist lists all LocalVolumeDeviceBlocklist objects and compiles them into a DeviceBlocklist.
+// A blocklist that can't be read or has an invalid model regex is an error, callers should not
+// provision any device until it is fixed. c should be a cached client, the controllers keep the cache
+// of LocalVolumeDeviceBlocklists up to date by watching them, see EnqueueAllForDeviceBlocklist.
+func LoadDeviceBlocklist(ctx context.Context, c client.Reader) (*DeviceBlocklist, error) {
+	blocklists := &localv1alpha1.LocalVolumeDeviceBlocklistList{}
+	if err := c.List(ctx, blocklists); err != nil {
+		return nil, fmt.Errorf("failed to list LocalVolumeDeviceBlocklists: %w", err)
+	}
+	return NewDeviceBlocklist(blocklists.Items)
+}
+
+// EnqueueAllForDeviceBlocklist returns a handler that enqueues all objects listed by newList in namespace
+// whenever a LocalVolumeDeviceBlocklist changes, so that blocklist edits take effect right away
+// instead of with the next periodic reconcile
+func EnqueueAllForDeviceBlocklist(c client.Reader, namespace string, newList func() client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		list := newList()
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			klog.ErrorS(err, "failed to list objects to reconcile after a LocalVolumeDeviceBlocklist change")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0)
+		err := meta.EachListItem(list, func(obj runtime.Object) error {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return err
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}})
+			return nil
+		})
+		if err != nil {
+			klog.ErrorS(err, "failed to list objects to reconcile after a LocalVolumeDeviceBlocklist change")
+			return nil
+		}
+		return requests
+	})
+}
+
+// NewDeviceBlocklist compiles blocklists into a DeviceBlocklist
+func NewDeviceBlocklist(blocklists []localv1alpha1.LocalVolumeDeviceBlocklist) (*DeviceBlocklist, error) {
+	b := &DeviceBlocklist{
+		serials:   make(map[string]string),
+		wwns:      make(map[string]string),
+		byIDNames: make(map[string]string),
+	}
+	for _, blocklist := range blocklists {
+		for _, serial := range blocklist.Spec.Serials {
+			b.serials[strings.TrimSpace(serial)] = blocklist.Name
+		}
+		for _, wwn := range blocklist.Spec.WWNs {
+			b.wwns[strings.ToLower(strings.TrimSpace(wwn))] = blocklist.Name
+		}
+		for _, name := range blocklist.Spec.ByIDNames {
+			b.byIDNames[filepath.Base(strings.TrimSpace(name))] = blocklist.Name
+		}
+		for _, expr := range blocklist.Spec.ModelRegexes {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid model regex %q in LocalVolumeDeviceBlocklist %q: %w", expr, blocklist.Name, err)
+			}
+			b.models = append(b.models, blockedModel{regex: re, blocklist: blocklist.Name})
+		}
+	}
+	return b, nil
+}
+
+// IsBlocked checks whether dev is listed in the blocklist and returns the reason.
+// A nil DeviceBlocklist blocks nothing.
+func (b *DeviceBlocklist) IsBlocked(dev internal.BlockDevice) (bool, string, error) {
+	if b == nil {
+		return false, "", nil
+	}
+	if blocklist, found := b.serials[strings.TrimSpace(dev.Serial)]; found && dev.Serial != "" {
+		return true, fmt.Sprintf("serial %q is listed in LocalVolumeDeviceBlocklist %q", dev.Serial, blocklist), nil
+	}
+	if blocklist, found := b.wwns[strings.ToLower(dev.WWN)]; found && dev.WWN != "" {
+		return true, fmt.Sprintf("WWN %q is listed in LocalVolumeDeviceBlocklist %q", dev.WWN, blocklist), nil
+	}
+	if model := dev.GetModel(); model != "" {
+		for _, m := range b.models {
+			if m.regex.MatchString(model) {
+				return true, fmt.Sprintf("model %q matches %q in LocalVolumeDeviceBlocklist %q", model, m.regex.String(), m.blocklist), nil
+			}
+		}
+	}
+	if len(b.byIDNames) > 0 {
+		links, err := dev.GetValidByIDSymlinks()
+		if err != nil {
+			return false, "", fmt.Errorf("could not list by-id symlinks of %q: %w", dev.Name, err)
+		}
+		for _, link := range links {
+			if blocklist, found := b.byIDNames[filepath.Base(link)]; found {
+				return true, fmt.Sprintf("%s is listed in LocalVolumeDeviceBlocklist %q", link, blocklist), nil
+			}
+		}
+	}
+	return false, "", nil
+}
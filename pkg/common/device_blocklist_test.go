@@ -0,0 +1,133 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDeviceBlocklistIsBlocked(t *testing.T) {
+	origGlob := internal.FilePathGlob
+	origEval := internal.FilePathEvalSymLinks
+	defer func() {
+		internal.FilePathGlob = origGlob
+		internal.FilePathEvalSymLinks = origEval
+	}()
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		return []string{"/dev/disk/by-id/nvme-eui.0001", "/dev/disk/by-id/wwn-0x5000"}, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		if path == "/dev/disk/by-id/nvme-eui.0001" {
+			return "/dev/nvme0n1", nil
+		}
+		return "/dev/sdb", nil
+	}
+
+	blocklist, err := NewDeviceBlocklist([]localv1alpha1.LocalVolumeDeviceBlocklist{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Spec: localv1alpha1.LocalVolumeDeviceBlocklistSpec{
+				Serials: []string{"S1"},
+				WWNs:    []string{"0x5000C500A1B2C3D4"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Spec: localv1alpha1.LocalVolumeDeviceBlocklistSpec{
+				ByIDNames:    []string{"/dev/disk/by-id/nvme-eui.0001"},
+				ModelRegexes: []string{"^Dell BOSS"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		dev           internal.BlockDevice
+		expectBlocked bool
+		expectReason  string
+	}{
+		{name: "serial", dev: internal.BlockDevice{KName: "sda", Serial: "S1"}, expectBlocked: true, expectReason: `serial "S1" is listed in LocalVolumeDeviceBlocklist "a"`},
+		{name: "wwn ignores case", dev: internal.BlockDevice{KName: "sda", WWN: "0x5000c500a1b2c3d4"}, expectBlocked: true, expectReason: `WWN "0x5000c500a1b2c3d4" is listed in LocalVolumeDeviceBlocklist "a"`},
+		{name: "by-id name", dev: internal.BlockDevice{KName: "nvme0n1"}, expectBlocked: true, expectReason: `/dev/disk/by-id/nvme-eui.0001 is listed in LocalVolumeDeviceBlocklist "b"`},
+		{name: "model regex", dev: internal.BlockDevice{KName: "sdc", Model: "Dell BOSS-N1"}, expectBlocked: true, expectReason: `model "Dell BOSS-N1" matches "^Dell BOSS" in LocalVolumeDeviceBlocklist "b"`},
+		{name: "model regex is not anchored at the end", dev: internal.BlockDevice{KName: "sdc", Model: "Dell BOSS"}, expectBlocked: true, expectReason: `model "Dell BOSS" matches "^Dell BOSS" in LocalVolumeDeviceBlocklist "b"`},
+		{name: "not listed", dev: internal.BlockDevice{KName: "sdb", Serial: "S2", Model: "Samsung Dell BOSS"}},
+		{name: "empty serial", dev: internal.BlockDevice{KName: "sdd"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocked, reason, err := blocklist.IsBlocked(tc.dev)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectBlocked, blocked)
+			assert.Equal(t, tc.expectReason, reason)
+		})
+	}
+
+	// a nil blocklist blocks nothing
+	var nilBlocklist *DeviceBlocklist
+	blocked, _, err := nilBlocklist.IsBlocked(internal.BlockDevice{KName: "sda", Serial: "S1"})
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+}
+
+func TestLoadDeviceBlocklist(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, localv1alpha1.AddToScheme(scheme))
+
+	valid := &localv1alpha1.LocalVolumeDeviceBlocklist{
+		ObjectMeta: metav1.ObjectMeta{Name: "valid"},
+		Spec:       localv1alpha1.LocalVolumeDeviceBlocklistSpec{Serials: []string{"S1"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(valid).Build()
+	blocklist, err := LoadDeviceBlocklist(context.TODO(), fakeClient)
+	assert.NoError(t, err)
+	blocked, _, err := blocklist.IsBlocked(internal.BlockDevice{KName: "sda", Serial: "S1"})
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// an invalid regex fails the whole blocklist
+	invalid := &localv1alpha1.LocalVolumeDeviceBlocklist{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec:       localv1alpha1.LocalVolumeDeviceBlocklistSpec{ModelRegexes: []string{"("}},
+	}
+	fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(valid, invalid).Build()
+	_, err = LoadDeviceBlocklist(context.TODO(), fakeClient)
+	assert.Error(t, err)
+}
+
+func TestEnqueueAllForDeviceBlocklist(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, localv1alpha1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&localv1alpha1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "local-storage"}},
+		&localv1alpha1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "local-storage"}},
+		&localv1alpha1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "other"}},
+	).Build()
+
+	h := EnqueueAllForDeviceBlocklist(fakeClient, "local-storage", func() client.ObjectList { return &localv1alpha1.LocalVolumeSetList{} })
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+	h.Update(context.TODO(), event.UpdateEvent{
+		ObjectOld: &localv1alpha1.LocalVolumeDeviceBlocklist{ObjectMeta: metav1.ObjectMeta{Name: "boot-disks"}},
+		ObjectNew: &localv1alpha1.LocalVolumeDeviceBlocklist{ObjectMeta: metav1.ObjectMeta{Name: "boot-disks"}},
+	}, q)
+
+	requests := make([]string, 0)
+	for q.Len() > 0 {
+		request, _ := q.Get()
+		requests = append(requests, request.Name)
+		q.Done(request)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, requests)
+}
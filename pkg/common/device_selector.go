@@ -0,0 +1,163 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// deviceSelectorVariable is the name under which the device is exposed to the expression
+	deviceSelectorVariable = "device"
+	// deviceSelectorCostLimit bounds the evaluation cost of a single expression run
+	deviceSelectorCostLimit = 100000
+)
+
+// DeviceSelectorProgram is a compiled LocalVolumeSet DeviceSelector expression
+type DeviceSelectorProgram struct {
+	expression string
+	program    cel.Program
+}
+
+// CompileDeviceSelector compiles and type checks the expression in selector.
+// It returns nil if selector is nil or has an empty expression.
+func CompileDeviceSelector(selector *localv1alpha1.DeviceSelector) (*DeviceSelectorProgram, error) {
+	if selector == nil || strings.TrimSpace(selector.Expression) == "" {
+		return nil, nil
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable(deviceSelectorVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Function("quantity",
+			cel.Overload("quantity_string_int",
+				[]*cel.Type{cel.StringType},
+				cel.IntType,
+				cel.UnaryBinding(quantityToBytes),
+			),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression environment: %w", err)
+	}
+
+	ast, issues := env.Compile(selector.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid device selector expression: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("device selector expression must evaluate to bool, got %v", ast.OutputType())
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(deviceSelectorCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build device selector program: %w", err)
+	}
+	return &DeviceSelectorProgram{expression: selector.Expression, program: program}, nil
+}
+
+// Matches evaluates the expression against dev
+func (p *DeviceSelectorProgram) Matches(dev internal.BlockDevice) (bool, error) {
+	if p == nil {
+		return true, nil
+	}
+	attributes, err := DeviceSelectorAttributes(dev)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := p.program.Eval(map[string]any{deviceSelectorVariable: attributes})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate device selector %q: %w", p.expression, err)
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("device selector %q returned non-bool value %v", p.expression, out.Value())
+	}
+	return matched, nil
+}
+
+// DeviceSelectorAttributes returns the attributes of dev that are visible to a device selector expression
+func DeviceSelectorAttributes(dev internal.BlockDevice) (map[string]any, error) {
+	size, err := dev.GetSize()
+	if err != nil {
+		return nil, err
+	}
+	rotational, err := dev.GetRotational()
+	if err != nil {
+		return nil, err
+	}
+	discardMax, err := dev.GetDiscardMax()
+	if err != nil {
+		return nil, err
+	}
+	// sector sizes are not reported for every device type, default to 0 instead of failing
+	logicalSectorSize, _ := strconv.ParseInt(dev.LogicalSectorSize, 10, 64)
+	physicalSectorSize, _ := strconv.ParseInt(dev.PhysicalSectorSize, 10, 64)
+
+	byIDLinks := make([]string, 0)
+	links, err := dev.GetValidByIDSymlinks()
+	if err != nil {
+		// links are best effort, the rest of the attributes are still usable
+		klog.ErrorS(err, "could not list by-id links for device selector", "device", dev.Name)
+	}
+	for _, link := range links {
+		byIDLinks = append(byIDLinks, filepath.Base(link))
+	}
+	byPathLinks := make([]string, 0)
+	links, err = dev.GetValidByPathSymlinks()
+	if err != nil {
+		klog.ErrorS(err, "could not list by-path links for device selector", "device", dev.Name)
+	}
+	for _, link := range links {
+		byPathLinks = append(byPathLinks, filepath.Base(link))
+	}
+
+	udev := dev.UdevProperties
+	if udev == nil {
+		udev = map[string]string{}
+	}
+
+	return map[string]any{
+		"name":               dev.Name,
+		"kname":              dev.KName,
+		"type":               dev.Type,
+		"model":              dev.Model,
+		"vendor":             dev.Vendor,
+		"serial":             dev.Serial,
+		"wwn":                dev.WWN,
+		"partLabel":          dev.PartLabel,
+		"partType":           dev.PartType,
+		"partUUID":           dev.PartUUID,
+		"fsType":             dev.FSType,
+		"transport":          dev.Transport,
+		"zoned":              dev.Zoned,
+		"size":               size,
+		"logicalSectorSize":  logicalSectorSize,
+		"physicalSectorSize": physicalSectorSize,
+		"discardMax":         discardMax,
+		"rotational":         rotational,
+		"byIDLinks":          byIDLinks,
+		"byPathLinks":        byPathLinks,
+		"udev":               udev,
+	}, nil
+}
+
+func quantityToBytes(arg ref.Val) ref.Val {
+	str, ok := arg.Value().(string)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(arg)
+	}
+	quantity, err := resource.ParseQuantity(str)
+	if err != nil {
+		return types.NewErr("invalid quantity %q: %v", str, err)
+	}
+	return types.Int(quantity.Value())
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileDeviceSelector(t *testing.T) {
+	testCases := []struct {
+		name          string
+		selector      *localv1alpha1.DeviceSelector
+		expectProgram bool
+		expectErr     bool
+	}{
+		{name: "nil selector", selector: nil},
+		{name: "empty expression", selector: &localv1alpha1.DeviceSelector{Expression: "  "}},
+		{name: "valid expression", selector: &localv1alpha1.DeviceSelector{Expression: `device.type == "disk"`}, expectProgram: true},
+		{name: "syntax error", selector: &localv1alpha1.DeviceSelector{Expression: `device.type ==`}, expectErr: true},
+		{name: "non-bool result", selector: &localv1alpha1.DeviceSelector{Expression: `device.size + 1`}, expectErr: true},
+		{name: "unknown variable", selector: &localv1alpha1.DeviceSelector{Expression: `disk.size > 1`}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := CompileDeviceSelector(tc.selector)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectProgram, program != nil)
+		})
+	}
+}
+
+func TestDeviceSelectorMatches(t *testing.T) {
+	origGlob := internal.FilePathGlob
+	origEval := internal.FilePathEvalSymLinks
+	defer func() {
+		internal.FilePathGlob = origGlob
+		internal.FilePathEvalSymLinks = origEval
+	}()
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		return []string{"/dev/disk/by-id/nvme-eui.0001", "/dev/disk/by-id/wwn-0x5000"}, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		if path == "/dev/disk/by-id/nvme-eui.0001" {
+			return "/dev/nvme0n1", nil
+		}
+		return "/dev/sda", nil
+	}
+
+	nvme := internal.BlockDevice{Name: "nvme0n1", KName: "nvme0n1", Type: "disk", Size: "2199023255552", Rotational: "0", Model: "Fast NVMe"}
+	hdd := internal.BlockDevice{Name: "sda", KName: "sda", Type: "disk", Size: "4398046511104", Rotational: "1", Vendor: "ACME", Serial: "S0"}
+
+	testCases := []struct {
+		name        string
+		expression  string
+		dev         internal.BlockDevice
+		expectMatch bool
+		expectErr   bool
+	}{
+		{name: "large nvme", expression: `!device.rotational && device.size > quantity("1Ti")`, dev: nvme, expectMatch: true},
+		{name: "hdd is rotational", expression: `!device.rotational && device.size > quantity("1Ti")`, dev: hdd, expectMatch: false},
+		{
+			name:        "or of two classes",
+			expression:  `(device.kname.startsWith("nvme") && device.size > quantity("1Ti")) || (device.vendor == "ACME" && device.serial != "S0")`,
+			dev:         hdd,
+			expectMatch: false,
+		},
+		{name: "by-id links", expression: `device.byIDLinks.exists(l, l.startsWith("nvme-eui."))`, dev: nvme, expectMatch: true},
+		{name: "by-id links of other device", expression: `"nvme-eui.0001" in device.byIDLinks`, dev: hdd, expectMatch: false},
+		{name: "invalid quantity", expression: `device.size > quantity("lots")`, dev: nvme, expectErr: true},
+		{name: "unparsable size", expression: `true`, dev: internal.BlockDevice{Size: "big"}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := CompileDeviceSelector(&localv1alpha1.DeviceSelector{Expression: tc.expression})
+			assert.NoError(t, err)
+			matched, err := program.Matches(tc.dev)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectMatch, matched)
+		})
+	}
+}
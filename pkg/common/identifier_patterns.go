@@ -0,0 +1,152 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+)
+
+const (
+	// IdentifierRegexPrefix marks the identifier patterns that are regular expressions rather than globs
+	IdentifierRegexPrefix = "regex:"
+	// foldCaseFlag makes a regular expression case insensitive
+	foldCaseFlag = "(?i)"
+)
+
+// identifierRegexes caches the compiled regular expressions of identifier patterns by expression,
+// so that they are not compiled again for every device
+var identifierRegexes sync.Map
+
+func compileIdentifierRegex(expr string) (*regexp.Regexp, error) {
+	if re, found := identifierRegexes.Load(expr); found {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	identifierRegexes.Store(expr, re)
+	return re, nil
+}
+
+// MatchesAnyIdentifier checks whether any of the non-empty values matches any of the patterns.
+// Patterns are globs following filepath.Match syntax unless they are prefixed with IdentifierRegexPrefix.
+func MatchesAnyIdentifier(patterns []string, values ...string) (bool, error) {
+	for _, pattern := range patterns {
+		var match func(string) (bool, error)
+		if expr, ok := strings.CutPrefix(pattern, IdentifierRegexPrefix); ok {
+			re, err := compileIdentifierRegex(expr)
+			if err != nil {
+				return false, fmt.Errorf("invalid identifier regex %q: %w", expr, err)
+			}
+			match = func(value string) (bool, error) { return re.MatchString(value), nil }
+		} else {
+			match = func(value string) (bool, error) { return filepath.Match(pattern, value) }
+		}
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			matched, err := match(value)
+			if err != nil {
+				return false, fmt.Errorf("invalid identifier pattern %q: %w", pattern, err)
+			}
+			if matched {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// MatchesAnyIdentifierFold is MatchesAnyIdentifier ignoring case, for identifiers such as
+// GUIDs that are written in upper or lower case
+func MatchesAnyIdentifierFold(patterns []string, values ...string) (bool, error) {
+	foldedValues := make([]string, 0, len(values))
+	for _, value := range values {
+		foldedValues = append(foldedValues, strings.ToLower(value))
+	}
+	return MatchesAnyIdentifier(foldIdentifierPatterns(patterns), foldedValues...)
+}
+
+func foldIdentifierPatterns(patterns []string) []string {
+	foldedPatterns := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, IdentifierRegexPrefix); ok {
+			foldedPatterns = append(foldedPatterns, IdentifierRegexPrefix+foldCaseFlag+expr)
+		} else {
+			foldedPatterns = append(foldedPatterns, strings.ToLower(pattern))
+		}
+	}
+	return foldedPatterns
+}
+
+// CompileIdentifierPatterns compiles all identifier patterns of lvset, including the ones of its
+// classes and node overrides, and returns an error listing the invalid ones
+func CompileIdentifierPatterns(lvset *localv1alpha1.LocalVolumeSet) error {
+	problems := make([]string, 0)
+	compile := func(field string, patterns []string) {
+		for i, pattern := range patterns {
+			var err error
+			if expr, ok := strings.CutPrefix(pattern, IdentifierRegexPrefix); ok {
+				_, err = compileIdentifierRegex(expr)
+			} else {
+				// a malformed glob is reported by Match regardless of the name
+				_, err = filepath.Match(pattern, "")
+			}
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s[%d] %q: %v", field, i, pattern, err))
+			}
+		}
+	}
+	compileIdentifiers := func(field string, spec localv1alpha1.DeviceIdentifierSpec) {
+		compile(field+".serials", spec.Serials)
+		compile(field+".wwns", spec.WWNs)
+		compile(field+".byPathNames", spec.ByPathNames)
+		compile(field+".byIDNames", spec.ByIDNames)
+		compile(field+".volumeGroups", spec.VolumeGroups)
+		compile(field+".logicalVolumes", spec.LogicalVolumes)
+		compile(field+".partLabels", spec.PartLabels)
+		compile(field+".partTypes", foldIdentifierPatterns(spec.PartTypes))
+		compile(field+".partUUIDs", foldIdentifierPatterns(spec.PartUUIDs))
+	}
+	compileInclusion := func(field string, spec *localv1alpha1.DeviceInclusionSpec) {
+		if spec == nil {
+			return
+		}
+		compileIdentifiers(field, spec.DeviceIdentifierSpec)
+		keys := make([]string, 0, len(spec.UdevProperties))
+		for key := range spec.UdevProperties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			compile(fmt.Sprintf("%s.udevProperties[%s]", field, key), []string{spec.UdevProperties[key]})
+		}
+	}
+	compileExclusion := func(field string, spec *localv1alpha1.DeviceExclusionSpec) {
+		if spec == nil {
+			return
+		}
+		compileIdentifiers(field, spec.DeviceIdentifierSpec)
+	}
+
+	compileInclusion("deviceInclusionSpec", lvset.Spec.DeviceInclusionSpec)
+	compileExclusion("deviceExclusionSpec", lvset.Spec.DeviceExclusionSpec)
+	for i, override := range lvset.Spec.NodeOverrides {
+		compileInclusion(fmt.Sprintf("nodeOverrides[%d].deviceInclusionSpec", i), override.DeviceInclusionSpec)
+		compileExclusion(fmt.Sprintf("nodeOverrides[%d].deviceExclusionSpec", i), override.DeviceExclusionSpec)
+	}
+	for i, class := range lvset.Spec.Classes {
+		compileInclusion(fmt.Sprintf("classes[%d].deviceInclusionSpec", i), class.DeviceInclusionSpec)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid identifier patterns: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
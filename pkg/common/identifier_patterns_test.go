@@ -0,0 +1,53 @@
+package common
+
+import (
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileIdentifierPatterns(t *testing.T) {
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{
+				DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{
+					Serials:   []string{"S3EV*", "regex:^Z1X[0-9]{5}$"},
+					PartTypes: []string{"regex:^0FC63DAF-"},
+				},
+				UdevProperties: map[string]string{"ID_BUS": "ata"},
+			},
+		},
+	}
+	assert.NoError(t, CompileIdentifierPatterns(lvset))
+
+	lvset.Spec.Classes = []localv1alpha1.LocalVolumeSetClass{{
+		StorageClassName: "fast",
+		DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{
+			DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{WWNs: []string{"regex:("}},
+		},
+	}}
+	lvset.Spec.DeviceExclusionSpec = &localv1alpha1.DeviceExclusionSpec{
+		DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByIDNames: []string{"wwn-["}},
+	}
+	err := CompileIdentifierPatterns(lvset)
+	assert.ErrorContains(t, err, `classes[0].deviceInclusionSpec.wwns[0] "regex:("`)
+	assert.ErrorContains(t, err, `deviceExclusionSpec.byIDNames[0] "wwn-["`)
+}
+
+func TestMatchesAnyIdentifier(t *testing.T) {
+	matched, err := MatchesAnyIdentifier([]string{"S3EV*", "regex:^Z1X[0-9]{5}$"}, "", "Z1X12345")
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	// compiled once and served from the cache afterwards
+	_, found := identifierRegexes.Load("^Z1X[0-9]{5}$")
+	assert.True(t, found)
+
+	matched, err = MatchesAnyIdentifierFold([]string{"regex:^5C2F7A3E-"}, "5c2f7a3e-0000")
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	_, err = MatchesAnyIdentifier([]string{"regex:("}, "Z1X12345")
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package common
+
+import (
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+)
+
+// LocalVolumeSetClasses returns a LocalVolumeSet for each class of lvset in the order in which devices
+// are sorted into them: a copy of lvset with the class merged into its spec for each of its Classes,
+// followed by lvset itself without Classes for the devices that match none of them.
+func LocalVolumeSetClasses(lvset *localv1alpha1.LocalVolumeSet) []*localv1alpha1.LocalVolumeSet {
+	if len(lvset.Spec.Classes) == 0 {
+		return []*localv1alpha1.LocalVolumeSet{lvset}
+	}
+	classes := make([]*localv1alpha1.LocalVolumeSet, 0, len(lvset.Spec.Classes)+1)
+	for _, class := range lvset.Spec.Classes {
+		effective := lvset.DeepCopy()
+		effective.Spec.Classes = nil
+		effective.Spec.StorageClassName = class.StorageClassName
+		effective.Spec.DeviceInclusionSpec = mergeDeviceInclusionSpec(lvset.Spec.DeviceInclusionSpec, class.DeviceInclusionSpec)
+		if class.VolumeMode != "" {
+			effective.Spec.VolumeMode = class.VolumeMode
+		}
+		if class.FSType != "" {
+			effective.Spec.FSType = class.FSType
+		}
+		if class.MaxDeviceCount != nil {
+			maxDeviceCount := *class.MaxDeviceCount
+			effective.Spec.MaxDeviceCount = &maxDeviceCount
+		}
+		classes = append(classes, effective)
+	}
+	defaultClass := lvset.DeepCopy()
+	defaultClass.Spec.Classes = nil
+	return append(classes, defaultClass)
+}
+
+// mergeDeviceInclusionSpec returns the DeviceInclusionSpec of a class: the fields set in class
+// replace the ones of spec, which is the effective DeviceInclusionSpec of the LocalVolumeSet on the node
+func mergeDeviceInclusionSpec(spec, class *localv1alpha1.DeviceInclusionSpec) *localv1alpha1.DeviceInclusionSpec {
+	if class == nil {
+		return spec.DeepCopy()
+	}
+	if spec == nil {
+		return class.DeepCopy()
+	}
+	merged := spec.DeepCopy()
+	class = class.DeepCopy()
+	replaceIfSet(&merged.DeviceTypes, class.DeviceTypes)
+	replaceIfSet(&merged.DeviceMechanicalProperties, class.DeviceMechanicalProperties)
+	if class.MinSize != nil {
+		merged.MinSize = class.MinSize
+	}
+	if class.MaxSize != nil {
+		merged.MaxSize = class.MaxSize
+	}
+	replaceIfSet(&merged.Models, class.Models)
+	replaceIfSet(&merged.Vendors, class.Vendors)
+	replaceIfSet(&merged.Transports, class.Transports)
+	replaceIfSet(&merged.LogicalSectorSizes, class.LogicalSectorSizes)
+	replaceIfSet(&merged.ZonedModels, class.ZonedModels)
+	merged.RequireDiscard = merged.RequireDiscard || class.RequireDiscard
+	if len(class.UdevProperties) > 0 {
+		merged.UdevProperties = class.UdevProperties
+	}
+	replaceIfSet(&merged.Serials, class.Serials)
+	replaceIfSet(&merged.WWNs, class.WWNs)
+	replaceIfSet(&merged.ByPathNames, class.ByPathNames)
+	replaceIfSet(&merged.ByIDNames, class.ByIDNames)
+	replaceIfSet(&merged.VolumeGroups, class.VolumeGroups)
+	replaceIfSet(&merged.LogicalVolumes, class.LogicalVolumes)
+	replaceIfSet(&merged.PartLabels, class.PartLabels)
+	replaceIfSet(&merged.PartTypes, class.PartTypes)
+	replaceIfSet(&merged.PartUUIDs, class.PartUUIDs)
+	return merged
+}
+
+func replaceIfSet[T any](field *[]T, value []T) {
+	if len(value) > 0 {
+		*field = value
+	}
+}
+
+// LocalVolumeSetStorageClassNames returns the names of all StorageClasses lvset provisions devices for
+func LocalVolumeSetStorageClassNames(lvset *localv1alpha1.LocalVolumeSet) []string {
+	names := []string{lvset.Spec.StorageClassName}
+	for _, class := range lvset.Spec.Classes {
+		names = append(names, class.StorageClassName)
+	}
+	return names
+}
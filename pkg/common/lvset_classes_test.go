@@ -0,0 +1,93 @@
+package common
+
+import (
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestLocalVolumeSetClasses(t *testing.T) {
+	lvset := &localv1alpha1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "lvset", Namespace: "ns"},
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName:    "default-sc",
+			MaxDeviceCount:      ptr.To[int32](4),
+			VolumeMode:          localv1.PersistentVolumeFilesystem,
+			FSType:              "xfs",
+			DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{Models: []string{"PM1733"}},
+			DeviceExclusionSpec: &localv1alpha1.DeviceExclusionSpec{Vendors: []string{"ACME"}},
+		},
+	}
+
+	// without classes lvset is the only class
+	classes := LocalVolumeSetClasses(lvset)
+	assert.Equal(t, []*localv1alpha1.LocalVolumeSet{lvset}, classes)
+	assert.Equal(t, []string{"default-sc"}, LocalVolumeSetStorageClassNames(lvset))
+
+	nonRotational := &localv1alpha1.DeviceInclusionSpec{
+		DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.NonRotational},
+	}
+	lvset.Spec.Classes = []localv1alpha1.LocalVolumeSetClass{
+		{
+			StorageClassName:    "nvme-fast",
+			DeviceInclusionSpec: nonRotational,
+			VolumeMode:          localv1.PersistentVolumeBlock,
+		},
+		{
+			StorageClassName: "hdd-bulk",
+			FSType:           "ext4",
+			MaxDeviceCount:   ptr.To[int32](12),
+		},
+	}
+	classes = LocalVolumeSetClasses(lvset)
+	assert.Equal(t, []string{"default-sc", "nvme-fast", "hdd-bulk"}, LocalVolumeSetStorageClassNames(lvset))
+	if !assert.Len(t, classes, 3) {
+		return
+	}
+
+	assert.Equal(t, "nvme-fast", classes[0].Spec.StorageClassName)
+	// the class only replaces the fields it sets
+	assert.Equal(t, &localv1alpha1.DeviceInclusionSpec{
+		Models:                     []string{"PM1733"},
+		DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.NonRotational},
+	}, classes[0].Spec.DeviceInclusionSpec)
+	assert.Equal(t, localv1.PersistentVolumeBlock, classes[0].Spec.VolumeMode)
+	assert.Equal(t, "xfs", classes[0].Spec.FSType)
+	assert.Equal(t, int32(4), *classes[0].Spec.MaxDeviceCount)
+	assert.Equal(t, lvset.Spec.DeviceExclusionSpec, classes[0].Spec.DeviceExclusionSpec)
+
+	assert.Equal(t, "hdd-bulk", classes[1].Spec.StorageClassName)
+	assert.Equal(t, lvset.Spec.DeviceInclusionSpec, classes[1].Spec.DeviceInclusionSpec)
+	assert.Equal(t, localv1.PersistentVolumeFilesystem, classes[1].Spec.VolumeMode)
+	assert.Equal(t, "ext4", classes[1].Spec.FSType)
+	assert.Equal(t, int32(12), *classes[1].Spec.MaxDeviceCount)
+
+	assert.Equal(t, "default-sc", classes[2].Spec.StorageClassName)
+	assert.Equal(t, lvset.Spec.DeviceInclusionSpec, classes[2].Spec.DeviceInclusionSpec)
+	for _, class := range classes {
+		assert.Empty(t, class.Spec.Classes)
+		assert.Equal(t, "lvset", class.Name)
+	}
+	// lvset is not modified
+	assert.Len(t, lvset.Spec.Classes, 2)
+	assert.Equal(t, []string{"PM1733"}, lvset.Spec.DeviceInclusionSpec.Models)
+	assert.Empty(t, lvset.Spec.DeviceInclusionSpec.DeviceMechanicalProperties)
+
+	// the DeviceInclusionSpec of a node override is kept for the classes as well
+	overridden := lvset.DeepCopy()
+	overridden.Spec.DeviceInclusionSpec = &localv1alpha1.DeviceInclusionSpec{
+		DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk, localv1alpha1.Partition},
+		Models:      []string{"PM9A3"},
+	}
+	classes = LocalVolumeSetClasses(overridden)
+	assert.Equal(t, &localv1alpha1.DeviceInclusionSpec{
+		DeviceTypes:                []localv1alpha1.DeviceType{localv1alpha1.RawDisk, localv1alpha1.Partition},
+		Models:                     []string{"PM9A3"},
+		DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.NonRotational},
+	}, classes[0].Spec.DeviceInclusionSpec)
+	assert.Equal(t, overridden.Spec.DeviceInclusionSpec, classes[1].Spec.DeviceInclusionSpec)
+}
@@ -18,6 +18,9 @@ const (
 	defaultKubeProxyImage        = "quay.io/openshift/origin-kube-rbac-proxy:latest"
 	defaultlocalDiskLocation     = "/mnt/local-storage"
 
+	// DiskMakerStateDir is the host directory in which the diskmaker keeps state across restarts
+	DiskMakerStateDir = "/var/lib/local-storage-operator"
+
 	// OwnerNamespaceLabel references the owning object's namespace
 	OwnerNamespaceLabel = "local.storage.openshift.io/owner-namespace"
 	// OwnerNameLabel references the owning object
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ApplyNodeOverrides returns the LocalVolumeSet as it applies to node: a copy of lvset with the
+// first NodeOverride whose NodeSelector matches node merged into its spec, and the index of that
+// override. lvset itself and -1 are returned if no override matches.
+func ApplyNodeOverrides(lvset *localv1alpha1.LocalVolumeSet, node *corev1.Node) (*localv1alpha1.LocalVolumeSet, int, error) {
+	for i, override := range lvset.Spec.NodeOverrides {
+		matches, err := NodeSelectorMatchesNodeLabels(node, override.NodeSelector)
+		if err != nil {
+			return nil, -1, fmt.Errorf("failed to match nodeSelector of nodeOverrides[%d]: %w", i, err)
+		}
+		if !matches {
+			continue
+		}
+
+		effective := lvset.DeepCopy()
+		if override.DeviceInclusionSpec != nil {
+			effective.Spec.DeviceInclusionSpec = override.DeviceInclusionSpec.DeepCopy()
+		}
+		if override.DeviceExclusionSpec != nil {
+			effective.Spec.DeviceExclusionSpec = override.DeviceExclusionSpec.DeepCopy()
+		}
+		if override.MaxDeviceCount != nil {
+			maxDeviceCount := *override.MaxDeviceCount
+			effective.Spec.MaxDeviceCount = &maxDeviceCount
+		}
+		if override.FSType != "" {
+			effective.Spec.FSType = override.FSType
+		}
+		return effective, i, nil
+	}
+	return lvset, -1, nil
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func generationSelector(generation string) *corev1.NodeSelector {
+	return &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "hardware-generation", Operator: corev1.NodeSelectorOpIn, Values: []string{generation}},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyNodeOverrides(t *testing.T) {
+	minSize := resource.MustParse("100Gi")
+	lvset := &localv1alpha1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "lvset", Namespace: "ns"},
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName:    "fast",
+			MaxDeviceCount:      ptr.To[int32](4),
+			FSType:              "xfs",
+			DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{Models: []string{"PM1733"}},
+			NodeOverrides: []localv1alpha1.NodeOverride{
+				{
+					NodeSelector:        generationSelector("gen2"),
+					DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{Models: []string{"PM9A3"}, MinSize: &minSize},
+					MaxDeviceCount:      ptr.To[int32](8),
+				},
+				{
+					NodeSelector: generationSelector("gen2"),
+					FSType:       "ext4",
+				},
+				{
+					NodeSelector:        generationSelector("gen3"),
+					DeviceExclusionSpec: &localv1alpha1.DeviceExclusionSpec{Vendors: []string{"ACME"}},
+					FSType:              "ext4",
+				},
+			},
+		},
+	}
+	nodeWithGeneration := func(generation string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"hardware-generation": generation}}}
+	}
+
+	// no matching override
+	effective, override, err := ApplyNodeOverrides(lvset, nodeWithGeneration("gen1"))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, override)
+	assert.Same(t, lvset, effective)
+
+	// the first matching override wins, unset fields are kept
+	effective, override, err = ApplyNodeOverrides(lvset, nodeWithGeneration("gen2"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, override)
+	assert.Equal(t, []string{"PM9A3"}, effective.Spec.DeviceInclusionSpec.Models)
+	assert.Equal(t, int32(8), *effective.Spec.MaxDeviceCount)
+	assert.Equal(t, "xfs", effective.Spec.FSType)
+	assert.Equal(t, "fast", effective.Spec.StorageClassName)
+	assert.Equal(t, lvset.ObjectMeta, effective.ObjectMeta)
+
+	effective, override, err = ApplyNodeOverrides(lvset, nodeWithGeneration("gen3"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, override)
+	assert.Equal(t, []string{"PM1733"}, effective.Spec.DeviceInclusionSpec.Models)
+	assert.Equal(t, []string{"ACME"}, effective.Spec.DeviceExclusionSpec.Vendors)
+	assert.Equal(t, int32(4), *effective.Spec.MaxDeviceCount)
+	assert.Equal(t, "ext4", effective.Spec.FSType)
+
+	// the original LocalVolumeSet is not modified
+	assert.Equal(t, []string{"PM1733"}, lvset.Spec.DeviceInclusionSpec.Models)
+	assert.Nil(t, lvset.Spec.DeviceExclusionSpec)
+	assert.Equal(t, "xfs", lvset.Spec.FSType)
+
+	// a node is required to match selectors
+	_, _, err = ApplyNodeOverrides(lvset, nil)
+	assert.Error(t, err)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"hash/fnv"
 	"path/filepath"
-	"strings"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/pkg/internal"
@@ -63,6 +62,16 @@ type SyncPVAndLVDLArgs struct {
 	BlockDevice internal.BlockDevice
 	// CacheWriter enables write-through updates to the LVDL in-memory cache.
 	CacheWriter *LocalVolumeDeviceLinkCache
+	// FSType, when set, replaces the filesystem type of the storage class provisioner config.
+	FSType string
+	// VolumeMode, when set, replaces the volume mode of the storage class provisioner config.
+	VolumeMode corev1.PersistentVolumeMode
+	// ExtraAnnotationsForPV are added to the annotations of the PV, those set by the operator take precedence.
+	ExtraAnnotationsForPV map[string]string
+	// PVTopology selects the node labels that are copied onto the PV
+	PVTopology *localv1.PVTopology
+	// ClaimRef is the PersistentVolumeClaim the PV is pre-bound to, if any
+	ClaimRef *localv1.PVClaimRef
 }
 
 // SyncPVAndLVDL ensures the PV exists for a symlinked device and keeps its LocalVolumeDeviceLink in sync.
@@ -85,17 +94,18 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 
 	pvName := GeneratePVName(filepath.Base(symLinkPath), runtimeConfig.Node.Name, storageClass.Name)
 
+	topologyLabels, topologyRequirements := nodeTopology(args.PVTopology, nodeLabels)
 	nodeAffinity := &corev1.VolumeNodeAffinity{
 		Required: &corev1.NodeSelector{
 			NodeSelectorTerms: []corev1.NodeSelectorTerm{
 				{
-					MatchExpressions: []corev1.NodeSelectorRequirement{
+					MatchExpressions: append([]corev1.NodeSelectorRequirement{
 						{
 							Key:      corev1.LabelHostname,
 							Operator: corev1.NodeSelectorOpIn,
 							Values:   []string{hostname},
 						},
-					},
+					}, topologyRequirements...),
 				},
 			},
 		},
@@ -140,7 +150,7 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 		return fmt.Errorf("unable to resolve symlink %s: %v", symLinkPath, err)
 	}
 
-	idExists := strings.HasPrefix(effectiveCurrentSource, internal.DiskByIDDir)
+	idExists := internal.IsStableDevicePath(effectiveCurrentSource)
 
 	mountConfig, found := runtimeConfig.DiscoveryMap[storageClass.GetName()]
 	if !found {
@@ -148,6 +158,9 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 	}
 
 	desiredVolumeMode := corev1.PersistentVolumeMode(mountConfig.VolumeMode)
+	if args.VolumeMode != "" {
+		desiredVolumeMode = args.VolumeMode
+	}
 
 	actualVolumeMode, err := provCommon.GetVolumeMode(runtimeConfig.VolUtil, symLinkPath)
 	if err != nil {
@@ -192,19 +205,28 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 		return fmt.Errorf("path %q has unexpected volume type %q", symLinkPath, actualVolumeMode)
 	}
 
-	labels := map[string]string{
-		corev1.LabelHostname:  hostname,
-		PVOwnerKindLabel:      kind,
-		PVOwnerNamespaceLabel: namespace,
-		PVOwnerNameLabel:      name,
-	}
+	labels := map[string]string{}
 	for key, value := range extraLabelsForPV {
 		labels[key] = value
 	}
-	annotations := map[string]string{
-		PVDeviceNameLabel:           deviceName,
-		provCommon.AnnProvisionedBy: runtimeConfig.Name,
+	// the labels of the node describe where the PV is, they take precedence over the extra labels
+	for key, value := range topologyLabels {
+		if extraValue, found := labels[key]; found && extraValue != value {
+			klog.InfoS("topology label overrides PV label", "pvName", pvName, "label", key, "value", value, "ignoredValue", extraValue)
+		}
+		labels[key] = value
+	}
+	labels[corev1.LabelHostname] = hostname
+	labels[PVOwnerKindLabel] = kind
+	labels[PVOwnerNamespaceLabel] = namespace
+	labels[PVOwnerNameLabel] = name
+
+	annotations := map[string]string{}
+	for key, value := range args.ExtraAnnotationsForPV {
+		annotations[key] = value
 	}
+	annotations[PVDeviceNameLabel] = deviceName
+	annotations[provCommon.AnnProvisionedBy] = runtimeConfig.Name
 	if idExists {
 		annotations[PVDeviceIDLabel] = filepath.Base(symLinkPath)
 	}
@@ -231,10 +253,17 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 		NodeAffinity:    nodeAffinity,
 	}
 	fsType := mountConfig.FsType
+	if args.FSType != "" {
+		fsType = args.FSType
+	}
 	if desiredVolumeMode == corev1.PersistentVolumeFilesystem && fsType != "" {
 		localPVConfig.FsType = &fsType
 	}
 	newPV := provCommon.CreateLocalPVSpec(localPVConfig)
+	if args.ClaimRef != nil {
+		newPV.Spec.ClaimRef = PVClaimReference(*args.ClaimRef)
+		newPV.Annotations[PreBoundClaimAnnotation] = args.ClaimRef.String()
+	}
 
 	// Add finalizer for diskmaker symlink cleanup
 	controllerutil.AddFinalizer(newPV, LSOSymlinkDeleterFinalizer)
@@ -275,6 +304,28 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 			}
 		}
 
+		// undo our own pre-binding if the claimRef was removed or changed before the claim was bound
+		claimRef := existingPV.Spec.ClaimRef
+		if claimRef != nil && claimRef.UID == "" && existingPV.Annotations[PreBoundClaimAnnotation] == claimRef.Namespace+"/"+claimRef.Name &&
+			(args.ClaimRef == nil || claimRef.Namespace != args.ClaimRef.Namespace || claimRef.Name != args.ClaimRef.Name) {
+			klog.InfoS("removing pre-binding of PV", "pvName", pvName, "claim", existingPV.Annotations[PreBoundClaimAnnotation])
+			existingPV.Spec.ClaimRef = nil
+			delete(existingPV.Annotations, PreBoundClaimAnnotation)
+			claimRef = nil
+		}
+
+		// pre-bind PVs that were created before the claimRef was set, unless they are claimed already
+		if args.ClaimRef != nil {
+			switch {
+			case claimRef == nil && existingPV.Status.Phase == corev1.VolumeAvailable:
+				existingPV.Spec.ClaimRef = PVClaimReference(*args.ClaimRef)
+				existingPV.Annotations[PreBoundClaimAnnotation] = args.ClaimRef.String()
+			case claimRef != nil && (claimRef.Namespace != args.ClaimRef.Namespace || claimRef.Name != args.ClaimRef.Name):
+				runtimeConfig.Recorder.Eventf(existingPV, corev1.EventTypeWarning, ClaimRefConflict,
+					"PV is claimed by %s/%s, not pre-binding it to %s", claimRef.Namespace, claimRef.Name, args.ClaimRef)
+			}
+		}
+
 		return nil
 	})
 	if opRes != controllerutil.OperationResultNone {
@@ -295,6 +346,32 @@ func SyncPVAndLVDL(ctx context.Context, args SyncPVAndLVDLArgs) error {
 	return nil
 }
 
+// nodeTopology returns the node labels that topology copies onto PVs, and the node affinity
+// requirements for them if topology adds them to the node affinity
+func nodeTopology(topology *localv1.PVTopology, nodeLabels map[string]string) (map[string]string, []corev1.NodeSelectorRequirement) {
+	labels := map[string]string{}
+	requirements := make([]corev1.NodeSelectorRequirement, 0)
+	if topology == nil {
+		return labels, requirements
+	}
+	for _, key := range topology.NodeLabels {
+		value, found := nodeLabels[key]
+		// the hostname is always part of the node affinity
+		if !found || key == corev1.LabelHostname {
+			continue
+		}
+		labels[key] = value
+		if topology.AddToNodeAffinity {
+			requirements = append(requirements, corev1.NodeSelectorRequirement{
+				Key:      key,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{value},
+			})
+		}
+	}
+	return labels, requirements
+}
+
 // GeneratePVName is used to generate a PV name based on the filename, node, and storageclass
 // Important, this hash value should remain consistent, so this function should not be changed
 // in a way that would change its output.
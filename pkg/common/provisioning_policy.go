@@ -0,0 +1,24 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+)
+
+// ProvisioningPolicyRelaxations returns a description of every filter that is relaxed by policy
+func ProvisioningPolicyRelaxations(policy *localv1alpha1.ProvisioningPolicy) []string {
+	relaxations := make([]string, 0)
+	if policy == nil {
+		return relaxations
+	}
+	if policy.AllowRemovableDevices {
+		relaxations = append(relaxations, "allowRemovableDevices: removable devices are provisioned")
+	}
+	if len(policy.AllowedFilesystemTypes) > 0 {
+		relaxations = append(relaxations, fmt.Sprintf("allowedFilesystemTypes: devices with existing %s filesystems are provisioned",
+			strings.Join(policy.AllowedFilesystemTypes, ", ")))
+	}
+	return relaxations
+}
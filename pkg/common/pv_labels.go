@@ -0,0 +1,98 @@
+package common
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// invalidLabelValueChars matches the runs of characters that are not allowed in label values
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// pvLabelAttributes maps the attributes of PVLabelPolicy to their label keys, in the order they are added
+var pvLabelAttributes = []struct {
+	attribute localv1.PVLabelAttribute
+	key       string
+	value     func(internal.BlockDevice) (string, error)
+}{
+	{localv1.PVLabelRotational, PVDeviceRotationalLabel, func(dev internal.BlockDevice) (string, error) {
+		rotational, err := dev.GetRotational()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(rotational), nil
+	}},
+	{localv1.PVLabelTransport, PVDeviceTransportLabel, func(dev internal.BlockDevice) (string, error) {
+		return strings.ToLower(dev.Transport), nil
+	}},
+	{localv1.PVLabelVendor, PVDeviceVendorLabel, func(dev internal.BlockDevice) (string, error) {
+		return dev.GetVendor(), nil
+	}},
+	{localv1.PVLabelModel, PVDeviceModelLabel, func(dev internal.BlockDevice) (string, error) {
+		return dev.GetModel(), nil
+	}},
+	{localv1.PVLabelSizeBucket, PVDeviceSizeBucketLabel, func(dev internal.BlockDevice) (string, error) {
+		size, err := dev.GetSize()
+		if err != nil || size <= 0 {
+			return "", err
+		}
+		return sizeBucket(size), nil
+	}},
+	{localv1.PVLabelSlot, PVDeviceSlotLabel, func(dev internal.BlockDevice) (string, error) {
+		return dev.GetSlot()
+	}},
+}
+
+// DeviceLabelsForPV returns the labels that policy adds to the PV of dev.
+// Returns an empty map if policy is nil.
+func DeviceLabelsForPV(policy *localv1.PVLabelPolicy, dev internal.BlockDevice) map[string]string {
+	labels := map[string]string{}
+	if policy == nil {
+		return labels
+	}
+	for _, attr := range pvLabelAttributes {
+		if len(policy.Attributes) > 0 && !slices.Contains(policy.Attributes, attr.attribute) {
+			continue
+		}
+		value, err := attr.value(dev)
+		if err != nil {
+			klog.ErrorS(err, "could not determine device attribute for PV label", "device", dev.Name, "attribute", attr.attribute)
+			continue
+		}
+		if value = normalizeLabelValue(value); value != "" {
+			labels[attr.key] = value
+		}
+	}
+	return labels
+}
+
+// sizeBucket returns size in bytes rounded up to a power of two, starting at 1Gi
+func sizeBucket(size int64) string {
+	bucket := int64(GiB)
+	for bucket < size {
+		bucket *= 2
+	}
+	return resource.NewQuantity(bucket, resource.BinarySI).String()
+}
+
+// normalizeLabelValue turns value into a valid label value, or an empty string if nothing is left of it
+func normalizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(strings.TrimSpace(value), "_")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	value = strings.TrimFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if len(validation.IsValidLabelValue(value)) > 0 {
+		return ""
+	}
+	return value
+}
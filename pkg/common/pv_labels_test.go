@@ -0,0 +1,118 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceLabelsForPV(t *testing.T) {
+	oldGlob, oldEvalSymlinks := internal.FilePathGlob, internal.FilePathEvalSymLinks
+	defer func() {
+		internal.FilePathGlob, internal.FilePathEvalSymLinks = oldGlob, oldEvalSymlinks
+	}()
+	links := map[string]string{
+		internal.DiskByPathDir + "pci-0000:3b:00.0-sas-phy10-lun-0": "/dev/sda",
+		internal.DiskByPathDir + "pci-0000:3b:00.0-sas-phy2-lun-0":  "/dev/sda",
+		internal.DiskByPathDir + "pci-0000:5e:00.0-nvme-1":          "/dev/nvme0n1",
+	}
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		matches := make([]string, 0)
+		for link := range links {
+			if ok, _ := filepath.Match(pattern, link); ok {
+				matches = append(matches, link)
+			}
+		}
+		return matches, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		return links[path], nil
+	}
+
+	sda := internal.BlockDevice{
+		Name:       "sda",
+		KName:      "sda",
+		Rotational: "1",
+		Transport:  "SAS",
+		Vendor:     "SEAGATE ",
+		Model:      "ST16000NM004J",
+		Size:       "16000900661248",
+	}
+	nvme := internal.BlockDevice{
+		Name:       "nvme0n1",
+		KName:      "nvme0n1",
+		Rotational: "0",
+		Transport:  "nvme",
+		Model:      "SAMSUNG MZQL23T8HCLS-00A07",
+		Size:       "3840755982336",
+	}
+	// lsblk reports no transport, vendor or size for this one
+	unknown := internal.BlockDevice{Name: "vdb", KName: "vdb", Rotational: "x"}
+
+	testcases := []struct {
+		label    string
+		policy   *localv1.PVLabelPolicy
+		dev      internal.BlockDevice
+		expected map[string]string
+	}{
+		{
+			label:    "no policy",
+			dev:      sda,
+			expected: map[string]string{},
+		},
+		{
+			label:  "all attributes",
+			policy: &localv1.PVLabelPolicy{},
+			dev:    sda,
+			expected: map[string]string{
+				PVDeviceRotationalLabel: "true",
+				PVDeviceTransportLabel:  "sas",
+				PVDeviceVendorLabel:     "SEAGATE",
+				PVDeviceModelLabel:      "ST16000NM004J",
+				PVDeviceSizeBucketLabel: "16Ti",
+				PVDeviceSlotLabel:       "pci-0000_3b_00.0-sas-phy2-lun-0",
+			},
+		},
+		{
+			label:  "selected attributes",
+			policy: &localv1.PVLabelPolicy{Attributes: []localv1.PVLabelAttribute{localv1.PVLabelRotational, localv1.PVLabelModel, localv1.PVLabelSizeBucket}},
+			dev:    nvme,
+			expected: map[string]string{
+				PVDeviceRotationalLabel: "false",
+				PVDeviceModelLabel:      "SAMSUNG_MZQL23T8HCLS-00A07",
+				PVDeviceSizeBucketLabel: "4Ti",
+			},
+		},
+		{
+			label:    "unknown attributes are left out",
+			policy:   &localv1.PVLabelPolicy{},
+			dev:      unknown,
+			expected: map[string]string{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DeviceLabelsForPV(tc.policy, tc.dev))
+		})
+	}
+}
+
+func TestSizeBucket(t *testing.T) {
+	assert.Equal(t, "1Gi", sizeBucket(1))
+	assert.Equal(t, "1Gi", sizeBucket(GiB))
+	assert.Equal(t, "2Gi", sizeBucket(GiB+1))
+	assert.Equal(t, "1Ti", sizeBucket(960*1000*1000*1000))
+	assert.Equal(t, "4Ti", sizeBucket(3840*1000*1000*1000))
+}
+
+func TestNormalizeLabelValue(t *testing.T) {
+	assert.Equal(t, "INTEL_SSDPE2KX040T8", normalizeLabelValue(" INTEL SSDPE2KX040T8 "))
+	assert.Equal(t, "pci-0000_3b_00.0-nvme-1", normalizeLabelValue("pci-0000:3b:00.0-nvme-1"))
+	assert.Equal(t, "a_b", normalizeLabelValue("(a/b)"))
+	assert.Equal(t, "", normalizeLabelValue("--"))
+	long := normalizeLabelValue("pci-0000:00:17.0-ata-1.0-scsi-0:0:0:0-very-long-name-of-an-enclosure-slot")
+	assert.Len(t, long, 63)
+}
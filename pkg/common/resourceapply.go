@@ -2,6 +2,8 @@ package common
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 	storagev1 "k8s.io/api/storage/v1"
@@ -9,11 +11,21 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	storageclientv1 "k8s.io/client-go/kubernetes/typed/storage/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 )
 
+// ErrStorageClassRecreateRequired is returned by ApplyStorageClass when the StorageClass has to be recreated
+// to apply its reclaim policy or volume binding mode, but recreating it is not allowed
+var ErrStorageClassRecreateRequired = errors.New("StorageClass has to be recreated, set allowRecreate in its storageClassTemplate")
+
 // ApplyStorageClass applies the given StorageClass to the cluster if it does not already exist or is different
 // Returns the StorageClass as it exists in the cluster, whether it was created or updated, and any error that occurred.
-func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClassesGetter, required *storagev1.StorageClass) (*storagev1.StorageClass, bool, error) {
+// The reclaim policy and volume binding mode of a StorageClass can't be updated. When they differ, the StorageClass
+// is recreated if allowRecreate is set, otherwise only the other fields are updated and ErrStorageClassRecreateRequired
+// is returned.
+func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClassesGetter, required *storagev1.StorageClass, allowRecreate bool) (*storagev1.StorageClass, bool, error) {
 	existing, err := client.StorageClasses().Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
 		actual, err := client.StorageClasses().Create(ctx, required, metav1.CreateOptions{})
@@ -23,7 +35,19 @@ func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClasse
 		return nil, false, err
 	}
 
+	var immutableErr error
+	if !equality.Semantic.DeepEqual(existing.ReclaimPolicy, required.ReclaimPolicy) ||
+		!equality.Semantic.DeepEqual(existing.VolumeBindingMode, required.VolumeBindingMode) {
+		if allowRecreate {
+			return recreateStorageClass(ctx, client, existing, required)
+		}
+		immutableErr = fmt.Errorf("%w: StorageClass %s has reclaimPolicy %s and volumeBindingMode %s instead of %s and %s",
+			ErrStorageClassRecreateRequired, existing.Name, ptr.Deref(existing.ReclaimPolicy, ""), ptr.Deref(existing.VolumeBindingMode, ""),
+			ptr.Deref(required.ReclaimPolicy, ""), ptr.Deref(required.VolumeBindingMode, ""))
+	}
+
 	changed := false
+	RemoveStaleTemplateMetadata(&changed, &existing.ObjectMeta, required.ObjectMeta)
 	resourcemerge.EnsureObjectMeta(&changed, &existing.ObjectMeta, required.ObjectMeta)
 
 	if !equality.Semantic.DeepEqual(required.MountOptions, existing.MountOptions) {
@@ -37,8 +61,35 @@ func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClasse
 	}
 
 	if !changed {
-		return existing, false, nil
+		return existing, false, immutableErr
 	}
 	actual, err := client.StorageClasses().Update(ctx, existing, metav1.UpdateOptions{})
-	return actual, true, err
+	if err != nil {
+		return actual, true, err
+	}
+	return actual, true, immutableErr
+}
+
+// recreateStorageClass replaces existing with required. The create is retried right away,
+// because claims that use the StorageClass can't be bound until it exists again.
+func recreateStorageClass(ctx context.Context, client storageclientv1.StorageClassesGetter, existing, required *storagev1.StorageClass) (*storagev1.StorageClass, bool, error) {
+	klog.InfoS("recreating StorageClass to update immutable fields", "storageClass", required.Name)
+	err := client.StorageClasses().Delete(ctx, existing.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &existing.UID},
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, false, err
+	}
+	var actual *storagev1.StorageClass
+	err = retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		return !apierrors.IsInvalid(err) && !apierrors.IsAlreadyExists(err)
+	}, func() error {
+		var err error
+		actual, err = client.StorageClasses().Create(ctx, required, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, true, fmt.Errorf("StorageClass %s was deleted to be recreated, but creating it failed: %w", required.Name, err)
+	}
+	return actual, true, nil
 }
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IsDefaultStorageClassAnnotation marks the default StorageClass of the cluster
+	IsDefaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// TemplateLabelsAnnotation lists the keys of the labels a StorageClassTemplate added to a StorageClass,
+	// so that they are removed from the StorageClass once they are removed from the template
+	TemplateLabelsAnnotation = "local.storage.openshift.io/template-labels"
+	// TemplateAnnotationsAnnotation lists the keys of the annotations a StorageClassTemplate added to a StorageClass
+	TemplateAnnotationsAnnotation = "local.storage.openshift.io/template-annotations"
+)
+
+// ApplyStorageClassTemplate customizes the StorageClass generated for an owner with template.
+// Labels already set on sc, i.e. the owner labels, are not overwritten by the labels of template.
+// The keys of the labels and annotations added by template are recorded in TemplateLabelsAnnotation
+// and TemplateAnnotationsAnnotation, see RemoveStaleTemplateMetadata.
+func ApplyStorageClassTemplate(sc *storagev1.StorageClass, template *localv1.StorageClassTemplate) {
+	if template == nil {
+		return
+	}
+	if template.ReclaimPolicy != nil {
+		reclaimPolicy := *template.ReclaimPolicy
+		sc.ReclaimPolicy = &reclaimPolicy
+	}
+	if template.VolumeBindingMode != nil {
+		volumeBindingMode := *template.VolumeBindingMode
+		sc.VolumeBindingMode = &volumeBindingMode
+	}
+	if len(template.MountOptions) > 0 {
+		sc.MountOptions = append([]string{}, template.MountOptions...)
+	}
+	labelKeys := make([]string, 0, len(template.Labels))
+	for key, value := range template.Labels {
+		if sc.Labels == nil {
+			sc.Labels = map[string]string{}
+		}
+		if _, found := sc.Labels[key]; !found {
+			sc.Labels[key] = value
+			labelKeys = append(labelKeys, key)
+		}
+	}
+	annotations := make(map[string]string, len(template.Annotations)+1)
+	for key, value := range template.Annotations {
+		annotations[key] = value
+	}
+	// the annotation is removed again once the flag is cleared, which ends being the default
+	if template.IsDefaultClass {
+		annotations[IsDefaultStorageClassAnnotation] = "true"
+	}
+	annotationKeys := make([]string, 0, len(annotations))
+	for key, value := range annotations {
+		if sc.Annotations == nil {
+			sc.Annotations = map[string]string{}
+		}
+		sc.Annotations[key] = value
+		annotationKeys = append(annotationKeys, key)
+	}
+	if len(labelKeys) > 0 {
+		sort.Strings(labelKeys)
+		metav1.SetMetaDataAnnotation(&sc.ObjectMeta, TemplateLabelsAnnotation, strings.Join(labelKeys, ","))
+	}
+	if len(annotationKeys) > 0 {
+		sort.Strings(annotationKeys)
+		metav1.SetMetaDataAnnotation(&sc.ObjectMeta, TemplateAnnotationsAnnotation, strings.Join(annotationKeys, ","))
+	}
+}
+
+// RemoveStaleTemplateMetadata removes the labels and annotations that a StorageClassTemplate added to
+// existing, according to its TemplateLabelsAnnotation and TemplateAnnotationsAnnotation, but that are not
+// in required anymore. Other labels and annotations of existing are kept.
+func RemoveStaleTemplateMetadata(modified *bool, existing *metav1.ObjectMeta, required metav1.ObjectMeta) {
+	removeStale := func(values map[string]string, requiredValues map[string]string, keys string) {
+		if keys == "" {
+			return
+		}
+		for _, key := range strings.Split(keys, ",") {
+			if _, found := requiredValues[key]; found {
+				continue
+			}
+			if _, found := values[key]; found {
+				delete(values, key)
+				*modified = true
+			}
+		}
+	}
+	removeStale(existing.Labels, required.Labels, existing.Annotations[TemplateLabelsAnnotation])
+	removeStale(existing.Annotations, required.Annotations, existing.Annotations[TemplateAnnotationsAnnotation])
+	removeStale(existing.Annotations, required.Annotations, TemplateLabelsAnnotation+","+TemplateAnnotationsAnnotation)
+}
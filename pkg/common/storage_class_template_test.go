@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func generatedStorageClass() *storagev1.StorageClass {
+	reclaimPolicy := corev1.PersistentVolumeReclaimDelete
+	volumeBindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	return &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "local-sc",
+			Labels: map[string]string{OwnerNameLabel: "lvset"},
+		},
+		Provisioner:       "kubernetes.io/no-provisioner",
+		ReclaimPolicy:     &reclaimPolicy,
+		VolumeBindingMode: &volumeBindingMode,
+	}
+}
+
+func TestApplyStorageClassTemplate(t *testing.T) {
+	sc := generatedStorageClass()
+	ApplyStorageClassTemplate(sc, nil)
+	assert.Equal(t, generatedStorageClass(), sc)
+
+	retain := corev1.PersistentVolumeReclaimRetain
+	immediate := storagev1.VolumeBindingImmediate
+	ApplyStorageClassTemplate(sc, &localv1.StorageClassTemplate{
+		ReclaimPolicy:     &retain,
+		VolumeBindingMode: &immediate,
+		MountOptions:      []string{"noatime"},
+		Labels:            map[string]string{OwnerNameLabel: "other", "tier": "fast"},
+		Annotations:       map[string]string{"team": "storage"},
+		IsDefaultClass:    true,
+	})
+	assert.Equal(t, retain, *sc.ReclaimPolicy)
+	assert.Equal(t, immediate, *sc.VolumeBindingMode)
+	assert.Equal(t, []string{"noatime"}, sc.MountOptions)
+	assert.Equal(t, map[string]string{OwnerNameLabel: "lvset", "tier": "fast"}, sc.Labels)
+	assert.Equal(t, map[string]string{
+		"team":                          "storage",
+		IsDefaultStorageClassAnnotation: "true",
+		TemplateLabelsAnnotation:        "tier",
+		TemplateAnnotationsAnnotation:   "storageclass.kubernetes.io/is-default-class,team",
+	}, sc.Annotations)
+
+	// nothing is written that the template does not specify
+	sc = generatedStorageClass()
+	ApplyStorageClassTemplate(sc, &localv1.StorageClassTemplate{})
+	assert.Equal(t, generatedStorageClass(), sc)
+}
+
+func TestApplyStorageClassDrift(t *testing.T) {
+	ctx := context.TODO()
+	existing := generatedStorageClass()
+	existing.UID = "old"
+	existing.MountOptions = []string{"discard"}
+	existing.Annotations = map[string]string{"example.com/set-by-admin": "true"}
+	client := fake.NewSimpleClientset(existing)
+
+	// mutable fields are updated in place
+	required := generatedStorageClass()
+	ApplyStorageClassTemplate(required, &localv1.StorageClassTemplate{
+		MountOptions:   []string{"noatime"},
+		Labels:         map[string]string{"tier": "fast"},
+		Annotations:    map[string]string{"team": "storage"},
+		IsDefaultClass: true,
+	})
+	actual, changed, err := ApplyStorageClass(ctx, client.StorageV1(), required, false)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "old", string(actual.UID))
+	assert.Equal(t, []string{"noatime"}, actual.MountOptions)
+	assert.Equal(t, "fast", actual.Labels["tier"])
+	assert.Equal(t, "true", actual.Annotations[IsDefaultStorageClassAnnotation])
+
+	_, changed, err = ApplyStorageClass(ctx, client.StorageV1(), required, false)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// labels and annotations removed from the template are removed from the StorageClass,
+	// the ones added by others are kept
+	required = generatedStorageClass()
+	ApplyStorageClassTemplate(required, &localv1.StorageClassTemplate{
+		MountOptions: []string{"noatime"},
+		Annotations:  map[string]string{"team": "storage"},
+	})
+	actual, changed, err = ApplyStorageClass(ctx, client.StorageV1(), required, false)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]string{OwnerNameLabel: "lvset"}, actual.Labels)
+	assert.Equal(t, map[string]string{
+		"example.com/set-by-admin":    "true",
+		"team":                        "storage",
+		TemplateAnnotationsAnnotation: "team",
+	}, actual.Annotations)
+
+	// immutable fields are only changed when recreating the StorageClass is allowed,
+	// the other fields are updated anyway
+	retain := corev1.PersistentVolumeReclaimRetain
+	required = generatedStorageClass()
+	ApplyStorageClassTemplate(required, &localv1.StorageClassTemplate{ReclaimPolicy: &retain, MountOptions: []string{"discard"}})
+	_, changed, err = ApplyStorageClass(ctx, client.StorageV1(), required, false)
+	assert.ErrorIs(t, err, ErrStorageClassRecreateRequired)
+	assert.True(t, changed)
+	actual, err = client.StorageV1().StorageClasses().Get(ctx, "local-sc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "old", string(actual.UID))
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, *actual.ReclaimPolicy)
+	assert.Equal(t, []string{"discard"}, actual.MountOptions)
+
+	_, changed, err = ApplyStorageClass(ctx, client.StorageV1(), required, true)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	actual, err = client.StorageV1().StorageClasses().Get(ctx, "local-sc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, retain, *actual.ReclaimPolicy)
+	assert.Equal(t, []string{"discard"}, actual.MountOptions)
+	assert.Equal(t, "lvset", actual.Labels[OwnerNameLabel])
+}
+
+func TestApplyStorageClassRecreateRetries(t *testing.T) {
+	ctx := context.TODO()
+	client := fake.NewSimpleClientset(generatedStorageClass())
+	failures := 2
+	client.PrependReactor("create", "storageclasses", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if failures == 0 {
+			return false, nil, nil
+		}
+		failures--
+		return true, nil, apierrors.NewServiceUnavailable("try again")
+	})
+
+	retain := corev1.PersistentVolumeReclaimRetain
+	required := generatedStorageClass()
+	ApplyStorageClassTemplate(required, &localv1.StorageClassTemplate{ReclaimPolicy: &retain})
+	_, changed, err := ApplyStorageClass(ctx, client.StorageV1(), required, true)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	actual, err := client.StorageV1().StorageClasses().Get(ctx, "local-sc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, retain, *actual.ReclaimPolicy)
+
+	// the error is returned once the retries are exhausted
+	failures = 10
+	required = generatedStorageClass()
+	_, _, err = ApplyStorageClass(ctx, client.StorageV1(), required, true)
+	assert.Error(t, err)
+	assert.True(t, apierrors.IsServiceUnavailable(err))
+}
@@ -18,7 +18,8 @@ import (
 )
 
 // GetSymLinkSourceAndTarget returns
-// `source`: the /dev/disk/by-id path of the device if it exists, /dev/KNAME if it doesn't
+// `source`: the /dev/disk/by-id path of the device if it exists, /dev/KNAME if it doesn't.
+// LVM logical volumes use their /dev/mapper path instead of the /dev/disk/by-id path.
 // `target`: the path in the symlinkdir to symlink to. device-id if it exists, KNAME if it doesn't
 // `idExists`: is set if the device-id exists
 // `err`
@@ -144,7 +145,7 @@ func pvHasLabels(pv *corev1.PersistentVolume, labels map[string]string) bool {
 	return true
 }
 
-type ConditionalSymlinkRemoval func() bool
+type ConditionalSymlinkRemoval func(pv *corev1.PersistentVolume) bool
 
 // CleanupSymlinks processes deleted PersistentVolumes with the
 // storage.openshift.com/lso-symlink-deleter finalizer, removes the symlink,
@@ -156,7 +157,7 @@ func CleanupSymlinks(c client.Client, r *provCommon.RuntimeConfig, ownerLabels m
 	// If shouldDeleteSymlinkFnArg is nil, default to always returning true.
 	var shouldDeleteSymlinkFn ConditionalSymlinkRemoval
 	if shouldDeleteSymlinkFnArg == nil {
-		shouldDeleteSymlinkFn = func() bool { return true }
+		shouldDeleteSymlinkFn = func(*corev1.PersistentVolume) bool { return true }
 	} else {
 		shouldDeleteSymlinkFn = shouldDeleteSymlinkFnArg
 	}
@@ -181,7 +182,7 @@ func CleanupSymlinks(c client.Client, r *provCommon.RuntimeConfig, ownerLabels m
 		if !hasSymlinkFinalizer(pv) {
 			continue
 		}
-		if shouldDeleteSymlinkFn() {
+		if shouldDeleteSymlinkFn(pv) {
 			err := deleteSymlink(pv)
 			if err != nil {
 				return err
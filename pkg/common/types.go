@@ -21,6 +21,19 @@ const (
 	PVDeviceNameLabel = "storage.openshift.com/device-name"
 	// PVDeviceIDLabel is the id of the device
 	PVDeviceIDLabel = "storage.openshift.com/device-id"
+
+	// PVDeviceRotationalLabel stores whether the device is rotational, added by PVLabelPolicy
+	PVDeviceRotationalLabel = "storage.openshift.com/device-rotational"
+	// PVDeviceTransportLabel stores the transport of the device, added by PVLabelPolicy
+	PVDeviceTransportLabel = "storage.openshift.com/device-transport"
+	// PVDeviceVendorLabel stores the vendor of the device, added by PVLabelPolicy
+	PVDeviceVendorLabel = "storage.openshift.com/device-vendor"
+	// PVDeviceModelLabel stores the model of the device, added by PVLabelPolicy
+	PVDeviceModelLabel = "storage.openshift.com/device-model"
+	// PVDeviceSizeBucketLabel stores the size of the device rounded up to a power of two, added by PVLabelPolicy
+	PVDeviceSizeBucketLabel = "storage.openshift.com/device-size-bucket"
+	// PVDeviceSlotLabel stores the by-path slot of the device, added by PVLabelPolicy
+	PVDeviceSlotLabel = "storage.openshift.com/device-slot"
 )
 
 // DeprecatedLabels: these labels were deprecated because the potential values weren't all compatible label values
@@ -29,7 +29,7 @@ const (
 type apiUpdater interface {
 	syncStatus(oldInstance, newInstance *localv1.LocalVolume) error
 	updateLocalVolume(lv *localv1.LocalVolume) error
-	applyStorageClass(ctx context.Context, required *storagev1.StorageClass) (*storagev1.StorageClass, bool, error)
+	applyStorageClass(ctx context.Context, required *storagev1.StorageClass, allowRecreate bool) (*storagev1.StorageClass, bool, error)
 	listStorageClasses(listOptions metav1.ListOptions) (*storagev1.StorageClassList, error)
 	listPersistentVolumes(listOptions metav1.ListOptions) (*corev1.PersistentVolumeList, error)
 	recordEvent(lv *localv1.LocalVolume, eventType, reason, messageFmt string, args ...interface{})
@@ -86,8 +86,8 @@ func (s *sdkAPIUpdater) syncStatus(oldInstance, newInstance *localv1.LocalVolume
 	return nil
 }
 
-func (s *sdkAPIUpdater) applyStorageClass(ctx context.Context, sc *storagev1.StorageClass) (*storagev1.StorageClass, bool, error) {
-	return common.ApplyStorageClass(ctx, s.clientset.StorageV1(), sc)
+func (s *sdkAPIUpdater) applyStorageClass(ctx context.Context, sc *storagev1.StorageClass, allowRecreate bool) (*storagev1.StorageClass, bool, error) {
+	return common.ApplyStorageClass(ctx, s.clientset.StorageV1(), sc, allowRecreate)
 }
 
 func (s *sdkAPIUpdater) listStorageClasses(listOptions metav1.ListOptions) (*storagev1.StorageClassList, error) {
@@ -6,4 +6,5 @@ const (
 	listingPersistentVolumesFailed   = "ListingPersistentVolumeFailed"
 	deletingStorageClassFailed       = "DeletingStorageClassFailed"
 	localVolumeDeletionFailed        = "LocalVolumeDeletionFailed"
+	claimRefConflict                 = "ClaimRefConflict"
 )
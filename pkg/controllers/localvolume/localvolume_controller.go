@@ -19,6 +19,8 @@ package localvolume
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
@@ -57,7 +59,9 @@ const (
 type LocalVolumeReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	Client            client.Client
+	Client client.Client
+	// ClientReader reads objects from the apiserver, it is used for objects that are not cached
+	ClientReader      client.Reader
 	Scheme            *runtime.Scheme
 	apiClient         apiUpdater
 	LvMap             *common.StorageClassOwnerMap
@@ -90,15 +94,17 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		r.LvMap.RegisterStorageClassOwner(storageClassDeviceSet.StorageClassName, request.NamespacedName)
 	}
 
-	err = r.syncLocalVolumeProvider(ctx, localStorageProvider)
+	requeueAfter, err := r.syncLocalVolumeProvider(ctx, localStorageProvider)
 	if err != nil {
 		return ctrl.Result{Requeue: true}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, instance *localv1.LocalVolume) error {
+// syncLocalVolumeProvider syncs the resources of instance and returns when its
+// StorageClasses have to be synced again to update their allowedTopologies
+func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, instance *localv1.LocalVolume) (time.Duration, error) {
 	var err error
 	// Create a copy so as we don't modify original LocalVolume
 	o := instance.DeepCopy()
@@ -107,13 +113,13 @@ func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, ins
 	o.SetDefaults()
 
 	if isDeletionCandidate(o, localVolumeFinalizer) {
-		return r.cleanupLocalVolumeDeployment(ctx, o)
+		return 0, r.cleanupLocalVolumeDeployment(ctx, o)
 	}
 
 	// Lets add a finalizer to the LocalVolume object first
 	o, modified := addFinalizer(o)
 	if modified {
-		return r.apiClient.updateLocalVolume(o)
+		return 0, r.apiClient.updateLocalVolume(o)
 	}
 
 	if o.Spec.ManagementState != operatorv1.Managed && o.Spec.ManagementState != operatorv1.Force {
@@ -121,15 +127,15 @@ func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, ins
 		o.Status.State = o.Spec.ManagementState
 		err = r.apiClient.syncStatus(instance, o)
 		if err != nil {
-			return fmt.Errorf("error syncing status: %v", err)
+			return 0, fmt.Errorf("error syncing status: %v", err)
 		}
-		return nil
+		return 0, nil
 	}
 
-	err = r.syncStorageClass(ctx, o)
+	requeueAfter, err := r.syncStorageClass(ctx, o)
 	if err != nil {
 		klog.ErrorS(err, "failed to create storageClass")
-		return r.addFailureCondition(instance, o, err)
+		return 0, r.addFailureCondition(instance, o, err)
 	}
 
 	children := []operatorv1.GenerationStatus{}
@@ -139,7 +145,7 @@ func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, ins
 	err = r.Client.Get(ctx, key, diskMakerDS)
 	if err != nil {
 		klog.ErrorS(err, "failed to fetch diskmaker daemonset")
-		return r.addFailureCondition(instance, o, err)
+		return 0, r.addFailureCondition(instance, o, err)
 	}
 
 	if diskMakerDS != nil {
@@ -153,15 +159,26 @@ func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, ins
 	}
 
 	o.Status.Generations = children
+	o.Status.ClaimRefConflicts, err = common.LocalVolumeClaimRefConflicts(ctx, r.Client, r.ClientReader, o)
+	if err != nil {
+		klog.ErrorS(err, "failed to check claimRefs")
+		return 0, r.addFailureCondition(instance, o, err)
+	}
+	for _, conflict := range o.Status.ClaimRefConflicts {
+		if !slices.ContainsFunc(instance.Status.ClaimRefConflicts, func(c localv1.ClaimRefConflict) bool { return c.ClaimRef == conflict.ClaimRef }) {
+			msg := fmt.Sprintf("claimRef %s is rejected: %s", conflict.ClaimRef, conflict.Message)
+			r.apiClient.recordEvent(o, corev1.EventTypeWarning, claimRefConflict, msg)
+		}
+	}
 	o.Status.State = operatorv1.Managed
 	o = r.addSuccessCondition(o)
 	o.Status.ObservedGeneration = &o.Generation
 	err = r.apiClient.syncStatus(instance, o)
 	if err != nil {
 		klog.ErrorS(err, "error syncing status")
-		return fmt.Errorf("error syncing status: %v", err)
+		return 0, fmt.Errorf("error syncing status: %v", err)
 	}
-	return nil
+	return requeueAfter, nil
 }
 
 func (r *LocalVolumeReconciler) addFailureCondition(oldLv *localv1.LocalVolume, lv *localv1.LocalVolume, err error) error {
@@ -243,19 +260,45 @@ func (r *LocalVolumeReconciler) cleanupLocalVolumeDeployment(ctx context.Context
 	return r.apiClient.updateLocalVolume(lv)
 }
 
-func (r *LocalVolumeReconciler) syncStorageClass(ctx context.Context, cr *localv1.LocalVolume) error {
+// syncStorageClass applies the StorageClasses of cr and returns when they have to be synced
+// again to update their allowedTopologies
+func (r *LocalVolumeReconciler) syncStorageClass(ctx context.Context, cr *localv1.LocalVolume) (time.Duration, error) {
 	storageClassDevices := cr.Spec.StorageClassDevices
 	expectedStorageClasses := sets.NewString()
+	var pvs *corev1.PersistentVolumeList
+	var requeueAfter time.Duration
 	for _, storageClassDevice := range storageClassDevices {
 		storageClassName := storageClassDevice.StorageClassName
 		expectedStorageClasses.Insert(storageClassName)
-		storageClass, err := generateStorageClass(cr, storageClassName)
+		storageClass, err := generateStorageClass(cr, storageClassDevice)
 		if err != nil {
-			return fmt.Errorf("error generating storageClass %s: %v", storageClassName, err)
+			return 0, fmt.Errorf("error generating storageClass %s: %v", storageClassName, err)
+		}
+		if template := storageClassDevice.StorageClassTemplate; template != nil && template.PopulateAllowedTopologies {
+			var existing *storagev1.StorageClass
+			existingStorageClass := &storagev1.StorageClass{}
+			err = r.Client.Get(ctx, types.NamespacedName{Name: storageClassName}, existingStorageClass)
+			if err == nil {
+				existing = existingStorageClass
+			} else if !errors.IsNotFound(err) {
+				return 0, fmt.Errorf("error getting storageClass %s: %v", storageClassName, err)
+			}
+			if pvs == nil {
+				// PVs of the StorageClass can also be created for other owners, list them all once
+				pvs = &corev1.PersistentVolumeList{}
+				if err = r.Client.List(ctx, pvs); err != nil {
+					return 0, fmt.Errorf("error listing persistent volumes: %v", err)
+				}
+			}
+			scRequeueAfter := common.SyncAllowedTopologies(storageClass, existing, template, pvs.Items, time.Now())
+			if scRequeueAfter > 0 && (requeueAfter == 0 || scRequeueAfter < requeueAfter) {
+				requeueAfter = scRequeueAfter
+			}
 		}
-		_, _, err = r.apiClient.applyStorageClass(ctx, storageClass)
+		allowRecreate := storageClassDevice.StorageClassTemplate != nil && storageClassDevice.StorageClassTemplate.AllowRecreate
+		_, _, err = r.apiClient.applyStorageClass(ctx, storageClass, allowRecreate)
 		if err != nil {
-			return fmt.Errorf("error creating storageClass %s: %v", storageClassName, err)
+			return 0, fmt.Errorf("error creating storageClass %s: %v", storageClassName, err)
 		}
 	}
 	removeErrors := r.removeUnExpectedStorageClasses(ctx, cr, expectedStorageClasses)
@@ -263,7 +306,7 @@ func (r *LocalVolumeReconciler) syncStorageClass(ctx context.Context, cr *localv
 	if removeErrors != nil {
 		klog.ErrorS(removeErrors, "error removing unexpected storageclasses")
 	}
-	return nil
+	return requeueAfter, nil
 }
 
 func (r *LocalVolumeReconciler) removeUnExpectedStorageClasses(ctx context.Context, cr *localv1.LocalVolume, expectedStorageClasses sets.String) error {
@@ -338,11 +381,11 @@ func addOwnerLabels(meta *metav1.ObjectMeta, cr *localv1.LocalVolume) bool {
 	return changed
 }
 
-func generateStorageClass(cr *localv1.LocalVolume, scName string) (*storagev1.StorageClass, error) {
+func generateStorageClass(cr *localv1.LocalVolume, storageClassDevice localv1.StorageClassDevice) (*storagev1.StorageClass, error) {
 	scBytes, err := assets.ReadFileAndReplace(
 		common.LocalVolumeStorageClassTemplate,
 		[]string{
-			"${OBJECT_NAME}", scName,
+			"${OBJECT_NAME}", storageClassDevice.StorageClassName,
 		},
 	)
 	if err != nil {
@@ -351,6 +394,7 @@ func generateStorageClass(cr *localv1.LocalVolume, scName string) (*storagev1.St
 	sc := resourceread.ReadStorageClassV1OrDie(scBytes)
 
 	addOwnerLabels(&sc.ObjectMeta, cr)
+	common.ApplyStorageClassTemplate(sc, storageClassDevice.StorageClassTemplate)
 	return sc, nil
 }
 
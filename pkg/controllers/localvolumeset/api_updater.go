@@ -20,7 +20,7 @@ const (
 )
 
 type apiUpdater interface {
-	applyStorageClass(ctx context.Context, required *storagev1.StorageClass) (*storagev1.StorageClass, bool, error)
+	applyStorageClass(ctx context.Context, required *storagev1.StorageClass, allowRecreate bool) (*storagev1.StorageClass, bool, error)
 	listStorageClasses(listOptions metav1.ListOptions) (*storagev1.StorageClassList, error)
 }
 
@@ -48,8 +48,8 @@ func newAPIUpdater(mgr manager.Manager) apiUpdater {
 
 var _ apiUpdater = &sdkAPIUpdater{}
 
-func (s *sdkAPIUpdater) applyStorageClass(ctx context.Context, sc *storagev1.StorageClass) (*storagev1.StorageClass, bool, error) {
-	return common.ApplyStorageClass(ctx, s.clientset.StorageV1(), sc)
+func (s *sdkAPIUpdater) applyStorageClass(ctx context.Context, sc *storagev1.StorageClass, allowRecreate bool) (*storagev1.StorageClass, bool, error) {
+	return common.ApplyStorageClass(ctx, s.clientset.StorageV1(), sc, allowRecreate)
 }
 
 func (s *sdkAPIUpdater) listStorageClasses(listOptions metav1.ListOptions) (*storagev1.StorageClassList, error) {
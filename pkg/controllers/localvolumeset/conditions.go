@@ -8,6 +8,12 @@ import (
 const (
 	// DaemonSetsAvailable
 	DaemonSetsAvailableAndConfigured = "DaemonSetsAvailable"
+	// DeviceSelectorValid reports whether spec.deviceSelector.expression compiles
+	DeviceSelectorValid = "DeviceSelectorValid"
+	// IdentifierPatternsValid reports whether the identifier patterns of the device specs compile
+	IdentifierPatternsValid = "IdentifierPatternsValid"
+	// ProvisioningPolicyRelaxed reports whether spec.provisioningPolicy relaxes any device filter
+	ProvisioningPolicyRelaxed = "ProvisioningPolicyRelaxed"
 )
 
 // SetCondition creates or updates a condition of type conditionType in conditions and returns changed
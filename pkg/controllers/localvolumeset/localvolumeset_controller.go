@@ -19,6 +19,7 @@ package localvolumeset
 import (
 	"context"
 	"fmt"
+	"time"
 
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/pkg/common"
@@ -30,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -84,7 +86,9 @@ func (r *LocalVolumeSetReconciler) reconcile(ctx context.Context, request reconc
 	}
 
 	// store a one to many association from storageClass to LocalVolumeSet
-	r.LvSetMap.RegisterStorageClassOwner(lvSet.Spec.StorageClassName, request.NamespacedName)
+	for _, storageClassName := range common.LocalVolumeSetStorageClassNames(lvSet) {
+		r.LvSetMap.RegisterStorageClassOwner(storageClassName, request.NamespacedName)
+	}
 
 	// handle the LocalVolumeSet finalizer
 	err = r.syncFinalizer(lvSet)
@@ -95,7 +99,7 @@ func (r *LocalVolumeSetReconciler) reconcile(ctx context.Context, request reconc
 	// The diskmaker daemonset, local-staic-provisioner daemonset and configmap are created in pkg/daemon
 	// this way, there can be one daemonset for all LocalVolumeSets
 
-	err = r.syncStorageClass(ctx, lvSet)
+	requeueAfter, err := r.syncStorageClass(ctx, lvSet)
 	if err != nil {
 		klog.ErrorS(err, "failed to sync storageclass")
 		return ctrl.Result{}, err
@@ -114,26 +118,88 @@ func (r *LocalVolumeSetReconciler) reconcile(ctx context.Context, request reconc
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *LocalVolumeSetReconciler) syncStorageClass(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet) error {
+// syncStorageClass applies the StorageClasses of lvs and returns when they have to be synced again
+// to update their allowedTopologies
+func (r *LocalVolumeSetReconciler) syncStorageClass(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet) (time.Duration, error) {
+	storageClassNames := common.LocalVolumeSetStorageClassNames(lvs)
 
 	// remove storageClass if the finalizer is removed
 	if !controllerutil.ContainsFinalizer(lvs, common.LocalVolumeProtectionFinalizer) {
-		removeError := r.removeStorageClass(ctx, lvs)
-		if removeError != nil {
-			klog.ErrorS(removeError, "error removing storageClass", "scName", lvs.Spec.StorageClassName)
+		for _, storageClassName := range storageClassNames {
+			removeError := r.removeStorageClass(ctx, lvs, storageClassName)
+			if removeError != nil {
+				klog.ErrorS(removeError, "error removing storageClass", "scName", storageClassName)
+			}
 		}
 		// do not block on storageClass removal
-		return nil
+		return 0, nil
+	}
+
+	var requeueAfter time.Duration
+	for _, storageClassName := range storageClassNames {
+		scRequeueAfter, err := r.applyStorageClass(ctx, lvs, storageClassName)
+		if err != nil {
+			return 0, err
+		}
+		if scRequeueAfter > 0 && (requeueAfter == 0 || scRequeueAfter < requeueAfter) {
+			requeueAfter = scRequeueAfter
+		}
+	}
+	if err := r.removeStaleStorageClasses(ctx, lvs, storageClassNames); err != nil {
+		return 0, err
+	}
+	return requeueAfter, nil
+}
+
+// removeStaleStorageClasses removes the StorageClasses owned by lvs that are not in storageClassNames anymore,
+// i.e. the ones of removed classes, once no PersistentVolumes use them
+func (r *LocalVolumeSetReconciler) removeStaleStorageClasses(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet, storageClassNames []string) error {
+	storageClasses := &storagev1.StorageClassList{}
+	err := r.Client.List(ctx, storageClasses, client.MatchingLabels{
+		common.OwnerNameLabel:      lvs.GetName(),
+		common.OwnerNamespaceLabel: lvs.GetNamespace(),
+		common.OwnerKindLabel:      localv1alpha1.LocalVolumeSetKind,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing StorageClasses of LocalVolumeSet %s: %w", lvs.Name, err)
 	}
+	owner := types.NamespacedName{Namespace: lvs.GetNamespace(), Name: lvs.GetName()}
+	current := sets.New(storageClassNames...)
+	for _, storageClass := range storageClasses.Items {
+		if current.Has(storageClass.Name) {
+			continue
+		}
+		pvs := &corev1.PersistentVolumeList{}
+		err := r.Client.List(ctx, pvs, client.MatchingFields{pvStorageClassField: storageClass.Name})
+		if err != nil {
+			return fmt.Errorf("error listing PersistentVolumes of StorageClass %s: %w", storageClass.Name, err)
+		}
+		if len(pvs.Items) > 0 {
+			// keep reconciling on changes of the PersistentVolumes until the last one is gone
+			r.LvSetMap.RegisterStorageClassOwner(storageClass.Name, owner)
+			klog.InfoS("keeping StorageClass of removed class until its PersistentVolumes are deleted",
+				"storageClassName", storageClass.Name, "persistentVolumes", len(pvs.Items))
+			continue
+		}
+		if err := r.removeStorageClass(ctx, lvs, storageClass.Name); err != nil {
+			return err
+		}
+		r.LvSetMap.DeregisterStorageClassOwner(storageClass.Name, owner)
+	}
+	return nil
+}
 
+// applyStorageClass applies the StorageClass storageClassName of lvs and returns when it has to be
+// synced again to update its allowedTopologies
+func (r *LocalVolumeSetReconciler) applyStorageClass(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet, storageClassName string) (time.Duration, error) {
 	deleteReclaimPolicy := corev1.PersistentVolumeReclaimDelete
 	firstConsumerBinding := storagev1.VolumeBindingWaitForFirstConsumer
 	storageClass := &storagev1.StorageClass{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: lvs.Spec.StorageClassName,
+			Name: storageClassName,
 			Labels: map[string]string{
 				common.OwnerNameLabel:      lvs.GetName(),
 				common.OwnerNamespaceLabel: lvs.GetNamespace(),
@@ -144,10 +210,37 @@ func (r *LocalVolumeSetReconciler) syncStorageClass(ctx context.Context, lvs *lo
 		ReclaimPolicy:     &deleteReclaimPolicy,
 		VolumeBindingMode: &firstConsumerBinding,
 	}
-	if _, _, err := r.apiClient.applyStorageClass(ctx, storageClass); err != nil {
-		return fmt.Errorf("error syncing StorageClass %s: %w", storageClass.Name, err)
+	template := lvs.Spec.StorageClassTemplate
+	if template != nil && template.IsDefaultClass && storageClassName != lvs.Spec.StorageClassName {
+		// only one StorageClass can be the default, the ones of the classes never are
+		template = template.DeepCopy()
+		template.IsDefaultClass = false
 	}
-	return nil
+	common.ApplyStorageClassTemplate(storageClass, template)
+
+	var requeueAfter time.Duration
+	if template != nil && template.PopulateAllowedTopologies {
+		var existing *storagev1.StorageClass
+		existingStorageClass := &storagev1.StorageClass{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: storageClass.Name}, existingStorageClass)
+		if err == nil {
+			existing = existingStorageClass
+		} else if !kerrors.IsNotFound(err) {
+			return 0, fmt.Errorf("error getting StorageClass %s: %w", storageClass.Name, err)
+		}
+		pvs := &corev1.PersistentVolumeList{}
+		err = r.Client.List(ctx, pvs, client.MatchingFields{pvStorageClassField: storageClass.Name})
+		if err != nil {
+			return 0, fmt.Errorf("error listing PersistentVolumes of StorageClass %s: %w", storageClass.Name, err)
+		}
+		requeueAfter = common.SyncAllowedTopologies(storageClass, existing, template, pvs.Items, time.Now())
+	}
+
+	allowRecreate := template != nil && template.AllowRecreate
+	if _, _, err := r.apiClient.applyStorageClass(ctx, storageClass, allowRecreate); err != nil {
+		return 0, fmt.Errorf("error syncing StorageClass %s: %w", storageClass.Name, err)
+	}
+	return requeueAfter, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -216,33 +309,33 @@ func (r *LocalVolumeSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-// removeStorageClass removes the storageClass associated with the LocalVolumeSet
-func (r *LocalVolumeSetReconciler) removeStorageClass(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet) error {
-	klog.InfoS("removing storageClass", "storageClassName", lvs.Spec.StorageClassName)
+// removeStorageClass removes the storageClass storageClassName associated with the LocalVolumeSet
+func (r *LocalVolumeSetReconciler) removeStorageClass(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet, storageClassName string) error {
+	klog.InfoS("removing storageClass", "storageClassName", storageClassName)
 
 	// Fetch the storageClass
 	lvsStorageClass := &storagev1.StorageClass{}
-	err := r.Client.Get(ctx, client.ObjectKey{Name: lvs.Spec.StorageClassName}, lvsStorageClass)
+	err := r.Client.Get(ctx, client.ObjectKey{Name: storageClassName}, lvsStorageClass)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
-			klog.InfoS("storageClass not found, skipping deletion", "storageClassName", lvs.Spec.StorageClassName)
+			klog.InfoS("storageClass not found, skipping deletion", "storageClassName", storageClassName)
 			return nil
 		}
-		return fmt.Errorf("failed to get storageClass %q for LocalVolumeSet %q: %w", lvs.Spec.StorageClassName, lvs.Name, err)
+		return fmt.Errorf("failed to get storageClass %q for LocalVolumeSet %q: %w", storageClassName, lvs.Name, err)
 	}
 
 	// Check whether storageClass is owned by the LocalVolumeSet
 	if !isStorageClassOwnedByLocalVolumeSet(lvsStorageClass, lvs) {
-		klog.InfoS("storageClass does not have matching owner labels, skipping deletion", "storageClassName", lvs.Spec.StorageClassName)
+		klog.InfoS("storageClass does not have matching owner labels, skipping deletion", "storageClassName", storageClassName)
 		return nil
 	}
 
 	// Delete the storageClass
 	if err := r.Client.Delete(ctx, lvsStorageClass); err != nil && !kerrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete storageClass %q: %w", lvs.Spec.StorageClassName, err)
+		return fmt.Errorf("failed to delete storageClass %q: %w", storageClassName, err)
 	}
 
-	klog.InfoS("Successfully deleted storageClass", "storageClassName", lvs.Spec.StorageClassName)
+	klog.InfoS("Successfully deleted storageClass", "storageClassName", storageClassName)
 	return nil
 }
 
@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"testing"
 
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/pkg/common"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	fakeClient "k8s.io/client-go/kubernetes/fake"
@@ -88,3 +92,85 @@ func (w *testClientWrapper) Get(ctx context.Context, key client.ObjectKey, obj c
 func (w *testClientWrapper) Watch(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
 	return w.Client.(client.WithWatch).Watch(ctx, obj, opts...)
 }
+
+func TestRemoveStaleStorageClasses(t *testing.T) {
+	lvs := &localv1alpha1.LocalVolumeSet{
+		TypeMeta:   metav1.TypeMeta{Kind: localv1alpha1.LocalVolumeSetKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "lvs", Namespace: testNamespace, Finalizers: []string{common.LocalVolumeProtectionFinalizer}},
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "default-sc",
+			Classes:          []localv1alpha1.LocalVolumeSetClass{{StorageClassName: "nvme-fast"}},
+		},
+	}
+	storageClass := func(name, owner string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					common.OwnerNameLabel:      owner,
+					common.OwnerNamespaceLabel: testNamespace,
+					common.OwnerKindLabel:      localv1alpha1.LocalVolumeSetKind,
+				},
+			},
+			Provisioner: "kubernetes.io/no-provisioner",
+		}
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-hdd"},
+		Spec:       corev1.PersistentVolumeSpec{StorageClassName: "hdd-bulk"},
+	}
+	r := newFakeLocalVolumeSetReconciler(t, lvs, pv,
+		storageClass("default-sc", "lvs"),
+		storageClass("nvme-fast", "lvs"),
+		// classes that were removed from lvs
+		storageClass("hdd-bulk", "lvs"),
+		storageClass("ssd-old", "lvs"),
+		// owned by another LocalVolumeSet
+		storageClass("other-sc", "other"),
+	)
+	owner := types.NamespacedName{Namespace: testNamespace, Name: "lvs"}
+	r.LvSetMap.RegisterStorageClassOwner("ssd-old", owner)
+
+	_, err := r.syncStorageClass(context.TODO(), lvs)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"default-sc", "nvme-fast", "hdd-bulk", "other-sc"} {
+		assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name}, &storagev1.StorageClass{}), name)
+	}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "ssd-old"}, &storagev1.StorageClass{})
+	assert.True(t, kerrors.IsNotFound(err))
+	assert.Empty(t, r.LvSetMap.GetStorageClassOwners("ssd-old"))
+	// the PV of the removed class still reconciles lvs
+	assert.Equal(t, []types.NamespacedName{owner}, r.LvSetMap.GetStorageClassOwners("hdd-bulk"))
+}
+
+func TestDefaultStorageClassWithClasses(t *testing.T) {
+	lvs := &localv1alpha1.LocalVolumeSet{
+		TypeMeta:   metav1.TypeMeta{Kind: localv1alpha1.LocalVolumeSetKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "lvs", Namespace: testNamespace, Finalizers: []string{common.LocalVolumeProtectionFinalizer}},
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "default-sc",
+			Classes: []localv1alpha1.LocalVolumeSetClass{
+				{StorageClassName: "nvme-fast"},
+				{StorageClassName: "hdd-bulk"},
+			},
+			StorageClassTemplate: &localv1.StorageClassTemplate{
+				Labels:         map[string]string{"tier": "local"},
+				IsDefaultClass: true,
+			},
+		},
+	}
+	r := newFakeLocalVolumeSetReconciler(t, lvs)
+	_, err := r.syncStorageClass(context.TODO(), lvs)
+	assert.NoError(t, err)
+
+	clientset := r.apiClient.(*sdkAPIUpdater).clientset
+	for _, name := range []string{"default-sc", "nvme-fast", "hdd-bulk"} {
+		storageClass, err := clientset.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err, name)
+		// the rest of the template applies to every class
+		assert.Equal(t, "local", storageClass.Labels["tier"], name)
+		_, isDefault := storageClass.Annotations[common.IsDefaultStorageClassAnnotation]
+		assert.Equal(t, name == "default-sc", isDefault, name)
+	}
+}
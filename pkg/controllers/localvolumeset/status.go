@@ -3,12 +3,15 @@ package localvolumeset
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
 	"github.com/openshift/local-storage-operator/pkg/controllers/nodedaemon"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -65,6 +68,52 @@ func (r *LocalVolumeSetReconciler) updateDaemonSetsCondition(ctx context.Context
 	return nil
 }
 
+// setDeviceSelectorCondition reports whether the device selector expression of lvSet compiles,
+// and returns whether the conditions changed
+func setDeviceSelectorCondition(lvSet *localv1alpha1.LocalVolumeSet) bool {
+	conditionStatus := operatorv1.ConditionTrue
+	conditionMessage := "No device selector expression configured."
+	program, err := common.CompileDeviceSelector(lvSet.Spec.DeviceSelector)
+	if err != nil {
+		conditionStatus = operatorv1.ConditionFalse
+		conditionMessage = err.Error()
+	} else if program != nil {
+		conditionMessage = "Device selector expression compiled successfully."
+	}
+	return SetCondition(&lvSet.Status.Conditions, DeviceSelectorValid, conditionMessage, conditionStatus)
+}
+
+// setIdentifierPatternsCondition reports whether the identifier patterns of lvSet compile,
+// and returns whether the conditions changed
+func setIdentifierPatternsCondition(lvSet *localv1alpha1.LocalVolumeSet) bool {
+	conditionStatus := operatorv1.ConditionTrue
+	conditionMessage := "Identifier patterns compiled successfully."
+	if err := common.CompileIdentifierPatterns(lvSet); err != nil {
+		conditionStatus = operatorv1.ConditionFalse
+		conditionMessage = err.Error()
+	}
+	return SetCondition(&lvSet.Status.Conditions, IdentifierPatternsValid, conditionMessage, conditionStatus)
+}
+
+// setProvisioningPolicyStatus reports the device filters relaxed by the provisioning policy of lvSet,
+// and returns whether the status changed
+func setProvisioningPolicyStatus(lvSet *localv1alpha1.LocalVolumeSet) bool {
+	relaxations := common.ProvisioningPolicyRelaxations(lvSet.Spec.ProvisioningPolicy)
+	conditionStatus := operatorv1.ConditionFalse
+	conditionMessage := "All device filters are enforced."
+	if len(relaxations) > 0 {
+		conditionStatus = operatorv1.ConditionTrue
+		conditionMessage = fmt.Sprintf("Relaxed device filters: %s.", strings.Join(relaxations, "; "))
+	}
+
+	changed := SetCondition(&lvSet.Status.Conditions, ProvisioningPolicyRelaxed, conditionMessage, conditionStatus)
+	if !equality.Semantic.DeepEqual(lvSet.Status.ActiveRelaxations, relaxations) {
+		lvSet.Status.ActiveRelaxations = relaxations
+		changed = true
+	}
+	return changed
+}
+
 func (r *LocalVolumeSetReconciler) updateTotalProvisionedDeviceCountStatus(ctx context.Context, request reconcile.Request) error {
 
 	lvSet := &localv1alpha1.LocalVolumeSet{}
@@ -77,16 +126,23 @@ func (r *LocalVolumeSetReconciler) updateTotalProvisionedDeviceCountStatus(ctx c
 		return fmt.Errorf("failed to get localvolumeset: %w", err)
 	}
 
-	// fetch PVs that match the storageclass
-	pvs := &corev1.PersistentVolumeList{}
-	err = r.Client.List(ctx, pvs, client.MatchingFields{pvStorageClassField: lvSet.Spec.StorageClassName})
-	if err != nil {
-		return fmt.Errorf("failed to list persistent volumes: %w", err)
+	// fetch PVs that match the storageclasses
+	totalPVCount := int32(0)
+	for _, storageClassName := range common.LocalVolumeSetStorageClassNames(lvSet) {
+		pvs := &corev1.PersistentVolumeList{}
+		err = r.Client.List(ctx, pvs, client.MatchingFields{pvStorageClassField: storageClassName})
+		if err != nil {
+			return fmt.Errorf("failed to list persistent volumes: %w", err)
+		}
+		totalPVCount += int32(len(pvs.Items))
 	}
 
-	totalPVCount := int32(len(pvs.Items))
 	lvSet.Status.TotalProvisionedDeviceCount = &totalPVCount
 	lvSet.Status.ObservedGeneration = lvSet.Generation
+	// the conditions derived from the spec are written with the same update
+	setDeviceSelectorCondition(lvSet)
+	setIdentifierPatternsCondition(lvSet)
+	setProvisioningPolicyStatus(lvSet)
 	err = r.Client.Status().Update(ctx, lvSet)
 	if err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
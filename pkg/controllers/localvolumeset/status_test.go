@@ -10,6 +10,7 @@ import (
 	"github.com/openshift/local-storage-operator/pkg/controllers/nodedaemon"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -101,3 +102,167 @@ func TestDaemonSetCondition(t *testing.T) {
 
 	}
 }
+
+func TestDeviceSelectorCondition(t *testing.T) {
+	testTable := []struct {
+		name           string
+		selector       *localv1alpha1.DeviceSelector
+		expectedStatus operatorv1.ConditionStatus
+	}{
+		{name: "no selector", selector: nil, expectedStatus: operatorv1.ConditionTrue},
+		{name: "valid expression", selector: &localv1alpha1.DeviceSelector{Expression: `device.size > quantity("1Ti")`}, expectedStatus: operatorv1.ConditionTrue},
+		{name: "invalid expression", selector: &localv1alpha1.DeviceSelector{Expression: `device.size >`}, expectedStatus: operatorv1.ConditionFalse},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			lvset := &localv1alpha1.LocalVolumeSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "selector", Namespace: testNamespace},
+				Spec:       localv1alpha1.LocalVolumeSetSpec{DeviceSelector: tc.selector},
+			}
+			fakeReconciler := newFakeLocalVolumeSetReconciler(t, lvset)
+			lvsetKey := types.NamespacedName{Name: lvset.GetName(), Namespace: lvset.GetNamespace()}
+
+			err := fakeReconciler.updateTotalProvisionedDeviceCountStatus(context.TODO(), reconcile.Request{NamespacedName: lvsetKey})
+			assert.NoErrorf(t, err, "updateTotalProvisionedDeviceCountStatus")
+
+			reconciledLVSet := &localv1alpha1.LocalVolumeSet{}
+			err = fakeReconciler.Client.Get(context.TODO(), lvsetKey, reconciledLVSet)
+			assert.NoErrorf(t, err, "get lvset from fake client")
+
+			conditionFound := false
+			for _, condition := range reconciledLVSet.Status.Conditions {
+				if condition.Type == DeviceSelectorValid {
+					conditionFound = true
+					assert.Equal(t, tc.expectedStatus, condition.Status)
+				}
+			}
+			assert.True(t, conditionFound, "condition should be set")
+		})
+	}
+}
+
+func TestIdentifierPatternsCondition(t *testing.T) {
+	testTable := []struct {
+		name           string
+		serials        []string
+		expectedStatus operatorv1.ConditionStatus
+	}{
+		{name: "no patterns", serials: nil, expectedStatus: operatorv1.ConditionTrue},
+		{name: "valid patterns", serials: []string{"S3EV*", "regex:^Z1X[0-9]{5}$"}, expectedStatus: operatorv1.ConditionTrue},
+		{name: "invalid regex", serials: []string{"regex:("}, expectedStatus: operatorv1.ConditionFalse},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			lvset := &localv1alpha1.LocalVolumeSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "patterns", Namespace: testNamespace},
+				Spec: localv1alpha1.LocalVolumeSetSpec{
+					DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{
+						DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: tc.serials},
+					},
+				},
+			}
+			fakeReconciler := newFakeLocalVolumeSetReconciler(t, lvset)
+			lvsetKey := types.NamespacedName{Name: lvset.GetName(), Namespace: lvset.GetNamespace()}
+
+			err := fakeReconciler.updateTotalProvisionedDeviceCountStatus(context.TODO(), reconcile.Request{NamespacedName: lvsetKey})
+			assert.NoErrorf(t, err, "updateTotalProvisionedDeviceCountStatus")
+
+			reconciledLVSet := &localv1alpha1.LocalVolumeSet{}
+			err = fakeReconciler.Client.Get(context.TODO(), lvsetKey, reconciledLVSet)
+			assert.NoErrorf(t, err, "get lvset from fake client")
+
+			conditionFound := false
+			for _, condition := range reconciledLVSet.Status.Conditions {
+				if condition.Type == IdentifierPatternsValid {
+					conditionFound = true
+					assert.Equal(t, tc.expectedStatus, condition.Status)
+				}
+			}
+			assert.True(t, conditionFound, "condition should be set")
+		})
+	}
+}
+
+func TestProvisioningPolicyStatus(t *testing.T) {
+	testTable := []struct {
+		name                string
+		policy              *localv1alpha1.ProvisioningPolicy
+		expectedStatus      operatorv1.ConditionStatus
+		expectedRelaxations int
+	}{
+		{name: "no policy", policy: nil, expectedStatus: operatorv1.ConditionFalse},
+		{name: "empty policy", policy: &localv1alpha1.ProvisioningPolicy{}, expectedStatus: operatorv1.ConditionFalse},
+		{
+			name:                "removable devices",
+			policy:              &localv1alpha1.ProvisioningPolicy{AllowRemovableDevices: true},
+			expectedStatus:      operatorv1.ConditionTrue,
+			expectedRelaxations: 1,
+		},
+		{
+			name:                "removable devices and filesystems",
+			policy:              &localv1alpha1.ProvisioningPolicy{AllowRemovableDevices: true, AllowedFilesystemTypes: []string{"xfs"}},
+			expectedStatus:      operatorv1.ConditionTrue,
+			expectedRelaxations: 2,
+		},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			lvset := &localv1alpha1.LocalVolumeSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: testNamespace},
+				Spec:       localv1alpha1.LocalVolumeSetSpec{ProvisioningPolicy: tc.policy},
+			}
+			fakeReconciler := newFakeLocalVolumeSetReconciler(t, lvset)
+			lvsetKey := types.NamespacedName{Name: lvset.GetName(), Namespace: lvset.GetNamespace()}
+
+			err := fakeReconciler.updateTotalProvisionedDeviceCountStatus(context.TODO(), reconcile.Request{NamespacedName: lvsetKey})
+			assert.NoErrorf(t, err, "updateTotalProvisionedDeviceCountStatus")
+
+			reconciledLVSet := &localv1alpha1.LocalVolumeSet{}
+			err = fakeReconciler.Client.Get(context.TODO(), lvsetKey, reconciledLVSet)
+			assert.NoErrorf(t, err, "get lvset from fake client")
+
+			assert.Len(t, reconciledLVSet.Status.ActiveRelaxations, tc.expectedRelaxations)
+			conditionFound := false
+			for _, condition := range reconciledLVSet.Status.Conditions {
+				if condition.Type == ProvisioningPolicyRelaxed {
+					conditionFound = true
+					assert.Equal(t, tc.expectedStatus, condition.Status)
+				}
+			}
+			assert.True(t, conditionFound, "condition should be set")
+		})
+	}
+}
+
+func TestTotalProvisionedDeviceCountStatusWithClasses(t *testing.T) {
+	lvset := &localv1alpha1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "classes", Namespace: testNamespace},
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "default-sc",
+			Classes: []localv1alpha1.LocalVolumeSetClass{
+				{StorageClassName: "nvme-fast"},
+				{StorageClassName: "hdd-bulk"},
+			},
+		},
+	}
+	pv := func(name, storageClassName string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PersistentVolumeSpec{StorageClassName: storageClassName},
+		}
+	}
+	fakeReconciler := newFakeLocalVolumeSetReconciler(t, lvset,
+		pv("pv-1", "nvme-fast"), pv("pv-2", "nvme-fast"), pv("pv-3", "hdd-bulk"), pv("pv-4", "default-sc"), pv("pv-5", "other-sc"))
+	lvsetKey := types.NamespacedName{Name: lvset.GetName(), Namespace: lvset.GetNamespace()}
+
+	err := fakeReconciler.updateTotalProvisionedDeviceCountStatus(context.TODO(), reconcile.Request{NamespacedName: lvsetKey})
+	assert.NoErrorf(t, err, "updateTotalProvisionedDeviceCountStatus")
+
+	reconciledLVSet := &localv1alpha1.LocalVolumeSet{}
+	err = fakeReconciler.Client.Get(context.TODO(), lvsetKey, reconciledLVSet)
+	assert.NoErrorf(t, err, "get lvset from fake client")
+	assert.Equal(t, int32(4), *reconciledLVSet.Status.TotalProvisionedDeviceCount)
+}
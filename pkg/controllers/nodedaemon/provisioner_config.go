@@ -46,15 +46,17 @@ func (r *DaemonReconciler) reconcileProvisionerConfigMap(
 	// config data
 	storageClassConfig := make(map[string]localStaticProvisioner.MountConfig)
 	for _, lvSet := range lvSets {
-		storageClassName := lvSet.Spec.StorageClassName
-		symlinkDir := path.Join(common.GetLocalDiskLocationPath(), storageClassName)
-		mountConfig := localStaticProvisioner.MountConfig{
-			FsType:     lvSet.Spec.FSType,
-			HostDir:    symlinkDir,
-			MountDir:   symlinkDir,
-			VolumeMode: string(lvSet.Spec.VolumeMode),
+		for _, class := range common.LocalVolumeSetClasses(&lvSet) {
+			storageClassName := class.Spec.StorageClassName
+			symlinkDir := path.Join(common.GetLocalDiskLocationPath(), storageClassName)
+			mountConfig := localStaticProvisioner.MountConfig{
+				FsType:     class.Spec.FSType,
+				HostDir:    symlinkDir,
+				MountDir:   symlinkDir,
+				VolumeMode: string(class.Spec.VolumeMode),
+			}
+			storageClassConfig[storageClassName] = mountConfig
 		}
-		storageClassConfig[storageClassName] = mountConfig
 	}
 	for _, lv := range lvs {
 		for _, devices := range lv.Spec.StorageClassDevices {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -580,3 +581,327 @@ func TestSyncPVAndLVDL_DeviceLinkLifecycle(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncPVAndLVDLDeviceOverrides(t *testing.T) {
+	reclaimPolicyDelete := corev1.PersistentVolumeReclaimDelete
+	lv := &localv1.LocalVolume{
+		TypeMeta: metav1.TypeMeta{Kind: localv1.LocalVolumeKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lv-overrides",
+			Namespace: "openshift-local-storage",
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-overrides",
+			Labels: map[string]string{corev1.LabelHostname: "node-hostname-overrides"},
+		},
+	}
+	sc := &storagev1.StorageClass{
+		ObjectMeta:    metav1.ObjectMeta{Name: "storageclass-overrides"},
+		ReclaimPolicy: &reclaimPolicyDelete,
+	}
+	symlinkPath := "/mnt/local-storage/storageclass-overrides/device-overrides"
+
+	r, testConfig := getFakeDiskMaker(t, "/mnt/local-storage", lv, node, sc)
+	r.localVolume = lv
+	testConfig.runtimeConfig.Node = node
+	testConfig.runtimeConfig.Name = common.GetProvisionedByValue(*node)
+	testConfig.runtimeConfig.DiscoveryMap[sc.Name] = provCommon.MountConfig{
+		VolumeMode: string(localv1.PersistentVolumeBlock),
+		FsType:     "ext4",
+	}
+	testConfig.fakeVolUtil.AddNewDirEntries("/mnt/local-storage/", map[string][]*provUtil.FakeDirEntry{
+		sc.Name: {{Name: "device-overrides", Capacity: 10 * common.GiB, VolumeType: provUtil.FakeEntryBlock}},
+	})
+	oldReadLink := internal.Readlink
+	defer func() {
+		internal.Readlink = oldReadLink
+	}()
+	internal.Readlink = func(symlinkPath string) (string, error) {
+		return "/dev/disk/by-id/wwn-overrides", nil
+	}
+
+	diskLocation := &internal.DiskLocation{
+		SymlinkPath: symlinkPath,
+		BlockDevice: internal.BlockDevice{KName: "device-overrides"},
+		FSType:      "xfs",
+		VolumeMode:  string(localv1.PersistentVolumeFilesystem),
+		PVLabels: map[string]string{
+			"example.com/tier":                    "recycled",
+			common.LocalVolumeOwnerNameForPV:      "not-the-owner",
+			common.LocalVolumeOwnerNamespaceForPV: "not-the-namespace",
+		},
+		PVAnnotations: map[string]string{
+			"example.com/rma":        "1234",
+			common.PVDeviceNameLabel: "not-the-device",
+		},
+	}
+	err := r.syncPVAndLVDL(t.Context(), sc.Name, diskLocation, sets.New[string]())
+	assert.NoError(t, err)
+
+	pv := &corev1.PersistentVolume{}
+	err = r.Client.Get(t.Context(), types.NamespacedName{Name: common.GeneratePVName(filepath.Base(symlinkPath), node.Name, sc.Name)}, pv)
+	assert.NoError(t, err)
+	assert.Equal(t, "recycled", pv.Labels["example.com/tier"])
+	assert.Equal(t, lv.Name, pv.Labels[common.LocalVolumeOwnerNameForPV])
+	assert.Equal(t, lv.Namespace, pv.Labels[common.LocalVolumeOwnerNamespaceForPV])
+	assert.Equal(t, "1234", pv.Annotations["example.com/rma"])
+	assert.Equal(t, "device-overrides", pv.Annotations[common.PVDeviceNameLabel])
+	assert.NotNil(t, pv.Spec.VolumeMode)
+	assert.Equal(t, corev1.PersistentVolumeFilesystem, *pv.Spec.VolumeMode)
+	assert.NotNil(t, pv.Spec.Local.FSType)
+	assert.Equal(t, "xfs", *pv.Spec.Local.FSType)
+}
+
+func TestSyncPVAndLVDLTopology(t *testing.T) {
+	reclaimPolicyDelete := corev1.PersistentVolumeReclaimDelete
+	testCases := []struct {
+		name                 string
+		topology             *localv1.PVTopology
+		expectedLabels       map[string]string
+		expectedRequirements []corev1.NodeSelectorRequirement
+	}{
+		{
+			name: "no topology",
+			expectedRequirements: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-hostname-topology"}},
+			},
+		},
+		{
+			name: "labels only",
+			topology: &localv1.PVTopology{
+				NodeLabels: []string{corev1.LabelTopologyZone, "example.com/rack", "example.com/missing"},
+			},
+			expectedLabels: map[string]string{
+				corev1.LabelTopologyZone: "zone-a",
+				"example.com/rack":       "rack-3",
+			},
+			expectedRequirements: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-hostname-topology"}},
+			},
+		},
+		{
+			name: "labels and node affinity",
+			topology: &localv1.PVTopology{
+				NodeLabels:        []string{"example.com/rack", corev1.LabelHostname, corev1.LabelTopologyZone},
+				AddToNodeAffinity: true,
+			},
+			expectedLabels: map[string]string{
+				corev1.LabelTopologyZone: "zone-a",
+				"example.com/rack":       "rack-3",
+			},
+			expectedRequirements: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-hostname-topology"}},
+				{Key: "example.com/rack", Operator: corev1.NodeSelectorOpIn, Values: []string{"rack-3"}},
+				{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lv := &localv1.LocalVolume{
+				TypeMeta: metav1.TypeMeta{Kind: localv1.LocalVolumeKind},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "lv-topology",
+					Namespace: "openshift-local-storage",
+				},
+				Spec: localv1.LocalVolumeSpec{PVTopology: tc.topology},
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-topology",
+					Labels: map[string]string{
+						corev1.LabelHostname:     "node-hostname-topology",
+						corev1.LabelTopologyZone: "zone-a",
+						"example.com/rack":       "rack-3",
+					},
+				},
+			}
+			sc := &storagev1.StorageClass{
+				ObjectMeta:    metav1.ObjectMeta{Name: "storageclass-topology"},
+				ReclaimPolicy: &reclaimPolicyDelete,
+			}
+			symlinkPath := "/mnt/local-storage/storageclass-topology/device-topology"
+
+			r, testConfig := getFakeDiskMaker(t, "/mnt/local-storage", lv, node, sc)
+			r.localVolume = lv
+			testConfig.runtimeConfig.Node = node
+			testConfig.runtimeConfig.Name = common.GetProvisionedByValue(*node)
+			testConfig.runtimeConfig.DiscoveryMap[sc.Name] = provCommon.MountConfig{VolumeMode: string(localv1.PersistentVolumeBlock)}
+			testConfig.fakeVolUtil.AddNewDirEntries("/mnt/local-storage/", map[string][]*provUtil.FakeDirEntry{
+				sc.Name: {{Name: "device-topology", Capacity: 10 * common.GiB, VolumeType: provUtil.FakeEntryBlock}},
+			})
+			oldReadLink := internal.Readlink
+			defer func() {
+				internal.Readlink = oldReadLink
+			}()
+			internal.Readlink = func(symlinkPath string) (string, error) {
+				return "/dev/disk/by-id/wwn-topology", nil
+			}
+
+			diskLocation := &internal.DiskLocation{
+				SymlinkPath: symlinkPath,
+				BlockDevice: internal.BlockDevice{KName: "device-topology"},
+			}
+			err := r.syncPVAndLVDL(t.Context(), sc.Name, diskLocation, sets.New[string]())
+			assert.NoError(t, err)
+
+			pv := &corev1.PersistentVolume{}
+			err = r.Client.Get(t.Context(), types.NamespacedName{Name: common.GeneratePVName(filepath.Base(symlinkPath), node.Name, sc.Name)}, pv)
+			assert.NoError(t, err)
+			for key, value := range tc.expectedLabels {
+				assert.Equal(t, value, pv.Labels[key], key)
+			}
+			assert.NotContains(t, pv.Labels, "example.com/missing")
+			if tc.expectedLabels == nil {
+				assert.NotContains(t, pv.Labels, corev1.LabelTopologyZone)
+			}
+			assert.Equal(t, tc.expectedRequirements, pv.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions)
+		})
+	}
+}
+
+func TestSyncPVAndLVDLClaimRef(t *testing.T) {
+	reclaimPolicyDelete := corev1.PersistentVolumeReclaimDelete
+	claimRef := &localv1.PVClaimRef{Namespace: "db", Name: "data-db-0"}
+	testCases := []struct {
+		name             string
+		claimRef         *localv1.PVClaimRef
+		existingClaimRef *corev1.ObjectReference
+		existingPreBound string
+		existingPhase    corev1.PersistentVolumePhase
+		expectedClaimRef *corev1.ObjectReference
+		expectedPreBound string
+		expectEvent      bool
+	}{
+		{
+			name: "no claimRef",
+		},
+		{
+			name:             "new PV is pre-bound",
+			claimRef:         claimRef,
+			expectedClaimRef: common.PVClaimReference(*claimRef),
+			expectedPreBound: "db/data-db-0",
+		},
+		{
+			name:             "available PV is pre-bound",
+			claimRef:         claimRef,
+			existingPhase:    corev1.VolumeAvailable,
+			expectedClaimRef: common.PVClaimReference(*claimRef),
+			expectedPreBound: "db/data-db-0",
+		},
+		{
+			name:             "pre-binding is removed with the claimRef",
+			existingClaimRef: common.PVClaimReference(*claimRef),
+			existingPreBound: "db/data-db-0",
+			existingPhase:    corev1.VolumeAvailable,
+		},
+		{
+			name:             "pre-binding follows a changed claimRef",
+			claimRef:         &localv1.PVClaimRef{Namespace: "db", Name: "data-db-1"},
+			existingClaimRef: common.PVClaimReference(*claimRef),
+			existingPreBound: "db/data-db-0",
+			existingPhase:    corev1.VolumeAvailable,
+			expectedClaimRef: common.PVClaimReference(localv1.PVClaimRef{Namespace: "db", Name: "data-db-1"}),
+			expectedPreBound: "db/data-db-1",
+		},
+		{
+			name:             "bound PV keeps its claim when the claimRef is removed",
+			existingClaimRef: &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "db", Name: "data-db-0", UID: "uid"},
+			existingPreBound: "db/data-db-0",
+			existingPhase:    corev1.VolumeBound,
+			expectedClaimRef: &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "db", Name: "data-db-0", UID: "uid"},
+			expectedPreBound: "db/data-db-0",
+		},
+		{
+			name:             "PV pre-bound by someone else is kept when there is no claimRef",
+			existingClaimRef: common.PVClaimReference(*claimRef),
+			existingPhase:    corev1.VolumeAvailable,
+			expectedClaimRef: common.PVClaimReference(*claimRef),
+		},
+		{
+			name:             "PV claimed by another claim is kept",
+			claimRef:         claimRef,
+			existingClaimRef: &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "db", Name: "other"},
+			existingPhase:    corev1.VolumeBound,
+			expectedClaimRef: &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "db", Name: "other"},
+			expectEvent:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lv := &localv1.LocalVolume{
+				TypeMeta: metav1.TypeMeta{Kind: localv1.LocalVolumeKind},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "lv-claimref",
+					Namespace: "openshift-local-storage",
+				},
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node-claimref",
+					Labels: map[string]string{corev1.LabelHostname: "node-hostname-claimref"},
+				},
+			}
+			sc := &storagev1.StorageClass{
+				ObjectMeta:    metav1.ObjectMeta{Name: "storageclass-claimref"},
+				ReclaimPolicy: &reclaimPolicyDelete,
+			}
+			symlinkPath := "/mnt/local-storage/storageclass-claimref/device-claimref"
+			pvName := common.GeneratePVName(filepath.Base(symlinkPath), node.Name, sc.Name)
+			objs := []runtime.Object{lv, node, sc}
+			if tc.existingPhase != "" {
+				pv := &corev1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{Name: pvName, CreationTimestamp: metav1.Now()},
+					Spec:       corev1.PersistentVolumeSpec{ClaimRef: tc.existingClaimRef},
+					Status:     corev1.PersistentVolumeStatus{Phase: tc.existingPhase},
+				}
+				if tc.existingPreBound != "" {
+					pv.Annotations = map[string]string{common.PreBoundClaimAnnotation: tc.existingPreBound}
+				}
+				objs = append(objs, pv)
+			}
+
+			r, testConfig := getFakeDiskMaker(t, "/mnt/local-storage", objs...)
+			r.localVolume = lv
+			testConfig.runtimeConfig.Node = node
+			testConfig.runtimeConfig.Name = common.GetProvisionedByValue(*node)
+			testConfig.runtimeConfig.DiscoveryMap[sc.Name] = provCommon.MountConfig{VolumeMode: string(localv1.PersistentVolumeBlock)}
+			testConfig.fakeVolUtil.AddNewDirEntries("/mnt/local-storage/", map[string][]*provUtil.FakeDirEntry{
+				sc.Name: {{Name: "device-claimref", Capacity: 10 * common.GiB, VolumeType: provUtil.FakeEntryBlock}},
+			})
+			oldReadLink := internal.Readlink
+			defer func() {
+				internal.Readlink = oldReadLink
+			}()
+			internal.Readlink = func(symlinkPath string) (string, error) {
+				return "/dev/disk/by-id/wwn-claimref", nil
+			}
+
+			diskLocation := &internal.DiskLocation{
+				SymlinkPath: symlinkPath,
+				BlockDevice: internal.BlockDevice{KName: "device-claimref"},
+				ClaimRef:    tc.claimRef,
+			}
+			err := r.syncPVAndLVDL(t.Context(), sc.Name, diskLocation, sets.New[string]())
+			assert.NoError(t, err)
+
+			pv := &corev1.PersistentVolume{}
+			err = r.Client.Get(t.Context(), types.NamespacedName{Name: pvName}, pv)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedClaimRef, pv.Spec.ClaimRef)
+			assert.Equal(t, tc.expectedPreBound, pv.Annotations[common.PreBoundClaimAnnotation])
+
+			foundEvent := false
+			for len(testConfig.fakeRecorder.Events) > 0 {
+				if strings.Contains(<-testConfig.fakeRecorder.Events, common.ClaimRefConflict) {
+					foundEvent = true
+				}
+			}
+			assert.Equal(t, tc.expectEvent, foundEvent)
+		})
+	}
+}
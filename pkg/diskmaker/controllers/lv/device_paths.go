@@ -0,0 +1,167 @@
+package lv
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// nodeSelectorKeyPrefix marks the keys of NodeDevicePaths that are label selectors instead of node names
+const nodeSelectorKeyPrefix = "selector:"
+
+// expandDevicePaths returns the device paths of storageClassDevice that apply to node: its
+// DevicePaths and the paths of its Devices followed by the paths of every NodeDevicePaths entry that matches node, with glob
+// patterns replaced by the paths they match. Paths that point to a device that an earlier path
+// already points to are dropped, unless they were listed literally in DevicePaths or NodeDevicePaths.
+// An invalid pattern or key is reported as error, the remaining paths are still returned.
+func (r *LocalVolumeReconciler) expandDevicePaths(storageClassDevice localv1.StorageClassDevice, node *corev1.Node) ([]string, []error) {
+	devicePaths := slices.Clone(storageClassDevice.DevicePaths)
+	overridePaths := sets.New[string]()
+	for _, override := range storageClassDevice.Devices {
+		devicePaths = append(devicePaths, override.Path)
+		overridePaths.Insert(override.Path)
+	}
+	errs := make([]error, 0)
+	if node != nil {
+		// iterate in a stable order so the same path wins on every reconcile
+		keys := make([]string, 0, len(storageClassDevice.NodeDevicePaths))
+		for key := range storageClassDevice.NodeDevicePaths {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			matches, err := nodeMatchesKey(node, key)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if matches {
+				devicePaths = append(devicePaths, storageClassDevice.NodeDevicePaths[key]...)
+			}
+		}
+	}
+
+	expanded := make([]string, 0, len(devicePaths))
+	seenPaths := sets.New[string]()
+	seenDevices := sets.New[string]()
+	for _, devicePath := range devicePaths {
+		if !isGlobPattern(devicePath) {
+			if seenPaths.Has(devicePath) {
+				continue
+			}
+			if device, err := r.fsInterface.evalSymlink(devicePath); err == nil {
+				// the paths of Devices only add devices that no other path points to
+				if overridePaths.Has(devicePath) && seenDevices.Has(device) {
+					continue
+				}
+				seenDevices.Insert(device)
+			}
+			seenPaths.Insert(devicePath)
+			expanded = append(expanded, devicePath)
+			continue
+		}
+
+		matches, err := internal.FilePathGlob(devicePath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid device path pattern %q: %w", devicePath, err))
+			continue
+		}
+		// filepath.Glob returns the matches in lexical order
+		for _, match := range matches {
+			if seenPaths.Has(match) {
+				continue
+			}
+			device, err := r.fsInterface.evalSymlink(match)
+			if err != nil {
+				klog.V(4).InfoS("skipping device path that can't be resolved", "pattern", devicePath, "path", match, "err", err)
+				continue
+			}
+			if seenDevices.Has(device) {
+				continue
+			}
+			seenPaths.Insert(match)
+			seenDevices.Insert(device)
+			expanded = append(expanded, match)
+		}
+	}
+	return expanded, errs
+}
+
+// deviceOverrides maps each of devicePaths to the first entry of storageClassDevice.Devices whose
+// path points to the same device. Paths that can't be resolved are compared literally.
+func (r *LocalVolumeReconciler) deviceOverrides(storageClassDevice localv1.StorageClassDevice, devicePaths []string) map[string]localv1.DeviceOverride {
+	if len(storageClassDevice.Devices) == 0 {
+		return nil
+	}
+	overridesByPath := map[string]localv1.DeviceOverride{}
+	overridesByDevice := map[string]localv1.DeviceOverride{}
+	for _, override := range storageClassDevice.Devices {
+		paths := []string{override.Path}
+		if isGlobPattern(override.Path) {
+			// invalid patterns are reported by expandDevicePaths
+			paths, _ = internal.FilePathGlob(override.Path)
+		}
+		for _, path := range paths {
+			if _, found := overridesByPath[path]; !found {
+				overridesByPath[path] = override
+			}
+			device, err := r.fsInterface.evalSymlink(path)
+			if err != nil {
+				continue
+			}
+			if _, found := overridesByDevice[device]; !found {
+				overridesByDevice[device] = override
+			}
+		}
+	}
+
+	overrides := map[string]localv1.DeviceOverride{}
+	for _, devicePath := range devicePaths {
+		var override localv1.DeviceOverride
+		var found bool
+		if device, err := r.fsInterface.evalSymlink(devicePath); err == nil {
+			override, found = overridesByDevice[device]
+		} else {
+			override, found = overridesByPath[devicePath]
+		}
+		if found {
+			overrides[devicePath] = override
+		}
+	}
+	return overrides
+}
+
+// nodeMatchesKey checks whether key of NodeDevicePaths is the name of node
+// or a nodeSelectorKeyPrefix label selector that matches the labels of node.
+// Keys that are neither a valid node name nor a non-empty selector are rejected,
+// so that a typo can't silently select no node or the wrong ones.
+func nodeMatchesKey(node *corev1.Node, key string) (bool, error) {
+	if expression, found := strings.CutPrefix(key, nodeSelectorKeyPrefix); found {
+		if strings.TrimSpace(expression) == "" {
+			return false, fmt.Errorf("invalid nodeDevicePaths key %q: empty label selector", key)
+		}
+		selector, err := labels.Parse(expression)
+		if err != nil {
+			return false, fmt.Errorf("invalid nodeDevicePaths key %q: %w", key, err)
+		}
+		return selector.Matches(labels.Set(node.Labels)), nil
+	}
+	if errs := validation.IsDNS1123Subdomain(key); len(errs) > 0 {
+		return false, fmt.Errorf("invalid nodeDevicePaths key %q: not a node name (%s), label selectors need the prefix %q",
+			key, strings.Join(errs, ", "), nodeSelectorKeyPrefix)
+	}
+	return key == node.Name, nil
+}
+
+// isGlobPattern checks whether path contains any of the special characters of filepath.Match
+func isGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
@@ -0,0 +1,231 @@
+package lv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExpandDevicePaths(t *testing.T) {
+	root := t.TempDir()
+	devDir := filepath.Join(root, "dev")
+	byPathDir := filepath.Join(devDir, "disk", "by-path")
+	byIDDir := filepath.Join(devDir, "disk", "by-id")
+	for _, dir := range []string{byPathDir, byIDDir} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	for _, name := range []string{"nvme0n1", "nvme1n1", "sda"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(devDir, name), nil, 0644))
+	}
+	links := map[string]string{
+		filepath.Join(byPathDir, "pci-0000:3b:00.0-nvme-1"):     "nvme0n1",
+		filepath.Join(byPathDir, "pci-0000:3c:00.0-nvme-1"):     "nvme1n1",
+		filepath.Join(byPathDir, "pci-0000:00:17.0-ata-1"):      "sda",
+		filepath.Join(byIDDir, "nvme-eui.0025388b91b2c3d4"):     "nvme0n1",
+		filepath.Join(byIDDir, "nvme-SAMSUNG_MZQL2_S64GNA0R"):   "nvme0n1",
+		filepath.Join(byIDDir, "wwn-0x5000c500a1b2c3d4"):        "sda",
+		filepath.Join(byIDDir, "nvme-eui.0025388b91b2c3e5"):     "nvme1n1",
+		filepath.Join(byIDDir, "nvme-SAMSUNG_MZQL2_S64GNA0S"):   "nvme1n1",
+		filepath.Join(byIDDir, "nvme-SAMSUNG_MZQL2_DANGLING"):   "nvme9n1",
+		filepath.Join(byPathDir, "pci-0000:5e:00.0-nvme-1"):     "nvme9n1",
+		filepath.Join(byPathDir, "pci-0000:00:17.0-ata-1.0"):    "sda",
+		filepath.Join(byPathDir, "pci-0000:00:1f.2-ata-1-part"): "sda1",
+	}
+	for link, target := range links {
+		assert.NoError(t, os.Symlink(filepath.Join(devDir, target), link))
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "worker-1",
+			Labels: map[string]string{"topology.kubernetes.io/zone": "rack-1"},
+		},
+	}
+
+	testcases := []struct {
+		label         string
+		device        localv1.StorageClassDevice
+		node          *corev1.Node
+		expected      []string
+		expectedError bool
+	}{
+		{
+			label: "literal paths are kept",
+			device: localv1.StorageClassDevice{
+				DevicePaths: []string{filepath.Join(devDir, "sda"), filepath.Join(devDir, "missing")},
+			},
+			node:     node,
+			expected: []string{filepath.Join(devDir, "sda"), filepath.Join(devDir, "missing")},
+		},
+		{
+			label: "glob pattern",
+			device: localv1.StorageClassDevice{
+				DevicePaths: []string{filepath.Join(byPathDir, "pci-*-nvme-*")},
+			},
+			node: node,
+			expected: []string{
+				filepath.Join(byPathDir, "pci-0000:3b:00.0-nvme-1"),
+				filepath.Join(byPathDir, "pci-0000:3c:00.0-nvme-1"),
+			},
+		},
+		{
+			label: "one path per device",
+			device: localv1.StorageClassDevice{
+				DevicePaths: []string{filepath.Join(byIDDir, "wwn-0x5000c500a1b2c3d4"), filepath.Join(byIDDir, "*")},
+			},
+			node: node,
+			expected: []string{
+				filepath.Join(byIDDir, "wwn-0x5000c500a1b2c3d4"),
+				filepath.Join(byIDDir, "nvme-SAMSUNG_MZQL2_S64GNA0R"),
+				filepath.Join(byIDDir, "nvme-SAMSUNG_MZQL2_S64GNA0S"),
+			},
+		},
+		{
+			label: "node device paths by name and label selector",
+			device: localv1.StorageClassDevice{
+				DevicePaths: []string{filepath.Join(devDir, "sda")},
+				NodeDevicePaths: map[string][]string{
+					"worker-1": {filepath.Join(byPathDir, "pci-0000:3b:*")},
+					"selector:topology.kubernetes.io/zone=rack-1": {filepath.Join(byPathDir, "pci-0000:3c:*")},
+					"selector:topology.kubernetes.io/zone=rack-2": {filepath.Join(devDir, "nvme9n1")},
+					"worker-2": {filepath.Join(devDir, "nvme9n1")},
+				},
+			},
+			node: node,
+			expected: []string{
+				filepath.Join(devDir, "sda"),
+				filepath.Join(byPathDir, "pci-0000:3c:00.0-nvme-1"),
+				filepath.Join(byPathDir, "pci-0000:3b:00.0-nvme-1"),
+			},
+		},
+		{
+			label: "node device paths without node",
+			device: localv1.StorageClassDevice{
+				NodeDevicePaths: map[string][]string{"worker-1": {filepath.Join(devDir, "sda")}},
+			},
+			expected: []string{},
+		},
+		{
+			label: "invalid key and pattern",
+			device: localv1.StorageClassDevice{
+				DevicePaths: []string{filepath.Join(byPathDir, "pci-[")},
+				NodeDevicePaths: map[string][]string{
+					"selector:zone in (rack-1": {filepath.Join(devDir, "nvme0n1")},
+					"worker-1":                 {filepath.Join(devDir, "nvme1n1")},
+				},
+			},
+			node:          node,
+			expected:      []string{filepath.Join(devDir, "nvme1n1")},
+			expectedError: true,
+		},
+		{
+			label: "selector without prefix",
+			device: localv1.StorageClassDevice{
+				NodeDevicePaths: map[string][]string{
+					"topology.kubernetes.io/zone=rack-1": {filepath.Join(devDir, "nvme0n1")},
+				},
+			},
+			node:          node,
+			expected:      []string{},
+			expectedError: true,
+		},
+		{
+			label: "empty selector",
+			device: localv1.StorageClassDevice{
+				NodeDevicePaths: map[string][]string{
+					"selector:": {filepath.Join(devDir, "nvme0n1")},
+				},
+			},
+			node:          node,
+			expected:      []string{},
+			expectedError: true,
+		},
+	}
+
+	r := &LocalVolumeReconciler{fsInterface: NixFileSystemInterface{}}
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			devicePaths, errs := r.expandDevicePaths(tc.device, tc.node)
+			assert.Equal(t, tc.expected, devicePaths)
+			if tc.expectedError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestDeviceOverrides(t *testing.T) {
+	root := t.TempDir()
+	devDir := filepath.Join(root, "dev")
+	byIDDir := filepath.Join(devDir, "disk", "by-id")
+	assert.NoError(t, os.MkdirAll(byIDDir, 0755))
+	for _, name := range []string{"sda", "sdb", "sdc"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(devDir, name), nil, 0644))
+		assert.NoError(t, os.Symlink(filepath.Join(devDir, name), filepath.Join(byIDDir, "wwn-"+name)))
+	}
+
+	wipe, keep := true, false
+	storageClassDevice := localv1.StorageClassDevice{
+		DevicePaths:                       []string{filepath.Join(devDir, "sda"), filepath.Join(devDir, "sdc")},
+		ForceWipeDevicesAndDestroyAllData: true,
+		Devices: []localv1.DeviceOverride{
+			{Path: filepath.Join(byIDDir, "wwn-sda"), ForceWipe: &keep, PVLabels: map[string]string{"tier": "recycled"}},
+			{Path: filepath.Join(byIDDir, "wwn-sd[ab]"), FSType: "xfs", ForceWipe: &wipe},
+			{Path: filepath.Join(devDir, "missing"), VolumeMode: localv1.PersistentVolumeBlock},
+		},
+	}
+
+	r := &LocalVolumeReconciler{fsInterface: NixFileSystemInterface{}}
+	devicePaths, errs := r.expandDevicePaths(storageClassDevice, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{
+		filepath.Join(devDir, "sda"),
+		filepath.Join(devDir, "sdc"),
+		filepath.Join(byIDDir, "wwn-sdb"),
+		filepath.Join(devDir, "missing"),
+	}, devicePaths)
+
+	disks := &Disks{
+		DevicePaths:                       devicePaths,
+		ForceWipeDevicesAndDestroyAllData: storageClassDevice.ForceWipeDevicesAndDestroyAllData,
+		DeviceOverrides:                   r.deviceOverrides(storageClassDevice, devicePaths),
+	}
+	assert.Len(t, disks.DeviceOverrides, 3)
+
+	testcases := []struct {
+		devicePath string
+		expected   internal.DiskLocation
+	}{
+		{
+			devicePath: filepath.Join(devDir, "sda"),
+			expected:   internal.DiskLocation{ForceWipe: false, PVLabels: map[string]string{"tier": "recycled"}},
+		},
+		{
+			devicePath: filepath.Join(byIDDir, "wwn-sdb"),
+			expected:   internal.DiskLocation{ForceWipe: true, FSType: "xfs"},
+		},
+		{
+			devicePath: filepath.Join(devDir, "sdc"),
+			expected:   internal.DiskLocation{ForceWipe: true},
+		},
+		{
+			devicePath: filepath.Join(devDir, "missing"),
+			expected:   internal.DiskLocation{ForceWipe: true, VolumeMode: "Block"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(filepath.Base(tc.devicePath), func(t *testing.T) {
+			deviceLocation := &internal.DiskLocation{ForceWipe: disks.ForceWipeDevicesAndDestroyAllData}
+			disks.applyDeviceOverride(tc.devicePath, deviceLocation)
+			assert.Equal(t, tc.expected, *deviceLocation)
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/ghodss/yaml"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -12,6 +14,26 @@ import (
 type Disks struct {
 	DevicePaths                       []string `json:"devicePaths,omitempty"`
 	ForceWipeDevicesAndDestroyAllData bool     `json:"forceWipeDevicesAndDestroyAllData,omitempty"`
+	// DeviceOverrides maps device paths to the settings that override those of the storage class
+	DeviceOverrides map[string]localv1.DeviceOverride `json:"deviceOverrides,omitempty"`
+	// Partitioning splits the devices into partitions that are provisioned instead of the devices
+	Partitioning *localv1.Partitioning `json:"partitioning,omitempty"`
+}
+
+// applyDeviceOverride sets the overridden settings of devicePath on deviceLocation
+func (d *Disks) applyDeviceOverride(devicePath string, deviceLocation *internal.DiskLocation) {
+	override, found := d.DeviceOverrides[devicePath]
+	if !found {
+		return
+	}
+	if override.ForceWipe != nil {
+		deviceLocation.ForceWipe = *override.ForceWipe
+	}
+	deviceLocation.FSType = override.FSType
+	deviceLocation.VolumeMode = string(override.VolumeMode)
+	deviceLocation.PVLabels = override.PVLabels
+	deviceLocation.PVAnnotations = override.PVAnnotations
+	deviceLocation.ClaimRef = override.ClaimRef
 }
 
 // DeviceNames returns devices which are used by name.
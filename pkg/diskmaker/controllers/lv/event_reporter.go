@@ -19,6 +19,10 @@ const (
 	ErrorFindingMatchingDisk = "ErrorFindingMatchingDisk"
 	ErrorCreatingSymLink     = "ErrorCreatingSymLink"
 	ErrorProvisioningVolume  = "ErrorProvisioningVolume"
+	// ErrorExpandingDevicePaths is reported when a device path pattern or nodeDevicePaths key is invalid
+	ErrorExpandingDevicePaths = "ErrorExpandingDevicePaths"
+	// ErrorClaimRefConflict is reported when a device is not provisioned because its claimRef is rejected
+	ErrorClaimRefConflict = "ErrorClaimRefConflict"
 
 	FoundMatchingDisk     = "FoundMatchingDisk"
 	DeviceSymlinkExists   = "DeviceSymlinkExists"
@@ -0,0 +1,85 @@
+package lv
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/openshift/local-storage-operator/pkg/diskmaker"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// expandPartitions replaces the device paths of partitioned storage classes in diskConfig by the paths
+// of the partitions the diskmaker created on them. Empty disks among validDevices are partitioned first,
+// their partitions are provisioned once they show up in a later reconcile. Disks that already back a PV
+// are left as they are, disks with a filesystem or partition table are only partitioned if they are force
+// wiped. Device overrides apply to all partitions of a disk, except for the claimRef.
+func (r *LocalVolumeReconciler) expandPartitions(diskConfig *DiskConfig, blockDevices, validDevices []internal.BlockDevice) {
+	partitionsByDisk := map[string][]internal.BlockDevice{}
+	for _, blockDevice := range blockDevices {
+		if blockDevice.IsLSOPartition() && blockDevice.PKName != "" {
+			partitionsByDisk[blockDevice.PKName] = append(partitionsByDisk[blockDevice.PKName], blockDevice)
+		}
+	}
+
+	for storageClass, disks := range diskConfig.Disks {
+		if disks.Partitioning == nil {
+			continue
+		}
+		devicePaths := make([]string, 0, len(disks.DevicePaths))
+		for _, devicePath := range disks.DevicePaths {
+			diskDevPath, err := r.fsInterface.evalSymlink(devicePath)
+			if err != nil {
+				// reported when the path is provisioned
+				devicePaths = append(devicePaths, devicePath)
+				continue
+			}
+			kname := filepath.Base(diskDevPath)
+
+			if partitions, found := partitionsByDisk[kname]; found {
+				override, hasOverride := disks.DeviceOverrides[devicePath]
+				override.ClaimRef = nil
+				for _, partition := range partitions {
+					partitionPath, err := partition.GetDevPath()
+					if err != nil {
+						klog.ErrorS(err, "failed to get path of partition", "device", partition.Name)
+						continue
+					}
+					devicePaths = append(devicePaths, partitionPath)
+					if hasOverride {
+						disks.DeviceOverrides[partitionPath] = override
+					}
+				}
+				continue
+			}
+
+			disk, found := hasExactDisk(validDevices, kname)
+			if !found || !disk.IsPartitionable() {
+				devicePaths = append(devicePaths, devicePath)
+				continue
+			}
+			forceWipe := disks.ForceWipeDevicesAndDestroyAllData
+			if override, found := disks.DeviceOverrides[devicePath]; found && override.ForceWipe != nil {
+				forceWipe = *override.ForceWipe
+			}
+			partitioned, count, size, err := disk.PartitionDisk(*disks.Partitioning, r.symlinkLocation, forceWipe)
+			if err != nil {
+				msg := fmt.Sprintf("failed to partition %s for storageClass %s: %v", devicePath, storageClass, err)
+				r.eventSync.Report(r.localVolume, newDiskEvent(diskmaker.ErrorPartitioningDisk, msg, devicePath, corev1.EventTypeWarning))
+				klog.Error(msg)
+				continue
+			}
+			if !partitioned {
+				// the whole disk already backs a PV, keep it in sync
+				devicePaths = append(devicePaths, devicePath)
+				continue
+			}
+			msg := fmt.Sprintf("created %d partitions of %d bytes on %s", count, size, devicePath)
+			r.eventSync.Report(r.localVolume, newDiskEvent(diskmaker.DiskPartitioned, msg, devicePath, corev1.EventTypeNormal))
+			klog.Info(msg)
+			r.effectiveRequeueTime = fastRequeueTime
+		}
+		disks.DevicePaths = devicePaths
+	}
+}
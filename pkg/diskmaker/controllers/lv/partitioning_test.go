@@ -0,0 +1,51 @@
+package lv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestExpandPartitions(t *testing.T) {
+	devDir := t.TempDir()
+	for _, name := range []string{"sda", "sdb"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(devDir, name), nil, 0644))
+	}
+	sdaPath, sdbPath := filepath.Join(devDir, "sda"), filepath.Join(devDir, "sdb")
+
+	blockDevices := []internal.BlockDevice{
+		{KName: "sda", Type: "disk"},
+		{KName: "sdb", Type: "disk"},
+		{KName: "sdb1", Type: "part", PartLabel: "lso-part-1", PKName: "sdb"},
+		{KName: "sdb2", Type: "part", PartLabel: "lso-part-2", PKName: "sdb"},
+	}
+	diskConfig := &DiskConfig{
+		Disks: map[string]*Disks{
+			"partitioned": {
+				DevicePaths:  []string{sdbPath},
+				Partitioning: &localv1.Partitioning{Count: ptr.To[int32](2)},
+				DeviceOverrides: map[string]localv1.DeviceOverride{
+					sdbPath: {Path: sdbPath, FSType: "xfs", ClaimRef: &localv1.PVClaimRef{Namespace: "default", Name: "data"}},
+				},
+			},
+			"whole": {
+				DevicePaths: []string{sdaPath, sdbPath},
+			},
+		},
+	}
+
+	r := &LocalVolumeReconciler{fsInterface: NixFileSystemInterface{}}
+	r.expandPartitions(diskConfig, blockDevices, blockDevices[2:])
+
+	partitioned := diskConfig.Disks["partitioned"]
+	assert.Equal(t, []string{"/dev/sdb1", "/dev/sdb2"}, partitioned.DevicePaths)
+	for _, partitionPath := range partitioned.DevicePaths {
+		assert.Equal(t, localv1.DeviceOverride{Path: sdbPath, FSType: "xfs"}, partitioned.DeviceOverrides[partitionPath])
+	}
+	assert.Equal(t, []string{sdaPath, sdbPath}, diskConfig.Disks["whole"].DevicePaths)
+}
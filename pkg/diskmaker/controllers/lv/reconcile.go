@@ -12,6 +12,7 @@ import (
 	"time"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/pkg/common"
 	"github.com/openshift/local-storage-operator/pkg/diskmaker"
 	"github.com/openshift/local-storage-operator/pkg/internal"
@@ -77,6 +78,8 @@ type LocalVolumeReconciler struct {
 	firstRunOver   bool
 
 	effectiveRequeueTime time.Duration
+	// claimRefConflicts are the rejected claims of the devices of the LocalVolume, mapped to the reason
+	claimRefConflicts map[localv1.PVClaimRef]string
 }
 
 func (r *LocalVolumeReconciler) createSymlink(
@@ -214,8 +217,16 @@ func (r *LocalVolumeReconciler) generateConfig() *DiskConfig {
 	for _, storageClassDevice := range storageClassDevices {
 		disks := new(Disks)
 		disks.ForceWipeDevicesAndDestroyAllData = storageClassDevice.ForceWipeDevicesAndDestroyAllData
-		if len(storageClassDevice.DevicePaths) > 0 {
-			disks.DevicePaths = storageClassDevice.DevicePaths
+		disks.Partitioning = storageClassDevice.Partitioning
+		devicePaths, errs := r.expandDevicePaths(storageClassDevice, r.runtimeConfig.Node)
+		for _, err := range errs {
+			msg := fmt.Sprintf("error expanding device paths of storageClass %s: %v", storageClassDevice.StorageClassName, err)
+			r.eventSync.Report(r.localVolume, newDiskEvent(ErrorExpandingDevicePaths, msg, "", corev1.EventTypeWarning))
+			klog.Error(msg)
+		}
+		if len(devicePaths) > 0 {
+			disks.DevicePaths = devicePaths
+			disks.DeviceOverrides = r.deviceOverrides(storageClassDevice, devicePaths)
 		}
 		configMapData.Disks[storageClassDevice.StorageClassName] = disks
 	}
@@ -293,7 +304,7 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		common.PVOwnerNameLabel:      lv.Name,
 	}
 	err = common.CleanupSymlinks(r.Client, r.runtimeConfig, ownerLabels,
-		func() bool {
+		func(*corev1.PersistentVolume) bool {
 			// Only delete the symlink if the owner LV is deleted.
 			return !lv.DeletionTimestamp.IsZero()
 		})
@@ -323,6 +334,16 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		klog.ErrorS(err, "error creating local-storage directory", "symLinkLocation", r.symlinkLocation)
 		return ctrl.Result{}, err
 	}
+	// devices whose claim is rejected are not provisioned
+	conflicts, err := common.LocalVolumeClaimRefConflicts(ctx, r.Client, r.ClientReader, lv)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check claimRefs: %w", err)
+	}
+	r.claimRefConflicts = map[localv1.PVClaimRef]string{}
+	for _, conflict := range conflicts {
+		r.claimRefConflicts[conflict.ClaimRef] = conflict.Message
+	}
+
 	diskConfig := r.generateConfig()
 	// run command lsblk --all --noheadings --pairs --output "KNAME,PKNAME,TYPE,MOUNTPOINT"
 	// the reason we are using KNAME instead of NAME is because for lvm disks(and may be others)
@@ -358,10 +379,48 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		klog.Error(msg)
 	}
 
+	// devices the host runs from are never provisioned, don't guess if they can't be determined
+	systemDevices, err := internal.GetSystemDevices()
+	if err != nil {
+		msg := fmt.Sprintf("failed to determine system devices: %v", err)
+		r.eventSync.Report(r.localVolume, newDiskEvent(diskmaker.ErrorListingSystemDevices, msg, "", corev1.EventTypeWarning))
+		klog.Error(msg)
+		return ctrl.Result{}, err
+	}
+
+	// devices listed in a LocalVolumeDeviceBlocklist are never touched
+	blocklist, err := common.LoadDeviceBlocklist(ctx, r.Client)
+	if err != nil {
+		msg := fmt.Sprintf("failed to load device blocklist: %v", err)
+		r.eventSync.Report(r.localVolume, newDiskEvent(diskmaker.ErrorLoadingDeviceBlocklist, msg, "", corev1.EventTypeWarning))
+		klog.Error(msg)
+		return ctrl.Result{}, err
+	}
+
 	validBlockDevices := make([]internal.BlockDevice, 0)
 	ignoredDevices := make([]internal.BlockDevice, 0)
+	blockedDevices := make([]internal.BlockDevice, 0)
+	blockedReasons := make(map[string]string)
 
 	for _, blockDevice := range blockDevices {
+		// system devices are not even probed for existing PVs
+		if systemDevices.Has(blockDevice.KName) {
+			klog.InfoS("ignoring system device", "devName", blockDevice.Name)
+			continue
+		}
+		blocked, reason, err := blocklist.IsBlocked(blockDevice)
+		if err != nil {
+			// can't tell whether the device is blocked, don't touch it
+			klog.ErrorS(err, "failed to check device against blocklist", "devName", blockDevice.Name)
+			blockedDevices = append(blockedDevices, blockDevice)
+			continue
+		}
+		if blocked {
+			klog.V(4).InfoS("ignoring blocked device", "devName", blockDevice.Name, "reason", reason)
+			blockedDevices = append(blockedDevices, blockDevice)
+			blockedReasons[blockDevice.KName] = reason
+			continue
+		}
 		if ignoreDevices(blockDevice) {
 			ignoredDevices = append(ignoredDevices, blockDevice)
 			continue
@@ -369,6 +428,11 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		validBlockDevices = append(validBlockDevices, blockDevice)
 	}
 
+	// from here on the partitions of partitioned disks are provisioned in place of the disks
+	r.expandPartitions(diskConfig, blockDevices, validBlockDevices)
+
+	r.reportBlockedDevices(blockedDevices, blockedReasons, diskConfig)
+
 	var inUsePVCount map[string]int
 	if len(ignoredDevices) > 0 {
 		inUsePVCount = r.processRejectedDevicesForDeviceLinks(ctx, ignoredDevices, diskConfig)
@@ -408,6 +472,10 @@ func (r *LocalVolumeReconciler) processValidDevices(ctx context.Context, validDe
 					r.logDeviceError(devicePath)
 					continue
 				}
+				disks.applyDeviceOverride(devicePath, deviceLocation)
+				if r.hasClaimRefConflict(devicePath, deviceLocation) {
+					continue
+				}
 
 				if r.provisionValidDevice(ctx, storageClass, symLinkDirPath, devicePath, deviceLocation, mountPointMap) {
 					totalProvisionedPVs += 1
@@ -448,6 +516,30 @@ func (r *LocalVolumeReconciler) updateOrphanedSymlinkMetrics(allBlockDevices []i
 	}
 }
 
+// reportBlockedDevices reports the devicePaths of the LocalVolume that resolve to a blocked
+// device and updates the blocked device metric. blockedReasons maps the KNAME of a blocked
+// device to the reason it is blocked, devices without a reason are counted but not reported.
+func (r *LocalVolumeReconciler) reportBlockedDevices(blockedDevices []internal.BlockDevice, blockedReasons map[string]string, diskConfig *DiskConfig) {
+	for storageClass, disks := range diskConfig.Disks {
+		blocked := 0
+		for _, devicePath := range disks.DevicePaths {
+			deviceLocation, matched, err := r.resolveValidDeviceLocation(devicePath, false, blockedDevices)
+			if err != nil || !matched {
+				continue
+			}
+			blocked++
+			reason, found := blockedReasons[deviceLocation.BlockDevice.KName]
+			if !found {
+				continue
+			}
+			msg := fmt.Sprintf("not provisioning %s, %s", devicePath, reason)
+			r.eventSync.Report(r.localVolume, newDiskEvent(diskmaker.DeviceBlocked, msg, devicePath, corev1.EventTypeNormal))
+			klog.Info(msg)
+		}
+		localmetrics.SetBlockedDeviceMetric(nodeName, storageClass, blocked)
+	}
+}
+
 func (r *LocalVolumeReconciler) updateMissingDevicePathMetrics(diskConfig *DiskConfig) {
 	for storageClass, disks := range diskConfig.Disks {
 		missing := 0
@@ -550,6 +642,21 @@ func (r *LocalVolumeReconciler) provisionValidDevice(ctx context.Context, storag
 	return provisioned
 }
 
+// hasClaimRefConflict checks whether the claim of deviceLocation is rejected, and reports it if it is
+func (r *LocalVolumeReconciler) hasClaimRefConflict(devicePath string, deviceLocation *internal.DiskLocation) bool {
+	if deviceLocation.ClaimRef == nil {
+		return false
+	}
+	reason, found := r.claimRefConflicts[*deviceLocation.ClaimRef]
+	if !found {
+		return false
+	}
+	msg := fmt.Sprintf("not provisioning %s, its claimRef %s is rejected: %s", devicePath, deviceLocation.ClaimRef, reason)
+	r.eventSync.Report(r.localVolume, newDiskEvent(ErrorClaimRefConflict, msg, devicePath, corev1.EventTypeWarning))
+	klog.Error(msg)
+	return true
+}
+
 func (r *LocalVolumeReconciler) reportDeviceResolutionError(devicePath string, err error) {
 	deviceKind := "disk"
 	if strings.HasPrefix(devicePath, diskByIDPrefix) {
@@ -644,10 +751,12 @@ func (r *LocalVolumeReconciler) syncPVAndLVDL(ctx context.Context, scName string
 		return fmt.Errorf("error fetching storageclass %s: %w", scName, err)
 	}
 
-	lvOwnerLabels := map[string]string{
-		common.LocalVolumeOwnerNameForPV:      r.localVolume.Name,
-		common.LocalVolumeOwnerNamespaceForPV: r.localVolume.Namespace,
+	pvLabels := common.DeviceLabelsForPV(r.localVolume.Spec.PVLabelPolicy, deviceNameLocation.BlockDevice)
+	for key, value := range deviceNameLocation.PVLabels {
+		pvLabels[key] = value
 	}
+	pvLabels[common.LocalVolumeOwnerNameForPV] = r.localVolume.Name
+	pvLabels[common.LocalVolumeOwnerNamespaceForPV] = r.localVolume.Namespace
 	syncArgs := common.SyncPVAndLVDLArgs{
 		LocalVolumeLikeObject: r.localVolume,
 		RuntimeConfig:         r.runtimeConfig,
@@ -656,9 +765,14 @@ func (r *LocalVolumeReconciler) syncPVAndLVDL(ctx context.Context, scName string
 		Client:                r.Client,
 		ClientReader:          r.ClientReader,
 		SymLinkPath:           deviceNameLocation.SymlinkPath,
-		ExtraLabelsForPV:      lvOwnerLabels,
+		ExtraLabelsForPV:      pvLabels,
 		BlockDevice:           deviceNameLocation.BlockDevice,
 		CacheWriter:           r.pvLinkCache,
+		FSType:                deviceNameLocation.FSType,
+		VolumeMode:            corev1.PersistentVolumeMode(deviceNameLocation.VolumeMode),
+		ExtraAnnotationsForPV: deviceNameLocation.PVAnnotations,
+		PVTopology:            r.localVolume.Spec.PVTopology,
+		ClaimRef:              deviceNameLocation.ClaimRef,
 	}
 
 	return common.SyncPVAndLVDL(ctx, syncArgs)
@@ -824,6 +938,9 @@ func (r *LocalVolumeReconciler) WithManager(mgr ctrl.Manager) error {
 		Watches(
 			&corev1.ConfigMap{},
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &localv1.LocalVolume{})).
+		// reconcile all LocalVolumes when the blocklist changes, this also keeps it in the cache
+		Watches(&localv1alpha1.LocalVolumeDeviceBlocklist{}, common.EnqueueAllForDeviceBlocklist(mgr.GetClient(), watchNamespace,
+			func() client.ObjectList { return &localv1.LocalVolumeList{} })).
 		// update owned-pv cache used by provisioner/deleter libs and enequeue owning lvset
 		// only the cache is touched by
 		Watches(&corev1.PersistentVolume{}, &handler.Funcs{
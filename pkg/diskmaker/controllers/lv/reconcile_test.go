@@ -17,6 +17,7 @@ import (
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/pkg/common"
+	"github.com/openshift/local-storage-operator/pkg/diskmaker"
 	"github.com/openshift/local-storage-operator/pkg/diskmaker/diskmakertest"
 	"github.com/openshift/local-storage-operator/pkg/internal"
 	"github.com/openshift/local-storage-operator/pkg/localmetrics"
@@ -1786,3 +1787,44 @@ func TestUpdateOrphanedSymlinkMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestReportBlockedDevices(t *testing.T) {
+	const testNodeName = "node-blocked-test"
+
+	tmpRoot := diskmakertest.TempDir(t, "blocked-devices")
+	d, tc := getFakeDiskMaker(t, tmpRoot)
+	d.localVolume = &localv1.LocalVolume{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       localv1.LocalVolumeKind,
+			APIVersion: localv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "lv-blocked", Namespace: "default"},
+	}
+	d.fsInterface = stubFileSystemInterface{evalFunc: func(path string) (string, error) {
+		return path, nil
+	}}
+
+	origNodeName := nodeName
+	nodeName = testNodeName
+	t.Cleanup(func() { nodeName = origNodeName })
+
+	diskConfig := &DiskConfig{
+		Disks: map[string]*Disks{
+			storageClassName: {DevicePaths: []string{"/dev/sda", "/dev/sdb", "/dev/sdc"}},
+		},
+	}
+	blockedDevices := []internal.BlockDevice{{Name: "sdb", KName: "sdb"}, {Name: "sdc", KName: "sdc"}}
+	// sdc could not be checked against the blocklist, it is counted but not reported
+	blockedReasons := map[string]string{"sdb": `serial "S2" is listed in LocalVolumeDeviceBlocklist "boot-disks"`}
+
+	d.reportBlockedDevices(blockedDevices, blockedReasons, diskConfig)
+
+	pb := &dto.Metric{}
+	assert.NoError(t, localmetrics.BlockedDeviceGauge(testNodeName, storageClassName).Write(pb))
+	assert.Equal(t, float64(2), pb.GetGauge().GetValue())
+
+	assert.Len(t, tc.fakeRecorder.Events, 1)
+	event := <-tc.fakeRecorder.Events
+	assert.Contains(t, event, diskmaker.DeviceBlocked)
+	assert.Contains(t, event, "/dev/sdb")
+}
@@ -0,0 +1,148 @@
+package lvset
+
+import (
+	"fmt"
+	"sort"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+)
+
+// activateCaches activates the volume groups of all classes of lvset that cache their devices,
+// so that the cached logical volumes show up again after the node was rebooted
+func (r *LocalVolumeSetReconciler) activateCaches(lvset *localv1alpha1.LocalVolumeSet) {
+	for _, class := range common.LocalVolumeSetClasses(lvset) {
+		if class.Spec.Cache == nil {
+			continue
+		}
+		vgName := poolVolumeGroupName(class)
+		vg, err := internal.GetVolumeGroup(vgName)
+		if err != nil {
+			klog.ErrorS(err, "could not get volume group", "volumeGroup", vgName)
+			continue
+		}
+		if vg == nil {
+			// no device was added yet
+			continue
+		}
+		if err := internal.ActivateVolumeGroup(vgName); err != nil {
+			msg := fmt.Sprintf("failed to activate volume group %s: %v", vgName, err)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorActivatingVolumeGroup, msg, "", corev1.EventTypeWarning))
+			klog.Error(msg)
+		}
+	}
+}
+
+// cachedPhysicalVolume is a physical volume of the volume group of lvset together with its device
+type cachedPhysicalVolume struct {
+	device internal.BlockDevice
+	pv     internal.PhysicalVolume
+}
+
+// buildCaches creates a cached logical volume for each unused Rotational device in the volume group of lvset,
+// cached by a slice of the cache size of lvset on a NonRotational device of the volume group that has room for it.
+// It returns whether any cached logical volume was created. The cached logical volumes are provisioned once
+// they show up in a later reconcile.
+func (r *LocalVolumeSetReconciler) buildCaches(lvset *localv1alpha1.LocalVolumeSet, blockDevices []internal.BlockDevice) bool {
+	if lvset.Spec.Cache == nil {
+		return false
+	}
+	vgName := poolVolumeGroupName(lvset)
+	vg, err := internal.GetVolumeGroup(vgName)
+	if err != nil {
+		klog.ErrorS(err, "could not get volume group", "volumeGroup", vgName)
+		return false
+	}
+	if vg == nil {
+		// no device was added yet
+		return false
+	}
+
+	// the devices of cached logical volumes that could not be set up are cached again, once their
+	// logical volumes are removed after a failed create
+	if !r.completeVolumeGroups.Has(vgName) {
+		removed, err := internal.RemoveIncompleteLogicalVolumes(vgName)
+		if len(removed) > 0 {
+			klog.InfoS("removed incomplete cached logical volumes", "volumeGroup", vgName, "logicalVolumes", removed)
+		}
+		if err != nil {
+			klog.ErrorS(err, "could not remove incomplete cached logical volumes", "volumeGroup", vgName)
+			return false
+		}
+		r.completeVolumeGroups.Insert(vgName)
+	}
+
+	physicalVolumes, err := internal.ListPhysicalVolumes()
+	if err != nil {
+		klog.ErrorS(err, "could not list members of volume group", "volumeGroup", vgName)
+		return false
+	}
+	origins := make([]*cachedPhysicalVolume, 0)
+	caches := make([]*cachedPhysicalVolume, 0)
+	for _, blockDevice := range blockDevices {
+		pv, found := physicalVolumes[blockDevice.KName]
+		if !found || pv.VGName != vgName {
+			continue
+		}
+		rotational, err := blockDevice.GetRotational()
+		if err != nil {
+			klog.ErrorS(err, "could not determine whether device is rotational", "device", blockDevice.Name)
+			continue
+		}
+		switch {
+		case !rotational:
+			caches = append(caches, &cachedPhysicalVolume{device: blockDevice, pv: pv})
+		case pv.Free == pv.Size:
+			// not cached yet, or its cached logical volume was removed along with its PV
+			origins = append(origins, &cachedPhysicalVolume{device: blockDevice, pv: pv})
+		}
+	}
+	if len(origins) == 0 {
+		return false
+	}
+	// fill up the cache devices one after another
+	sort.SliceStable(caches, func(i, j int) bool { return caches[i].device.KName < caches[j].device.KName })
+
+	cacheSize := lvset.Spec.Cache.CacheSize.Value()
+	if vg.ExtentSize > 0 {
+		// LVM allocates whole extents
+		cacheSize = (cacheSize + vg.ExtentSize - 1) / vg.ExtentSize * vg.ExtentSize
+	}
+	mode := lvset.Spec.Cache.Mode
+	if mode == "" {
+		mode = localv1alpha1.WriteThrough
+	}
+
+	created := false
+	for _, origin := range origins {
+		var cache *cachedPhysicalVolume
+		for _, candidate := range caches {
+			if candidate.pv.Free >= cacheSize {
+				cache = candidate
+				break
+			}
+		}
+		if cache == nil {
+			klog.InfoS("no room for more caches, waiting for a cache device", "volumeGroup", vgName, "device", origin.device.Name)
+			break
+		}
+		id := rand.String(10)
+		err := internal.CreateCachedLogicalVolume(vgName, id, origin.device, cache.device, cacheSize, string(mode))
+		if err != nil {
+			msg := fmt.Sprintf("failed to cache %s with %s: %v", origin.device.Name, cache.device.Name, err)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorCreatingCachedVolume, msg, origin.device.KName, corev1.EventTypeWarning))
+			klog.Error(msg)
+			r.completeVolumeGroups.Delete(vgName)
+			continue
+		}
+		klog.InfoS("created cached logical volume", "volumeGroup", vgName, "logicalVolume", internal.PoolLogicalVolumePrefix+id,
+			"device", origin.device.Name, "cacheDevice", cache.device.Name, "cacheSize", cacheSize, "cacheMode", mode)
+		cache.pv.Free -= cacheSize
+		created = true
+	}
+	return created
+}
@@ -0,0 +1,103 @@
+package lvset
+
+import (
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestBuildCaches(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	defer func() { internal.CmdExecutor = oldExecutor }()
+
+	var commands [][]string
+	action := func(output string) testingexec.FakeCommandAction {
+		return func(cmd string, args ...string) utilexec.Cmd {
+			commands = append(commands, append([]string{cmd}, args...))
+			return &testingexec.FakeCmd{
+				CombinedOutputScript: []testingexec.FakeAction{
+					func() ([]byte, []byte, error) { return []byte(output), nil, nil },
+				},
+			}
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			// vgs
+			action("  10737418240:4194304"),
+			// lvs, the cached logical volume of sdc and a leftover of a failed attempt
+			action("  vol-abc\n  new-def\n"),
+			// lvremove
+			action(""),
+			// pvs, the cache device has room for one more cache of 1GiB
+			action(`  /dev/sdb:lso-archive:1069547520:1069547520
+  /dev/sdc:lso-archive:1069547520:0
+  /dev/sdd:lso-archive:1069547520:1069547520
+  /dev/nvme0n1:lso-archive:3217031168:1610612736
+`),
+			// lvcreate, lvcreate, lvconvert and lvrename for sdb
+			action(""), action(""), action(""), action(""),
+		},
+	}
+
+	blockDevices := []internal.BlockDevice{
+		{Name: "sda", KName: "sda", Rotational: "1"},
+		{Name: "sdb", KName: "sdb", Rotational: "1"},
+		{Name: "sdc", KName: "sdc", Rotational: "1"},
+		{Name: "sdd", KName: "sdd", Rotational: "1"},
+		{Name: "nvme0n1", KName: "nvme0n1", Rotational: "0"},
+	}
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "archive",
+			Cache:            &localv1alpha1.LocalVolumeSetCache{CacheSize: resource.MustParse("1Gi")},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	assert.True(t, r.buildCaches(lvset, blockDevices))
+
+	assert.Len(t, commands, 8)
+	assert.Equal(t, []string{"lvremove", "-y", "lso-archive/new-def"}, commands[2])
+	// sdb is cached, sdd waits for room on a cache device
+	assert.Contains(t, commands[4], "/dev/sdb")
+	assert.Contains(t, commands[5], "/dev/nvme0n1")
+	assert.Contains(t, commands[6], "writethrough")
+	assert.Equal(t, "lvrename", commands[7][0])
+
+	// leftovers are only looked for again after a failed create
+	commands = nil
+	failing := func(cmd string, args ...string) utilexec.Cmd {
+		commands = append(commands, append([]string{cmd}, args...))
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) { return nil, nil, testingexec.FakeExitError{Status: 5} },
+			},
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			// vgs
+			action("  10737418240:4194304"),
+			// pvs, sdd is not cached yet
+			action(`  /dev/sdd:lso-archive:1069547520:1069547520
+  /dev/nvme0n1:lso-archive:3217031168:1610612736
+`),
+			// lvcreate of sdd fails
+			failing,
+			// vgs and lvs in the next reconcile
+			action("  10737418240:4194304"),
+			failing,
+		},
+	}
+	assert.False(t, r.buildCaches(lvset, blockDevices))
+	assert.Len(t, commands, 3)
+	assert.Equal(t, "lvcreate", commands[2][0])
+	assert.False(t, r.buildCaches(lvset, blockDevices))
+	assert.Len(t, commands, 5)
+	assert.Equal(t, "lvs", commands[4][0])
+}
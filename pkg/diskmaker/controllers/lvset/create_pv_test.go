@@ -383,3 +383,78 @@ func TestCreatePV_SetsLVDLOwnerRefToLocalVolumeSet(t *testing.T) {
 		assert.Equal(t, lvset.UID, lvdl.OwnerReferences[0].UID)
 	}
 }
+
+func TestCreatePVTopology(t *testing.T) {
+	reclaimPolicyDelete := corev1.PersistentVolumeReclaimDelete
+	lvset := localv1alpha1.LocalVolumeSet{
+		TypeMeta: metav1.TypeMeta{Kind: localv1alpha1.LocalVolumeSetKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lvset-topology",
+			Namespace: "default",
+		},
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "storageclass-topology",
+			PVTopology: &localv1.PVTopology{
+				NodeLabels:        []string{corev1.LabelTopologyZone, "example.com/rack"},
+				AddToNodeAffinity: true,
+			},
+		},
+	}
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nodename-topology",
+			Labels: map[string]string{
+				corev1.LabelHostname:     "node-hostname-topology",
+				corev1.LabelTopologyZone: "zone-a",
+				"example.com/rack":       "rack-3",
+			},
+		},
+	}
+	sc := storagev1.StorageClass{
+		ObjectMeta:    metav1.ObjectMeta{Name: "storageclass-topology"},
+		ReclaimPolicy: &reclaimPolicyDelete,
+	}
+	symLinkPath := "/mnt/local-storage/" + sc.Name + "/device-topology"
+
+	r, testConfig := newFakeLocalVolumeSetReconciler(t, &lvset, &node, &sc)
+	r.nodeName = node.Name
+	testConfig.runtimeConfig.Node = &node
+	testConfig.runtimeConfig.Name = common.GetProvisionedByValue(node)
+	testConfig.runtimeConfig.DiscoveryMap[sc.Name] = provCommon.MountConfig{VolumeMode: string(localv1.PersistentVolumeBlock)}
+	testConfig.fakeVolUtil.AddNewDirEntries("/mnt/local-storage/", map[string][]*provUtil.FakeDirEntry{
+		sc.Name: {{Name: "device-topology", Capacity: 10 * common.GiB, VolumeType: provUtil.FakeEntryBlock}},
+	})
+	diskmakertest.WithInternalMocks(t, func() {
+		internal.Readlink = func(symlinkPath string) (string, error) {
+			return "/dev/disk/by-id/wwn-topology", nil
+		}
+	})
+
+	err := common.SyncPVAndLVDL(t.Context(), common.SyncPVAndLVDLArgs{
+		LocalVolumeLikeObject: &lvset,
+		RuntimeConfig:         r.runtimeConfig,
+		StorageClass:          sc,
+		MountPointMap:         sets.New[string](),
+		Client:                r.Client,
+		ClientReader:          r.ClientReader,
+		SymLinkPath:           symLinkPath,
+		BlockDevice:           internal.BlockDevice{KName: "device-topology"},
+		CacheWriter:           r.pvLinkCache,
+		// the zone of the node wins over an extra label with the same key
+		ExtraLabelsForPV: map[string]string{corev1.LabelTopologyZone: "zone-b", "example.com/tier": "fast"},
+		PVTopology:       lvset.Spec.PVTopology,
+	})
+	assert.NoError(t, err)
+
+	pv := &corev1.PersistentVolume{}
+	err = r.Client.Get(t.Context(), types.NamespacedName{Name: common.GeneratePVName(filepath.Base(symLinkPath), node.Name, sc.Name)}, pv)
+	assert.NoError(t, err)
+	assert.Equal(t, "zone-a", pv.Labels[corev1.LabelTopologyZone])
+	assert.Equal(t, "rack-3", pv.Labels["example.com/rack"])
+	assert.Equal(t, "fast", pv.Labels["example.com/tier"])
+	assert.Equal(t, []corev1.NodeSelectorRequirement{
+		{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-hostname-topology"}},
+		{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+		{Key: "example.com/rack", Operator: corev1.NodeSelectorOpIn, Values: []string{"rack-3"}},
+	}, pv.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions)
+}
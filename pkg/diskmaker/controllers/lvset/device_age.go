@@ -1,14 +1,40 @@
 package lvset
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// deviceAgeStateFile is the file in deviceAgeStateDir in which the time devices were first observed
+	// is kept across restarts of the diskmaker. Older versions kept it in the symlink root.
+	deviceAgeStateFile = ".device-ages.json"
 )
 
 var (
-	// deviceMinAge is the minimum age for a device to be considered safe to claim
+	// deviceMinAge is the default minimum age for a device to be considered safe to claim
 	// otherwise, it could be a device that some other entity has attached and we have not claimed.
 	deviceMinAge = time.Minute
+
+	// deviceAgeStateDir is the directory of the deviceAgeStateFile, patched in tests
+	deviceAgeStateDir = common.DiskMakerStateDir
+
+	// bootIDFile identifies the current boot of the node
+	bootIDFile = "/proc/sys/kernel/random/boot_id"
+
+	// udevSettled checks whether udev has processed all events, patched in tests
+	udevSettled = internal.UdevSettled
 )
 
 // timeInterface exists so as it can be patched for testing purpose
@@ -22,22 +48,77 @@ func (t *WallTime) getCurrentTime() time.Time {
 	return time.Now()
 }
 
+// deviceAgeState is the content of the deviceAgeStateFile
+type deviceAgeState struct {
+	// BootID is the boot of the node in which the state was written
+	BootID string `json:"bootID,omitempty"`
+	// FirstObserved maps keys of devices to the time they were first observed
+	FirstObserved map[string]time.Time `json:"firstObserved"`
+}
+
 type ageMap struct {
 	ageMap map[string]time.Time
 	mux    sync.RWMutex
 	clock  timeInterface
+	// stateFile persists ageMap, which is only kept in memory if stateFile is empty
+	stateFile string
+	bootID    string
 }
 
-func newAgeMap(clock timeInterface) *ageMap {
-	return &ageMap{
-		clock:  clock,
-		ageMap: map[string]time.Time{},
+func newAgeMap(clock timeInterface, stateFile string) *ageMap {
+	a := &ageMap{
+		clock:     clock,
+		ageMap:    map[string]time.Time{},
+		stateFile: stateFile,
+	}
+	if stateFile == "" {
+		return a
 	}
+	if bootID, err := os.ReadFile(bootIDFile); err == nil {
+		a.bootID = strings.TrimSpace(string(bootID))
+	} else {
+		klog.ErrorS(err, "could not read boot id", "file", bootIDFile)
+	}
+	if err := a.load(); err != nil {
+		// starting over only delays claiming devices
+		klog.ErrorS(err, "could not load device ages", "file", stateFile)
+	}
+	return a
 }
 
-// checks if older than,
+// deviceAgeKey returns the key of dev in the ageMap. The kernel name alone can be
+// reused by another device after a reboot, so the WWN or serial number is included if known.
+func deviceAgeKey(dev internal.BlockDevice) string {
+	id := dev.WWN
+	if id == "" {
+		id = dev.Serial
+	}
+	if id == "" {
+		id = dev.PartUUID
+	}
+	if id == "" {
+		return dev.KName
+	}
+	return dev.KName + "/" + id
+}
+
+// deviceSettleDuration returns how long devices have to be observed before lvset claims them.
+// The API rejects negative durations, the default is used for objects that were stored before.
+func deviceSettleDuration(lvset *localv1alpha1.LocalVolumeSet) time.Duration {
+	if lvset == nil || lvset.Spec.DeviceSettleDuration == nil {
+		return deviceMinAge
+	}
+	if lvset.Spec.DeviceSettleDuration.Duration < 0 {
+		klog.InfoS("ignoring negative deviceSettleDuration", "namespace", lvset.Namespace, "name", lvset.Name,
+			"deviceSettleDuration", lvset.Spec.DeviceSettleDuration.Duration, "default", deviceMinAge)
+		return deviceMinAge
+	}
+	return lvset.Spec.DeviceSettleDuration.Duration
+}
+
+// checks if older than minAge,
 // records current time if this is the first observation of key
-func (a *ageMap) isOlderThan(key string) bool {
+func (a *ageMap) isOlderThan(key string, minAge time.Duration) bool {
 	a.mux.RLock()
 	defer a.mux.RUnlock()
 
@@ -45,17 +126,107 @@ func (a *ageMap) isOlderThan(key string) bool {
 	if !found {
 		return false
 	}
-	return a.clock.getCurrentTime().Sub(firstObserved) > deviceMinAge
+	return a.clock.getCurrentTime().Sub(firstObserved) > minAge
 }
 
 func (a *ageMap) storeDeviceAge(key string) {
 	a.mux.Lock()
 	defer a.mux.Unlock()
 
-	firstObserved, found := a.ageMap[key]
+	_, found := a.ageMap[key]
 	// set firstObserved if it doesn't exist
 	if !found {
-		firstObserved = a.clock.getCurrentTime()
+		a.ageMap[key] = a.clock.getCurrentTime()
+		a.save()
+	}
+}
+
+// prune forgets the devices whose keys are not in keys, i.e. that are no longer attached
+func (a *ageMap) prune(keys sets.Set[string]) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	pruned := false
+	for key := range a.ageMap {
+		if !keys.Has(key) {
+			delete(a.ageMap, key)
+			pruned = true
+		}
+	}
+	if pruned {
+		a.save()
+	}
+}
+
+// migrateDeviceAgeState moves the state file of an older diskmaker from legacyFile to stateFile,
+// unless there is a stateFile already
+func migrateDeviceAgeState(legacyFile, stateFile string) {
+	data, err := os.ReadFile(legacyFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.ErrorS(err, "could not read device ages", "file", legacyFile)
+		}
+		return
+	}
+	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
+			klog.ErrorS(err, "could not migrate device ages", "file", stateFile)
+			return
+		}
+		if err := os.WriteFile(stateFile, data, 0644); err != nil {
+			klog.ErrorS(err, "could not migrate device ages", "file", stateFile)
+			return
+		}
+	}
+	if err := os.Remove(legacyFile); err != nil {
+		klog.ErrorS(err, "could not remove device ages", "file", legacyFile)
+	}
+}
+
+// load reads the ageMap from the stateFile. Devices without WWN or serial number
+// are only kept if the state was written in the current boot.
+func (a *ageMap) load() error {
+	data, err := os.ReadFile(a.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	state := deviceAgeState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", a.stateFile, err)
+	}
+	sameBoot := a.bootID != "" && state.BootID == a.bootID
+	for key, firstObserved := range state.FirstObserved {
+		if !sameBoot && !strings.Contains(key, "/") {
+			continue
+		}
 		a.ageMap[key] = firstObserved
 	}
+	return nil
+}
+
+// save writes the ageMap to the stateFile, the caller must hold the lock
+func (a *ageMap) save() {
+	if a.stateFile == "" {
+		return
+	}
+	data, err := json.Marshal(deviceAgeState{BootID: a.bootID, FirstObserved: a.ageMap})
+	if err != nil {
+		klog.ErrorS(err, "could not encode device ages")
+		return
+	}
+	// write to a temporary file first so that a crash does not leave a partial state file behind
+	tmpFile := a.stateFile + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(a.stateFile), 0755); err != nil {
+		klog.ErrorS(err, "could not save device ages", "file", a.stateFile)
+		return
+	}
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		klog.ErrorS(err, "could not save device ages", "file", a.stateFile)
+		return
+	}
+	if err := os.Rename(tmpFile, a.stateFile); err != nil {
+		klog.ErrorS(err, "could not save device ages", "file", a.stateFile)
+	}
 }
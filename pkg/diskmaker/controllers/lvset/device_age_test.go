@@ -2,12 +2,19 @@ package lvset
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
+	"github.com/openshift/local-storage-operator/pkg/diskmaker"
 	"github.com/openshift/local-storage-operator/pkg/internal"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type fakeClock struct {
@@ -71,9 +78,240 @@ func TestDeviceAge(t *testing.T) {
 			blockDevices = append(blockDevices, internal.BlockDevice{KName: fmt.Sprintf("dev-%d", len(blockDevices))})
 		}
 
-		validDevices, delayedDevices, _ := r.getValidDevices(lvset, blockDevices)
+		validDevices, delayedDevices, _, _ := r.getValidDevices(lvset, nil, nil, nil, blockDevices)
 		assert.Lenf(t, validDevices, expectedValid[run], "validDevices")
 		assert.Lenf(t, delayedDevices, len(blockDevices)-expectedValid[run], "delayedDevices")
 
 	}
 }
+
+func TestGetValidDevicesProvisioningPolicy(t *testing.T) {
+	// only keep the relaxable filters
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
+		notRemovable:          oldFilterMap[notRemovable],
+		noFilesystemSignature: oldFilterMap[noFilesystemSignature],
+	}
+	oldMatcherMap := matcherMap
+	matcherMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+	}()
+
+	blockDevices := []internal.BlockDevice{
+		{KName: "sda", Removable: "0"},
+		{KName: "sdb", Removable: "1"},
+		{KName: "sdc", Removable: "0", FSType: "xfs"},
+		{KName: "sdd", Removable: "1", FSType: "ext4"},
+	}
+	testTable := []struct {
+		name             string
+		policy           *localv1alpha1.ProvisioningPolicy
+		expectedRejected []string
+	}{
+		{name: "no policy", policy: nil, expectedRejected: []string{"sdb", "sdc", "sdd"}},
+		{name: "removable", policy: &localv1alpha1.ProvisioningPolicy{AllowRemovableDevices: true}, expectedRejected: []string{"sdc", "sdd"}},
+		{name: "xfs", policy: &localv1alpha1.ProvisioningPolicy{AllowedFilesystemTypes: []string{"xfs"}}, expectedRejected: []string{"sdb", "sdd"}},
+		{
+			name:             "removable and ext4",
+			policy:           &localv1alpha1.ProvisioningPolicy{AllowRemovableDevices: true, AllowedFilesystemTypes: []string{"ext4"}},
+			expectedRejected: []string{"sdc"},
+		},
+	}
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			lvset := &localv1alpha1.LocalVolumeSet{Spec: localv1alpha1.LocalVolumeSetSpec{ProvisioningPolicy: tc.policy}}
+			r, _ := newFakeLocalVolumeSetReconciler(t)
+			_, _, rejectedDevices, _ := r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+			rejected := make([]string, 0)
+			for _, dev := range rejectedDevices {
+				rejected = append(rejected, dev.KName)
+			}
+			assert.ElementsMatch(t, tc.expectedRejected, rejected)
+		})
+	}
+}
+
+func TestGetValidDevicesSystemDevices(t *testing.T) {
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := matcherMap
+	matcherMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+	}()
+
+	blockDevices := []internal.BlockDevice{{KName: "sda"}, {KName: "sdb"}, {KName: "md127"}, {KName: "sdc"}}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	validDevices, delayedDevices, rejectedDevices, blockedDevices := r.getValidDevices(&localv1alpha1.LocalVolumeSet{}, nil, sets.New("sda", "md127"), nil, blockDevices)
+	assert.Empty(t, validDevices)
+	assert.Equal(t, []internal.BlockDevice{{KName: "sdb"}, {KName: "sdc"}}, delayedDevices)
+	// system devices are dropped before they are probed or reported
+	assert.Empty(t, rejectedDevices)
+	assert.Empty(t, blockedDevices)
+}
+
+func TestGetValidDevicesBlocklist(t *testing.T) {
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := matcherMap
+	matcherMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+	}()
+
+	blocklist, err := common.NewDeviceBlocklist([]localv1alpha1.LocalVolumeDeviceBlocklist{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "boot-disks"},
+			Spec:       localv1alpha1.LocalVolumeDeviceBlocklistSpec{Serials: []string{"S1"}, ModelRegexes: []string{"^BOSS"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	blockDevices := []internal.BlockDevice{
+		{KName: "sda", Serial: "S1"},
+		{KName: "sdb", Serial: "S2"},
+		{KName: "sdc", Model: "BOSS-N1"},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	lvset := &localv1alpha1.LocalVolumeSet{}
+	validDevices, delayedDevices, rejectedDevices, blockedDevices := r.getValidDevices(lvset, nil, nil, blocklist, blockDevices)
+	assert.Empty(t, validDevices)
+	assert.Empty(t, rejectedDevices)
+	assert.Equal(t, []internal.BlockDevice{{KName: "sdb", Serial: "S2"}}, delayedDevices)
+	assert.Equal(t, []internal.BlockDevice{{KName: "sda", Serial: "S1"}, {KName: "sdc", Model: "BOSS-N1"}}, blockedDevices)
+	assert.True(t, r.eventReporter.hasReported(lvset, newDiskEvent(diskmaker.DeviceBlocked, "", "sda", corev1.EventTypeNormal)))
+	assert.False(t, r.eventReporter.hasReported(lvset, newDiskEvent(diskmaker.DeviceBlocked, "", "sdb", corev1.EventTypeNormal)))
+}
+
+func TestGetValidDevicesSettleDuration(t *testing.T) {
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := matcherMap
+	matcherMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldUdevSettled := udevSettled
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+		udevSettled = oldUdevSettled
+	}()
+
+	settled := false
+	udevSettledCalls := 0
+	udevSettled = func() (bool, error) {
+		udevSettledCalls++
+		return settled, nil
+	}
+
+	blockDevices := []internal.BlockDevice{
+		{KName: "sda", UdevProperties: map[string]string{"ID_SERIAL": "S1"}},
+		{KName: "sdb", UdevProperties: map[string]string{"ID_SERIAL": "S2"}},
+		// not in the udev database yet
+		{KName: "sdc"},
+	}
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{DeviceSettleDuration: &metav1.Duration{Duration: 10 * time.Second}},
+	}
+	r, tc := newFakeLocalVolumeSetReconciler(t)
+	tc.fakeClock.ftime = time.Unix(0, 0)
+
+	validDevices, delayedDevices, _, _ := r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Empty(t, validDevices)
+	assert.Len(t, delayedDevices, 3)
+	assert.Zero(t, udevSettledCalls, "udevadm settle is only run when enabled")
+
+	lvset.Spec.UdevSettle = true
+	validDevices, delayedDevices, _, _ = r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Empty(t, validDevices)
+	assert.Len(t, delayedDevices, 3)
+	assert.Equal(t, 1, udevSettledCalls)
+
+	settled = true
+	validDevices, delayedDevices, _, _ = r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Equal(t, blockDevices[:2], validDevices)
+	assert.Equal(t, blockDevices[2:], delayedDevices)
+	assert.Equal(t, 2, udevSettledCalls)
+
+	// the custom duration replaces deviceMinAge
+	lvset.Spec.UdevSettle = false
+	tc.fakeClock.ftime = time.Unix(11, 0)
+	validDevices, delayedDevices, _, _ = r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Equal(t, blockDevices, validDevices)
+	assert.Empty(t, delayedDevices)
+
+	// negative durations were accepted before the API rejected them
+	lvset.Spec.DeviceSettleDuration.Duration = -time.Minute
+	assert.Equal(t, deviceMinAge, deviceSettleDuration(lvset))
+	validDevices, delayedDevices, _, _ = r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Empty(t, validDevices)
+	assert.Len(t, delayedDevices, 3)
+}
+
+func TestAgeMapPersistence(t *testing.T) {
+	oldBootIDFile := bootIDFile
+	defer func() {
+		bootIDFile = oldBootIDFile
+	}()
+	tmpDir := t.TempDir()
+	bootIDFile = filepath.Join(tmpDir, "boot_id")
+	assert.NoError(t, os.WriteFile(bootIDFile, []byte("boot-1\n"), 0644))
+	stateFile := filepath.Join(tmpDir, "local-storage", deviceAgeStateFile)
+
+	clock := &fakeClock{ftime: time.Unix(0, 0)}
+	withID := deviceAgeKey(internal.BlockDevice{KName: "sda", WWN: "0x5000c500a1b2c3d4"})
+	withoutID := deviceAgeKey(internal.BlockDevice{KName: "vdb"})
+	gone := deviceAgeKey(internal.BlockDevice{KName: "sdb", Serial: "S2"})
+	assert.Equal(t, "sda/0x5000c500a1b2c3d4", withID)
+	assert.Equal(t, "vdb", withoutID)
+
+	a := newAgeMap(clock, stateFile)
+	for _, key := range []string{withID, withoutID, gone} {
+		a.storeDeviceAge(key)
+	}
+	a.prune(sets.New(withID, withoutID))
+
+	// restart of the diskmaker
+	clock.ftime = time.Unix(0, 0).Add(deviceMinAge * 2)
+	a = newAgeMap(clock, stateFile)
+	assert.True(t, a.isOlderThan(withID, deviceMinAge))
+	assert.True(t, a.isOlderThan(withoutID, deviceMinAge))
+	assert.False(t, a.isOlderThan(gone, deviceMinAge))
+
+	// reboot of the node, the kernel name alone could now be another device
+	assert.NoError(t, os.WriteFile(bootIDFile, []byte("boot-2\n"), 0644))
+	a = newAgeMap(clock, stateFile)
+	assert.True(t, a.isOlderThan(withID, deviceMinAge))
+	assert.False(t, a.isOlderThan(withoutID, deviceMinAge))
+
+	// a corrupt state file only delays claiming devices
+	assert.NoError(t, os.WriteFile(stateFile, []byte("{"), 0644))
+	a = newAgeMap(clock, stateFile)
+	assert.False(t, a.isOlderThan(withID, deviceMinAge))
+}
+
+func TestMigrateDeviceAgeState(t *testing.T) {
+	tmpDir := t.TempDir()
+	legacyFile := filepath.Join(tmpDir, "mnt", "local-storage", deviceAgeStateFile)
+	stateFile := filepath.Join(tmpDir, "var", "lib", "local-storage-operator", deviceAgeStateFile)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(legacyFile), 0755))
+	assert.NoError(t, os.WriteFile(legacyFile, []byte(`{"firstObserved":{"sda/S1":"1970-01-01T00:00:00Z"}}`), 0644))
+
+	migrateDeviceAgeState(legacyFile, stateFile)
+	assert.NoFileExists(t, legacyFile)
+	a := newAgeMap(&fakeClock{ftime: time.Unix(0, 0).Add(deviceMinAge * 2)}, stateFile)
+	assert.True(t, a.isOlderThan("sda/S1", deviceMinAge))
+
+	// an existing state file is newer than the one left behind in the symlink root
+	assert.NoError(t, os.WriteFile(legacyFile, []byte(`{"firstObserved":{}}`), 0644))
+	migrateDeviceAgeState(legacyFile, stateFile)
+	assert.NoFileExists(t, legacyFile)
+	a = newAgeMap(&fakeClock{ftime: time.Unix(0, 0).Add(deviceMinAge * 2)}, stateFile)
+	assert.True(t, a.isOlderThan("sda/S1", deviceMinAge))
+
+	// nothing to migrate
+	migrateDeviceAgeState(legacyFile, stateFile)
+	assert.FileExists(t, stateFile)
+}
@@ -0,0 +1,90 @@
+package lvset
+
+import (
+	"cmp"
+	"slices"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"k8s.io/klog/v2"
+)
+
+// orderDevices returns devices in the order they should be claimed according to strategy.
+// Devices that are equal for the strategy are ordered by serial number and then by kernel name,
+// so that the same devices are claimed after a reboot. Without a strategy the order is not changed.
+func orderDevices(devices []internal.BlockDevice, strategy localv1alpha1.SelectionStrategy) []internal.BlockDevice {
+	var compare func(a, b internal.BlockDevice) int
+	switch strategy {
+	case localv1alpha1.SmallestFirst:
+		compare = func(a, b internal.BlockDevice) int {
+			return cmp.Compare(deviceSize(a), deviceSize(b))
+		}
+	case localv1alpha1.LargestFirst:
+		compare = func(a, b internal.BlockDevice) int {
+			return cmp.Compare(deviceSize(b), deviceSize(a))
+		}
+	case localv1alpha1.NonRotationalFirst:
+		compare = func(a, b internal.BlockDevice) int {
+			return cmp.Compare(rotationalRank(a), rotationalRank(b))
+		}
+	case localv1alpha1.BySlotOrder:
+		slots := make(map[string]string, len(devices))
+		for _, dev := range devices {
+			slots[dev.KName] = slotName(dev)
+		}
+		compare = func(a, b internal.BlockDevice) int {
+			slotA, slotB := slots[a.KName], slots[b.KName]
+			// devices without a slot go last
+			if (slotA == "") != (slotB == "") {
+				if slotA == "" {
+					return 1
+				}
+				return -1
+			}
+			return internal.CompareNatural(slotA, slotB)
+		}
+	default:
+		return devices
+	}
+
+	ordered := slices.Clone(devices)
+	slices.SortStableFunc(ordered, func(a, b internal.BlockDevice) int {
+		if c := compare(a, b); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Serial, b.Serial); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.KName, b.KName)
+	})
+	return ordered
+}
+
+// deviceSize returns the size of dev in bytes, or 0 if it is unknown
+func deviceSize(dev internal.BlockDevice) int64 {
+	size, err := dev.GetSize()
+	if err != nil {
+		klog.ErrorS(err, "could not determine device size for ordering", "device", dev.Name)
+		return 0
+	}
+	return size
+}
+
+// rotationalRank returns 0 for non-rotational devices and 1 for rotational or unknown ones
+func rotationalRank(dev internal.BlockDevice) int {
+	rotational, err := dev.GetRotational()
+	if err != nil || rotational {
+		return 1
+	}
+	return 0
+}
+
+// slotName returns the slot of dev, or an empty string if it has none
+func slotName(dev internal.BlockDevice) string {
+	slot, err := dev.GetSlot()
+	if err != nil {
+		klog.ErrorS(err, "could not list by-path links for ordering", "device", dev.Name)
+		return ""
+	}
+	return slot
+}
@@ -0,0 +1,68 @@
+package lvset
+
+import (
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderDevices(t *testing.T) {
+	origGlob := internal.FilePathGlob
+	origEval := internal.FilePathEvalSymLinks
+	defer func() {
+		internal.FilePathGlob = origGlob
+		internal.FilePathEvalSymLinks = origEval
+	}()
+	byPathLinks := map[string]string{
+		"/dev/disk/by-path/pci-0000:3b:00.0-sas-phy10-lun-0": "/dev/sda",
+		"/dev/disk/by-path/pci-0000:3b:00.0-sas-phy2-lun-0":  "/dev/sdb",
+		"/dev/disk/by-path/pci-0000:3b:00.0-sas-phy1-lun-0":  "/dev/sdc",
+	}
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		links := make([]string, 0)
+		for link := range byPathLinks {
+			links = append(links, link)
+		}
+		return links, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		return byPathLinks[path], nil
+	}
+
+	devices := []internal.BlockDevice{
+		{KName: "sda", Serial: "C", Size: "200", Rotational: "1"},
+		{KName: "sdb", Serial: "A", Size: "100", Rotational: "1"},
+		{KName: "sdc", Serial: "B", Size: "300", Rotational: "0"},
+		{KName: "sdd", Serial: "D", Size: "100", Rotational: "0"},
+	}
+	kNames := func(devices []internal.BlockDevice) []string {
+		names := make([]string, 0, len(devices))
+		for _, dev := range devices {
+			names = append(names, dev.KName)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		strategy      localv1alpha1.SelectionStrategy
+		expectedOrder []string
+	}{
+		{strategy: "", expectedOrder: []string{"sda", "sdb", "sdc", "sdd"}},
+		// sdb and sdd have the same size, sdb has the lower serial
+		{strategy: localv1alpha1.SmallestFirst, expectedOrder: []string{"sdb", "sdd", "sda", "sdc"}},
+		{strategy: localv1alpha1.LargestFirst, expectedOrder: []string{"sdc", "sda", "sdb", "sdd"}},
+		// phy1 < phy2 < phy10, sdd has no slot
+		{strategy: localv1alpha1.BySlotOrder, expectedOrder: []string{"sdc", "sdb", "sda", "sdd"}},
+		{strategy: localv1alpha1.NonRotationalFirst, expectedOrder: []string{"sdc", "sdd", "sdb", "sda"}},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			ordered := orderDevices(devices, tc.strategy)
+			assert.Equal(t, tc.expectedOrder, kNames(ordered))
+			// the input is not reordered
+			assert.Equal(t, []string{"sda", "sdb", "sdc", "sdd"}, kNames(devices))
+		})
+	}
+}
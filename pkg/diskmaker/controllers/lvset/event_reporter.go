@@ -5,6 +5,7 @@ import (
 	"os"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 
@@ -16,8 +17,38 @@ const (
 	// ErrorListingExistingSymlinks is an event reason string
 	ErrorListingExistingSymlinks = "ErrorListingExistingSymlinks"
 	ErrorMaxCountReached         = "ErrorMaxCountReached"
+	// ErrorMaxCapacityReached is an event reason string
+	ErrorMaxCapacityReached = "ErrorMaxCapacityReached"
+	// ErrorInvalidDeviceSelector is an event reason string
+	ErrorInvalidDeviceSelector = "ErrorInvalidDeviceSelector"
+	// ErrorInvalidIdentifierPattern is an event reason string
+	ErrorInvalidIdentifierPattern = "ErrorInvalidIdentifierPattern"
 	// DiscoveredNewDevice is an event reason string
 	DiscoveredNewDevice = "DiscoveredNewDevice"
+	// DeviceRejected is an event reason string
+	DeviceRejected = "DeviceRejected"
+	// DevicePooled is an event reason string
+	DevicePooled = "DevicePooled"
+	// ErrorPoolingDevice is an event reason string
+	ErrorPoolingDevice = "ErrorPoolingDevice"
+	// ErrorCreatingPoolVolume is an event reason string
+	ErrorCreatingPoolVolume = "ErrorCreatingPoolVolume"
+	// ErrorRemovingPoolVolume is an event reason string
+	ErrorRemovingPoolVolume = "ErrorRemovingPoolVolume"
+	// RaidArrayCreated is an event reason string
+	RaidArrayCreated = "RaidArrayCreated"
+	// RaidArrayDegraded is an event reason string
+	RaidArrayDegraded = "RaidArrayDegraded"
+	// ErrorCreatingRaidArray is an event reason string
+	ErrorCreatingRaidArray = "ErrorCreatingRaidArray"
+	// ErrorAssemblingRaidArray is an event reason string
+	ErrorAssemblingRaidArray = "ErrorAssemblingRaidArray"
+	// ErrorCreatingCachedVolume is an event reason string
+	ErrorCreatingCachedVolume = "ErrorCreatingCachedVolume"
+	// ErrorActivatingVolumeGroup is an event reason string
+	ErrorActivatingVolumeGroup = "ErrorActivatingVolumeGroup"
+	// ErrorNoCacheDevice is an event reason string
+	ErrorNoCacheDevice = "ErrorNoCacheDevice"
 )
 
 func newDiskEvent(eventReason, message, disk, eventType string) diskmaker.DiskEvent {
@@ -41,7 +72,7 @@ func newEventReporter(eventRecorder record.EventRecorder) *eventReporter {
 func (rep *eventReporter) Report(obj runtime.Object, e diskmaker.DiskEvent) {
 	rep.mux.Lock()
 	defer rep.mux.Unlock()
-	eventKey := fmt.Sprintf("%s:%s:%s", e.EventReason, e.EventType, e.Disk)
+	eventKey := eventKey(obj, e)
 	if rep.reportedEvents.Has(eventKey) {
 		return
 	}
@@ -49,6 +80,22 @@ func (rep *eventReporter) Report(obj runtime.Object, e diskmaker.DiskEvent) {
 	rep.reportedEvents.Insert(eventKey)
 }
 
+// hasReported returns whether an event with the same reason, type and disk as e was already reported for obj
+func (rep *eventReporter) hasReported(obj runtime.Object, e diskmaker.DiskEvent) bool {
+	rep.mux.Lock()
+	defer rep.mux.Unlock()
+	return rep.reportedEvents.Has(eventKey(obj, e))
+}
+
+// eventKey identifies e for obj, so that every LocalVolumeSet is told about a disk once
+func eventKey(obj runtime.Object, e diskmaker.DiskEvent) string {
+	objKey := ""
+	if accessor, err := meta.Accessor(obj); err == nil {
+		objKey = accessor.GetNamespace() + "/" + accessor.GetName()
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", objKey, e.EventReason, e.EventType, e.Disk)
+}
+
 func (reporter *eventReporter) recordEvent(obj runtime.Object, e diskmaker.DiskEvent) {
 	nodeName := os.Getenv("MY_NODE_NAME")
 	message := e.Message
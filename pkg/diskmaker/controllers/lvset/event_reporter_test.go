@@ -8,9 +8,12 @@ import (
 
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/pkg/diskmaker"
+	"github.com/openshift/local-storage-operator/pkg/internal"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
 )
 
 func TestEventReporer(t *testing.T) {
@@ -67,3 +70,52 @@ func TestEventReporer(t *testing.T) {
 	assert.Len(t, recordedEvents, len(uniqueEvents))
 
 }
+
+func TestReportRejectedDevice(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	origGlob := internal.FilePathGlob
+	defer func() {
+		internal.CmdExecutor = oldExecutor
+		internal.FilePathGlob = origGlob
+	}()
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		return []string{}, nil
+	}
+	probes := 0
+	probe := func(cmd string, args ...string) utilexec.Cmd {
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) {
+					probes++
+					return []byte("TYPE=ceph_bluestore\n"), nil, nil
+				},
+			},
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{probe, probe},
+	}
+
+	r, tc := newFakeLocalVolumeSetReconciler(t)
+	lvset := &localv1alpha1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "local-storage"}}
+	otherLVSet := &localv1alpha1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "local-storage"}}
+	dev := internal.BlockDevice{Name: "sdb", KName: "sdb"}
+
+	r.reportRejectedDevice(lvset, dev)
+	// the device is only probed and reported once per LocalVolumeSet
+	r.reportRejectedDevice(lvset, dev)
+	assert.Equal(t, 1, probes)
+	r.reportRejectedDevice(otherLVSet, dev)
+	assert.Equal(t, 2, probes)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-tc.eventStream:
+			assert.Contains(t, event, DeviceRejected)
+			assert.Contains(t, event, "likely owner: Ceph OSD (bluestore)")
+		default:
+			t.Fatal("expected a DeviceRejected event")
+		}
+	}
+	assert.Empty(t, tc.eventStream)
+}
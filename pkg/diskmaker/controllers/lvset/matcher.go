@@ -3,9 +3,11 @@ package lvset
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
 	"github.com/openshift/local-storage-operator/pkg/internal"
 	"golang.org/x/sys/unix"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -19,6 +21,8 @@ const (
 	noBiosBootInPartLabel = "noBiosBootInPartLabel"
 	noFilesystemSignature = "noFilesystemSignature"
 	noBindMounts          = "noBindMounts"
+	// NoEmptyPartitionTable is exported for the device discovery, which reports such devices as not available
+	NoEmptyPartitionTable = "noEmptyPartitionTable"
 	// file access , can't mock test
 	noChildren = "noChildren"
 	// file access , can't mock test
@@ -30,9 +34,35 @@ const (
 	inMechanicalPropertyList = "inMechanicalPropertyList"
 	inVendorList             = "inVendorList"
 	inModelList              = "inModelList"
+	inTransportList          = "inTransportList"
+	inLogicalSectorSizeList  = "inLogicalSectorSizeList"
+	inZonedModelList         = "inZonedModelList"
+	supportsDiscard          = "supportsDiscard"
+	inSerialList             = "inSerialList"
+	inWWNList                = "inWWNList"
+	inByPathList             = "inByPathList"
+	inByIDList               = "inByIDList"
+	inUdevProperties         = "inUdevProperties"
+	inVolumeGroupList        = "inVolumeGroupList"
+	inLogicalVolumeList      = "inLogicalVolumeList"
+	inPartLabelList          = "inPartLabelList"
+	inPartTypeList           = "inPartTypeList"
+	inPartUUIDList           = "inPartUUIDList"
 
 	// exclusion matcher names:
-	notInDeviceNameFilter = "notInDeviceNameFilter"
+	notInDeviceNameFilter  = "notInDeviceNameFilter"
+	notInVendorList        = "notInVendorList"
+	notInModelList         = "notInModelList"
+	notInSizeRange         = "notInSizeRange"
+	notInSerialList        = "notInSerialList"
+	notInWWNList           = "notInWWNList"
+	notInByPathList        = "notInByPathList"
+	notInByIDList          = "notInByIDList"
+	notInVolumeGroupList   = "notInVolumeGroupList"
+	notInLogicalVolumeList = "notInLogicalVolumeList"
+	notInPartLabelList     = "notInPartLabelList"
+	notInPartTypeList      = "notInPartTypeList"
+	notInPartUUIDList      = "notInPartUUIDList"
 )
 
 var defaultMinSize = resource.MustParse("1Gi")
@@ -76,6 +106,15 @@ var DefaultFilterMap = map[string]func(internal.BlockDevice, *localv1alpha1.Devi
 		hasChildren, err := dev.HasChildren()
 		return !hasChildren, err
 	},
+	// a partition table without partitions has no children and no filesystem,
+	// but the device is most likely prepared for some other use
+	NoEmptyPartitionTable: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if dev.PTType == "" || dev.Type == string(localv1alpha1.Partition) {
+			return true, nil
+		}
+		hasChildren, err := dev.HasChildren()
+		return hasChildren, err
+	},
 	canOpenExclusively: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
 		pathname, err := dev.GetDevPath()
 		if err != nil {
@@ -98,6 +137,23 @@ var DefaultFilterMap = map[string]func(internal.BlockDevice, *localv1alpha1.Devi
 	},
 }
 
+// functions that accept a device that was rejected by the DefaultFilterMap filter of the same name,
+// if the *localv1alpha1.ProvisioningPolicy allows it
+var relaxedFilterMap = map[string]func(internal.BlockDevice, *localv1alpha1.ProvisioningPolicy) bool{
+	notRemovable: func(dev internal.BlockDevice, policy *localv1alpha1.ProvisioningPolicy) bool {
+		return policy != nil && policy.AllowRemovableDevices
+	},
+
+	noFilesystemSignature: func(dev internal.BlockDevice, policy *localv1alpha1.ProvisioningPolicy) bool {
+		if policy == nil || dev.FSType == "" {
+			return false
+		}
+		return slices.ContainsFunc(policy.AllowedFilesystemTypes, func(fsType string) bool {
+			return strings.EqualFold(fsType, dev.FSType)
+		})
+	},
+}
+
 // functions that match device by *localv1alpha1.DeviceInclusionSpec
 var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
 
@@ -105,28 +161,10 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		if spec == nil {
 			return true, nil
 		}
-		matched := false
-		quantity, err := resource.ParseQuantity(dev.Size)
-		if err != nil {
-			return false, fmt.Errorf("could not parse device size: %w", err)
-		}
-		greaterThanOrEqualToMin := true
 		if spec.MinSize == nil {
 			spec.MinSize = &defaultMinSize
 		}
-		// quantity greater than min: -1
-		// quantity equal to min: 0
-		greaterThanOrEqualToMin = spec.MinSize.Cmp(quantity) <= 0
-
-		lessThanOrEqualToMax := true
-		if spec.MaxSize != nil {
-			// quantity less than max: 1
-			// quantity equal to max: 0
-			lessThanOrEqualToMax = spec.MaxSize.Cmp(quantity) >= 0
-		}
-
-		matched = greaterThanOrEqualToMin && lessThanOrEqualToMax
-		return matched, nil
+		return sizeInRange(dev, spec.MinSize, spec.MaxSize)
 	},
 	inTypeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
 		matched := false
@@ -143,6 +181,10 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 				break
 			}
 		}
+		if matched && strings.EqualFold(dev.Type, string(localv1alpha1.LVM)) {
+			// LVM internal devices, e.g. thin pool data, are listed with the same type
+			return dev.IsLogicalVolume()
+		}
 		return matched, nil
 	},
 	inMechanicalPropertyList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
@@ -174,14 +216,7 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		if len(spec.Vendors) == 0 {
 			return true, nil
 		}
-		matched := false
-		for _, vendor := range spec.Vendors {
-			if strings.Contains(strings.ToLower(dev.Vendor), strings.ToLower(vendor)) {
-				matched = true
-				break
-			}
-		}
-		return matched, nil
+		return containsAnyFold(dev.GetVendor(), spec.Vendors), nil
 	},
 
 	inModelList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
@@ -191,15 +226,161 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		if len(spec.Models) == 0 {
 			return true, nil
 		}
+		return containsAnyFold(dev.GetModel(), spec.Models), nil
+	},
+
+	inTransportList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil {
+			return true, nil
+		}
+		if len(spec.Transports) == 0 {
+			return true, nil
+		}
 		matched := false
-		for _, model := range spec.Models {
-			if strings.Contains(strings.ToLower(dev.Model), strings.ToLower(model)) {
+		for _, transport := range spec.Transports {
+			if strings.EqualFold(dev.Transport, transport) {
 				matched = true
 				break
 			}
 		}
 		return matched, nil
 	},
+
+	inLogicalSectorSizeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil {
+			return true, nil
+		}
+		if len(spec.LogicalSectorSizes) == 0 {
+			return true, nil
+		}
+		logicalSectorSize, err := dev.GetLogicalSectorSize()
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(spec.LogicalSectorSizes, logicalSectorSize), nil
+	},
+
+	inZonedModelList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.ZonedModels) == 0 {
+			return true, nil
+		}
+		// lsblk reports an empty value on kernels without zoned block device support
+		zoned := localv1alpha1.ZonedModel(strings.ToLower(dev.Zoned))
+		if zoned == "" {
+			zoned = localv1alpha1.NotZoned
+		}
+		return slices.Contains(spec.ZonedModels, zoned), nil
+	},
+
+	supportsDiscard: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || !spec.RequireDiscard {
+			return true, nil
+		}
+		discardMax, err := dev.GetDiscardMax()
+		if err != nil {
+			return false, err
+		}
+		return discardMax > 0, nil
+	},
+
+	inSerialList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.Serials) == 0 {
+			return true, nil
+		}
+		return common.MatchesAnyIdentifier(spec.Serials, dev.Serial)
+	},
+
+	inWWNList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.WWNs) == 0 {
+			return true, nil
+		}
+		return common.MatchesAnyIdentifier(spec.WWNs, dev.WWN)
+	},
+
+	inByPathList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.ByPathNames) == 0 {
+			return true, nil
+		}
+		names, err := symlinkNames(dev.GetValidByPathSymlinks)
+		if err != nil {
+			return false, err
+		}
+		return common.MatchesAnyIdentifier(spec.ByPathNames, names...)
+	},
+
+	inByIDList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.ByIDNames) == 0 {
+			return true, nil
+		}
+		names, err := symlinkNames(dev.GetValidByIDSymlinks)
+		if err != nil {
+			return false, err
+		}
+		return common.MatchesAnyIdentifier(spec.ByIDNames, names...)
+	},
+
+	inUdevProperties: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.UdevProperties) == 0 {
+			return true, nil
+		}
+		for key, pattern := range spec.UdevProperties {
+			value, found := dev.UdevProperties[key]
+			if !found {
+				return false, nil
+			}
+			matched, err := common.MatchesAnyIdentifier([]string{pattern}, value)
+			if err != nil {
+				return false, fmt.Errorf("udev property %q: %w", key, err)
+			}
+			if !matched {
+				return false, nil
+			}
+		}
+		return true, nil
+	},
+
+	inVolumeGroupList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.VolumeGroups) == 0 {
+			return true, nil
+		}
+		vgName, _, err := dev.GetLVMNames()
+		if err != nil {
+			return false, err
+		}
+		return common.MatchesAnyIdentifier(spec.VolumeGroups, vgName)
+	},
+
+	inLogicalVolumeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.LogicalVolumes) == 0 {
+			return true, nil
+		}
+		_, lvName, err := dev.GetLVMNames()
+		if err != nil {
+			return false, err
+		}
+		return common.MatchesAnyIdentifier(spec.LogicalVolumes, lvName)
+	},
+
+	inPartLabelList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.PartLabels) == 0 {
+			return true, nil
+		}
+		return common.MatchesAnyIdentifier(spec.PartLabels, dev.PartLabel)
+	},
+
+	inPartTypeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.PartTypes) == 0 {
+			return true, nil
+		}
+		return common.MatchesAnyIdentifierFold(spec.PartTypes, dev.PartType)
+	},
+
+	inPartUUIDList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil || len(spec.PartUUIDs) == 0 {
+			return true, nil
+		}
+		return common.MatchesAnyIdentifierFold(spec.PartUUIDs, dev.PartUUID)
+	},
 }
 
 // functions that exclude devices by *localv1alpha1.DeviceExclusionSpec
@@ -219,4 +400,190 @@ var exclusionMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceEx
 		}
 		return true, nil
 	},
+
+	notInVendorList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.Vendors) == 0 {
+			return true, nil
+		}
+		return !containsAnyFold(dev.GetVendor(), spec.Vendors), nil
+	},
+
+	notInModelList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.Models) == 0 {
+			return true, nil
+		}
+		return !containsAnyFold(dev.GetModel(), spec.Models), nil
+	},
+
+	notInSizeRange: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || (spec.MinSize == nil && spec.MaxSize == nil) {
+			return true, nil
+		}
+		matched, err := sizeInRange(dev, spec.MinSize, spec.MaxSize)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInSerialList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.Serials) == 0 {
+			return true, nil
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.Serials, dev.Serial)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInWWNList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.WWNs) == 0 {
+			return true, nil
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.WWNs, dev.WWN)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInByPathList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.ByPathNames) == 0 {
+			return true, nil
+		}
+		names, err := symlinkNames(dev.GetValidByPathSymlinks)
+		if err != nil {
+			return false, err
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.ByPathNames, names...)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInByIDList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.ByIDNames) == 0 {
+			return true, nil
+		}
+		names, err := symlinkNames(dev.GetValidByIDSymlinks)
+		if err != nil {
+			return false, err
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.ByIDNames, names...)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInVolumeGroupList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.VolumeGroups) == 0 {
+			return true, nil
+		}
+		vgName, _, err := dev.GetLVMNames()
+		if err != nil {
+			return false, err
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.VolumeGroups, vgName)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInLogicalVolumeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.LogicalVolumes) == 0 {
+			return true, nil
+		}
+		_, lvName, err := dev.GetLVMNames()
+		if err != nil {
+			return false, err
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.LogicalVolumes, lvName)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInPartLabelList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.PartLabels) == 0 {
+			return true, nil
+		}
+		matched, err := common.MatchesAnyIdentifier(spec.PartLabels, dev.PartLabel)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInPartTypeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.PartTypes) == 0 {
+			return true, nil
+		}
+		matched, err := common.MatchesAnyIdentifierFold(spec.PartTypes, dev.PartType)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+
+	notInPartUUIDList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceExclusionSpec) (bool, error) {
+		if spec == nil || len(spec.PartUUIDs) == 0 {
+			return true, nil
+		}
+		matched, err := common.MatchesAnyIdentifierFold(spec.PartUUIDs, dev.PartUUID)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	},
+}
+
+// sizeInRange checks that the device size is within [minSize, maxSize]. A nil bound is not checked.
+func sizeInRange(dev internal.BlockDevice, minSize, maxSize *resource.Quantity) (bool, error) {
+	quantity, err := resource.ParseQuantity(dev.Size)
+	if err != nil {
+		return false, fmt.Errorf("could not parse device size: %w", err)
+	}
+	greaterThanOrEqualToMin := true
+	if minSize != nil {
+		// quantity greater than min: -1
+		// quantity equal to min: 0
+		greaterThanOrEqualToMin = minSize.Cmp(quantity) <= 0
+	}
+
+	lessThanOrEqualToMax := true
+	if maxSize != nil {
+		// quantity less than max: 1
+		// quantity equal to max: 0
+		lessThanOrEqualToMax = maxSize.Cmp(quantity) >= 0
+	}
+
+	return greaterThanOrEqualToMin && lessThanOrEqualToMax, nil
+}
+
+// containsAnyFold checks whether value contains any of substrings, ignoring case
+func containsAnyFold(value string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(strings.ToLower(value), strings.ToLower(substring)) {
+			return true
+		}
+	}
+	return false
+}
+
+// symlinkNames returns the base names of the symlinks returned by list
+func symlinkNames(list func() ([]string, error)) ([]string, error) {
+	links, err := list()
+	if err != nil {
+		return nil, fmt.Errorf("could not list device symlinks: %w", err)
+	}
+	names := make([]string, 0, len(links))
+	for _, link := range links {
+		names = append(names, filepath.Base(link))
+	}
+	return names, nil
 }
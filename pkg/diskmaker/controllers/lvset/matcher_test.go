@@ -2,6 +2,8 @@ package lvset
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
@@ -611,6 +613,678 @@ func TestInModelList(t *testing.T) {
 	assertAll(t, results)
 }
 
+func TestInTransportList(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := inTransportList
+	results := []knownMatcherResult{
+		// nil spec or empty list: always pass
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: "usb"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: "usb"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// exact, case insensitive match
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: "nvme"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{Transports: []string{"NVMe"}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: "sas"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{Transports: []string{"nvme", "sas"}},
+			expectMatch: true, expectErr: false,
+		},
+		// mismatch
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: "usb"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{Transports: []string{"nvme"}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: "iscsi"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{Transports: []string{"nvme", "sata"}},
+			expectMatch: false, expectErr: false,
+		},
+		// partitions have no transport
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Transport: ""},
+			spec:        &localv1alpha1.DeviceInclusionSpec{Transports: []string{"nvme"}},
+			expectMatch: false, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestInLogicalSectorSizeList(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := inLogicalSectorSizeList
+	results := []knownMatcherResult{
+		// nil spec or empty list: always pass
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{LogicalSectorSize: "512"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{LogicalSectorSize: ""},
+			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// match
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{LogicalSectorSize: "4096"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{LogicalSectorSizes: []int64{4096}},
+			expectMatch: true, expectErr: false,
+		},
+		// mismatch
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{LogicalSectorSize: "512"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{LogicalSectorSizes: []int64{4096}},
+			expectMatch: false, expectErr: false,
+		},
+		// unparsable value
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{LogicalSectorSize: ""},
+			spec:        &localv1alpha1.DeviceInclusionSpec{LogicalSectorSizes: []int64{512}},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestInZonedModelList(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := inZonedModelList
+	results := []knownMatcherResult{
+		// nil spec: zoned models are not filtered
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: "none"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: ""},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: "host-managed"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		// empty list: zoned models are not filtered
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: "host-aware"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// explicitly requested zoned models
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: "host-managed"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{ZonedModels: []localv1alpha1.ZonedModel{localv1alpha1.HostManaged}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: "none"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{ZonedModels: []localv1alpha1.ZonedModel{localv1alpha1.HostManaged}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Zoned: ""},
+			spec:        &localv1alpha1.DeviceInclusionSpec{ZonedModels: []localv1alpha1.ZonedModel{localv1alpha1.NotZoned, localv1alpha1.HostAware}},
+			expectMatch: true, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestSupportsDiscard(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := supportsDiscard
+	results := []knownMatcherResult{
+		// not required: always pass
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{DiscardMax: "0"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{DiscardMax: "0"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// required
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{DiscardMax: "2199023255040"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{RequireDiscard: true},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{DiscardMax: "0"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{RequireDiscard: true},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{DiscardMax: ""},
+			spec:        &localv1alpha1.DeviceInclusionSpec{RequireDiscard: true},
+			expectMatch: false, expectErr: false,
+		},
+		// unparsable value
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{DiscardMax: "2G"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{RequireDiscard: true},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestInSerialList(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := inSerialList
+	results := []knownMatcherResult{
+		// nil spec or empty list: always pass
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "S3EVNX0K123456"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "S3EVNX0K123456"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// glob
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "S3EVNX0K123456"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"S3EV*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "Z1X00001"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"S3EV*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		// regex
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "Z1X00001"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"S3EV*", "regex:^Z1X[0-9]{5}$"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "Z1X0000A"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"regex:^Z1X[0-9]{5}$"}}},
+			expectMatch: false, expectErr: false,
+		},
+		// devices without a serial never match
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: ""},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		// invalid patterns
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "S3EVNX0K123456"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"[invalid"}}},
+			expectMatch: false, expectErr: true,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Serial: "S3EVNX0K123456"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"regex:(invalid"}}},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestInWWNList(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := inWWNList
+	results := []knownMatcherResult{
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{WWN: "0x5000c500a1b2c3d4"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{WWN: "0x5000c500a1b2c3d4"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{WWNs: []string{"0x5000c500*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{WWN: "0x5002538e40a1b2c3"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{WWNs: []string{"0x5000c500*"}}},
+			expectMatch: false, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestInByPathAndByIDList(t *testing.T) {
+	origGlob := internal.FilePathGlob
+	origEval := internal.FilePathEvalSymLinks
+	defer func() {
+		internal.FilePathGlob = origGlob
+		internal.FilePathEvalSymLinks = origEval
+	}()
+	links := map[string]string{
+		"/dev/disk/by-path/pci-0000:3b:00.0-sas-phy4-lun-0": "/dev/sda",
+		"/dev/disk/by-path/pci-0000:3b:00.0-sas-phy5-lun-0": "/dev/sdb",
+		"/dev/disk/by-id/wwn-0x5000c500a1b2c3d4":            "/dev/sda",
+		"/dev/disk/by-id/scsi-35000c500a1b2c3d4":            "/dev/sda",
+		"/dev/disk/by-id/wwn-0x5002538e40a1b2c3":            "/dev/sdb",
+	}
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		matches := make([]string, 0)
+		for link := range links {
+			if matched, _ := filepath.Match(pattern, link); matched {
+				matches = append(matches, link)
+			}
+		}
+		return matches, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		return links[path], nil
+	}
+
+	matcherMap := matcherMap
+	results := []knownMatcherResult{
+		{
+			matcherMap: matcherMap, matcher: inByPathList,
+			dev:         internal.BlockDevice{KName: "sda"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByPathNames: []string{"*-phy4-*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inByPathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByPathNames: []string{"*-phy4-*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inByPathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByPathNames: []string{`regex:-phy[4-5]-`}}},
+			expectMatch: true, expectErr: false,
+		},
+		// a device without by-path links
+		{
+			matcherMap: matcherMap, matcher: inByPathList,
+			dev:         internal.BlockDevice{KName: "sdc"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByPathNames: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inByIDList,
+			dev:         internal.BlockDevice{KName: "sda"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByIDNames: []string{"scsi-35000c500*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inByIDList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByIDNames: []string{"scsi-35000c500*"}}},
+			expectMatch: false, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+
+	em := exclusionMap
+	exclusionResults := []knownExclusionMatcherResult{
+		{
+			matcherMap: em, matcher: notInByPathList,
+			dev:         internal.BlockDevice{KName: "sda"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByPathNames: []string{"*-phy4-*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInByPathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByPathNames: []string{"*-phy4-*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInByIDList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByIDNames: []string{"wwn-0x5002538e*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInByIDList,
+			dev:         internal.BlockDevice{KName: "sda"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{ByIDNames: []string{"wwn-0x5002538e*"}}},
+			expectMatch: true, expectErr: false,
+		},
+	}
+	assertAllExclusion(t, exclusionResults)
+}
+
+func TestInVolumeGroupAndLogicalVolumeList(t *testing.T) {
+	origGlob := internal.FilePathGlob
+	origEval := internal.FilePathEvalSymLinks
+	defer func() {
+		internal.FilePathGlob = origGlob
+		internal.FilePathEvalSymLinks = origEval
+	}()
+	// the kernel names don't exist in sysfs, so the device mapper UUIDs are read from the by-id links
+	vgUUID := "Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uN"
+	links := map[string]string{
+		"/dev/disk/by-id/dm-uuid-LVM-" + vgUUID + "aB3dE5fG7hJ9kL1mN3pQ5rS7tU9vW1xY":       "/dev/dm-lvtest1",
+		"/dev/disk/by-id/dm-uuid-LVM-" + vgUUID + "cD4eF6gH8iJ0kL2mN4oP6qR8sT0uV2wX":       "/dev/dm-lvtest2",
+		"/dev/disk/by-id/dm-uuid-LVM-" + vgUUID + "eF5gH7iJ9kL1mN3oP5qR7sT9uV1wX3yZ-tpool": "/dev/dm-lvtest3",
+		"/dev/disk/by-id/dm-uuid-mpath-36001405a1b2c3d4e5f6a7b8c9d0e1f2a":                  "/dev/dm-lvtest4",
+		"/dev/disk/by-id/dm-name-data--vg-lv--1":                                           "/dev/dm-lvtest1",
+		"/dev/disk/by-id/wwn-0x5000c500a1b2c3d4":                                           "/dev/sda",
+	}
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		matches := make([]string, 0)
+		for link := range links {
+			if matched, _ := filepath.Match(pattern, link); matched {
+				matches = append(matches, link)
+			}
+		}
+		return matches, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		return links[path], nil
+	}
+
+	// names from the device mapper name
+	dataLV := internal.BlockDevice{Name: "data--vg-lv--1", KName: "dm-lvtest1", Type: "lvm"}
+	// names from udev
+	logsLV := internal.BlockDevice{Name: "data--vg-logs", KName: "dm-lvtest2", Type: "lvm",
+		UdevProperties: map[string]string{internal.UdevDMVGName: "data-vg", internal.UdevDMLVName: "logs"}}
+	thinPool := internal.BlockDevice{Name: "data--vg-pool-tpool", KName: "dm-lvtest3", Type: "lvm"}
+	mpath := internal.BlockDevice{Name: "mpatha", KName: "dm-lvtest4", Type: "mpath"}
+	disk := internal.BlockDevice{Name: "sda", KName: "sda", Type: "disk"}
+
+	lvmTypes := &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.LVM}}
+	results := []knownMatcherResult{
+		{
+			matcherMap: matcherMap, matcher: inTypeList,
+			dev: dataLV, spec: lvmTypes,
+			expectMatch: true, expectErr: false,
+		},
+		// LVM internal devices are not selected
+		{
+			matcherMap: matcherMap, matcher: inTypeList,
+			dev: thinPool, spec: lvmTypes,
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inVolumeGroupList,
+			dev:         dataLV,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{VolumeGroups: []string{"data-vg"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inVolumeGroupList,
+			dev:         logsLV,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{VolumeGroups: []string{"regex:^data-"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inVolumeGroupList,
+			dev:         dataLV,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{VolumeGroups: []string{"data"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inLogicalVolumeList,
+			dev:         dataLV,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{LogicalVolumes: []string{"lv-*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inLogicalVolumeList,
+			dev:         logsLV,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{LogicalVolumes: []string{"lv-*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		// devices that are not logical volumes never match
+		{
+			matcherMap: matcherMap, matcher: inVolumeGroupList,
+			dev:         mpath,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{VolumeGroups: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inLogicalVolumeList,
+			dev:         disk,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{LogicalVolumes: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inLogicalVolumeList,
+			dev:         thinPool,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{LogicalVolumes: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+
+	em := exclusionMap
+	exclusionResults := []knownExclusionMatcherResult{
+		{
+			matcherMap: em, matcher: notInVolumeGroupList,
+			dev:         dataLV,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{VolumeGroups: []string{"data-vg"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInVolumeGroupList,
+			dev:         disk,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{VolumeGroups: []string{"*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInLogicalVolumeList,
+			dev:         logsLV,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{LogicalVolumes: []string{"logs"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInLogicalVolumeList,
+			dev:         dataLV,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{LogicalVolumes: []string{"logs"}}},
+			expectMatch: true, expectErr: false,
+		},
+	}
+	assertAllExclusion(t, exclusionResults)
+}
+
+func TestInPartitionLists(t *testing.T) {
+	linuxFS := "0FC63DAF-8483-4772-8E79-3D69D8477DE4"
+	data := internal.BlockDevice{Type: "part", PartLabel: "lso-data-1", PartType: strings.ToLower(linuxFS), PartUUID: "5c2f7a3e-9d41-4b8e-a0c6-1f2e3d4c5b6a"}
+	dos := internal.BlockDevice{Type: "part", PartType: "0x83", PartUUID: "1a2b3c4d-02"}
+	disk := internal.BlockDevice{Type: "disk"}
+
+	results := []knownMatcherResult{
+		{
+			matcherMap: matcherMap, matcher: inPartLabelList,
+			dev:         data,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartLabels: []string{"lso-data-*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inPartLabelList,
+			dev:         dos,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartLabels: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		// part types are matched case insensitively
+		{
+			matcherMap: matcherMap, matcher: inPartTypeList,
+			dev:         data,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartTypes: []string{linuxFS}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inPartTypeList,
+			dev:         dos,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartTypes: []string{"0X83"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inPartTypeList,
+			dev:         dos,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartTypes: []string{linuxFS}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inPartUUIDList,
+			dev:         data,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartUUIDs: []string{"regex:^5C2F7A3E-"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inPartUUIDList,
+			dev:         disk,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartUUIDs: []string{"*"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: inPartUUIDList,
+			dev:         data,
+			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartUUIDs: []string{"regex:("}}},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAll(t, results)
+
+	em := exclusionMap
+	exclusionResults := []knownExclusionMatcherResult{
+		{
+			matcherMap: em, matcher: notInPartLabelList,
+			dev:         data,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartLabels: []string{"lso-data-1"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInPartTypeList,
+			dev:         data,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartTypes: []string{"0x83"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInPartUUIDList,
+			dev:         dos,
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{PartUUIDs: []string{"1A2B3C4D-*"}}},
+			expectMatch: false, expectErr: false,
+		},
+	}
+	assertAllExclusion(t, exclusionResults)
+}
+
+func TestInUdevProperties(t *testing.T) {
+	matcherMap := matcherMap
+	matcher := inUdevProperties
+	virtio := internal.BlockDevice{UdevProperties: map[string]string{
+		"ID_PATH":     "pci-0000:00:05.0",
+		"ID_MODEL_ID": "0x1af4",
+		"ID_SERIAL":   "0123456789",
+	}}
+	results := []knownMatcherResult{
+		// nil spec or empty map: always pass
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{},
+			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// exact value
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         virtio,
+			spec:        &localv1alpha1.DeviceInclusionSpec{UdevProperties: map[string]string{"ID_MODEL_ID": "0x1af4"}},
+			expectMatch: true, expectErr: false,
+		},
+		// all properties need to match
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         virtio,
+			spec:        &localv1alpha1.DeviceInclusionSpec{UdevProperties: map[string]string{"ID_MODEL_ID": "0x1af4", "ID_PATH": "pci-0000:00:06.0"}},
+			expectMatch: false, expectErr: false,
+		},
+		// glob and regex values
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         virtio,
+			spec:        &localv1alpha1.DeviceInclusionSpec{UdevProperties: map[string]string{"ID_PATH": "pci-0000:00:0?.0", "ID_SERIAL": "regex:^[0-9]+$"}},
+			expectMatch: true, expectErr: false,
+		},
+		// missing property
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         virtio,
+			spec:        &localv1alpha1.DeviceInclusionSpec{UdevProperties: map[string]string{"DM_UUID": "*"}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{},
+			spec:        &localv1alpha1.DeviceInclusionSpec{UdevProperties: map[string]string{"ID_MODEL_ID": "0x1af4"}},
+			expectMatch: false, expectErr: false,
+		},
+		// invalid pattern
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         virtio,
+			spec:        &localv1alpha1.DeviceInclusionSpec{UdevProperties: map[string]string{"ID_SERIAL": "regex:("}},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAll(t, results)
+}
+
 func TestNotInDeviceNameFilter(t *testing.T) {
 	em := exclusionMap
 	matcher := notInDeviceNameFilter
@@ -699,6 +1373,153 @@ func TestNotInDeviceNameFilter(t *testing.T) {
 	assertAllExclusion(t, results)
 }
 
+func TestNotInVendorAndModelList(t *testing.T) {
+	em := exclusionMap
+	results := []knownExclusionMatcherResult{
+		// nil spec or empty list: always pass
+		{
+			matcherMap: em, matcher: notInVendorList,
+			dev:         internal.BlockDevice{Vendor: "ATA"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInModelList,
+			dev:         internal.BlockDevice{Model: "VBOX HARDDISK"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// substring, case insensitive: device excluded
+		{
+			matcherMap: em, matcher: notInVendorList,
+			dev:         internal.BlockDevice{Vendor: "ATA"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{Vendors: []string{"ata"}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInModelList,
+			dev:         internal.BlockDevice{Model: "VBOX HARDDISK"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{Models: []string{"NVMe", "vbox"}},
+			expectMatch: false, expectErr: false,
+		},
+		// no match: device included
+		{
+			matcherMap: em, matcher: notInVendorList,
+			dev:         internal.BlockDevice{Vendor: "ATA"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{Vendors: []string{"IBM"}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInModelList,
+			dev:         internal.BlockDevice{Model: "VBOX HARDDISK"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{Models: []string{"NVMe"}},
+			expectMatch: true, expectErr: false,
+		},
+	}
+	assertAllExclusion(t, results)
+}
+
+func TestNotInSizeRange(t *testing.T) {
+	tenGi := resource.MustParse("10Gi")
+	fiftyGi := resource.MustParse("50Gi")
+	em := exclusionMap
+	matcher := notInSizeRange
+	results := []knownExclusionMatcherResult{
+		// no bounds: always pass, even below the inclusion default min size
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: fmt.Sprintf("%d", 1*Mi)},
+			spec:        &localv1alpha1.DeviceExclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		// within range: device excluded
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: fmt.Sprintf("%d", 20*Gi)},
+			spec:        &localv1alpha1.DeviceExclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			expectMatch: false, expectErr: false,
+		},
+		// outside range: device included
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: fmt.Sprintf("%d", 60*Gi)},
+			spec:        &localv1alpha1.DeviceExclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			expectMatch: true, expectErr: false,
+		},
+		// only max: everything up to max excluded
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: fmt.Sprintf("%d", 1*Mi)},
+			spec:        &localv1alpha1.DeviceExclusionSpec{MaxSize: &tenGi},
+			expectMatch: false, expectErr: false,
+		},
+		// only min: everything from min up excluded
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: fmt.Sprintf("%d", 50*Gi)},
+			spec:        &localv1alpha1.DeviceExclusionSpec{MinSize: &fiftyGi},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: fmt.Sprintf("%d", 20*Gi)},
+			spec:        &localv1alpha1.DeviceExclusionSpec{MinSize: &fiftyGi},
+			expectMatch: true, expectErr: false,
+		},
+		// unparsable size
+		{
+			matcherMap: em, matcher: matcher,
+			dev:         internal.BlockDevice{Size: "big"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{MinSize: &fiftyGi},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAllExclusion(t, results)
+}
+
+func TestNotInSerialAndWWNList(t *testing.T) {
+	em := exclusionMap
+	results := []knownExclusionMatcherResult{
+		{
+			matcherMap: em, matcher: notInSerialList,
+			dev:         internal.BlockDevice{Serial: "Z1X00001"},
+			spec:        nil,
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInSerialList,
+			dev:         internal.BlockDevice{Serial: "Z1X00001"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"regex:^Z1X0000[0-9]$"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInSerialList,
+			dev:         internal.BlockDevice{Serial: "S3EVNX0K123456"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{Serials: []string{"Z1X*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInWWNList,
+			dev:         internal.BlockDevice{WWN: "0x5000c500a1b2c3d4"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{WWNs: []string{"0x5000c500a1b2c3d4"}}},
+			expectMatch: false, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInWWNList,
+			dev:         internal.BlockDevice{WWN: ""},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{WWNs: []string{"*"}}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: em, matcher: notInWWNList,
+			dev:         internal.BlockDevice{WWN: "0x5000c500a1b2c3d4"},
+			spec:        &localv1alpha1.DeviceExclusionSpec{DeviceIdentifierSpec: localv1alpha1.DeviceIdentifierSpec{WWNs: []string{"[invalid"}}},
+			expectMatch: false, expectErr: true,
+		},
+	}
+	assertAllExclusion(t, results)
+}
+
 // a known result for a particular exclusion matcher that can be asserted
 type knownExclusionMatcherResult struct {
 	matcherMap  map[string]func(internal.BlockDevice, *localv1alpha1.DeviceExclusionSpec) (bool, error)
@@ -765,3 +1586,76 @@ func (r *knownMatcherResult) assert(t *testing.T) {
 		assert.False(t, match)
 	}
 }
+
+func TestRelaxedFilterMap(t *testing.T) {
+	results := []struct {
+		filter   string
+		dev      internal.BlockDevice
+		policy   *localv1alpha1.ProvisioningPolicy
+		expected bool
+	}{
+		{filter: notRemovable, dev: internal.BlockDevice{Removable: "1"}, policy: nil, expected: false},
+		{filter: notRemovable, dev: internal.BlockDevice{Removable: "1"}, policy: &localv1alpha1.ProvisioningPolicy{}, expected: false},
+		{filter: notRemovable, dev: internal.BlockDevice{Removable: "1"}, policy: &localv1alpha1.ProvisioningPolicy{AllowRemovableDevices: true}, expected: true},
+		{filter: noFilesystemSignature, dev: internal.BlockDevice{FSType: "xfs"}, policy: nil, expected: false},
+		{filter: noFilesystemSignature, dev: internal.BlockDevice{FSType: "xfs"}, policy: &localv1alpha1.ProvisioningPolicy{AllowRemovableDevices: true}, expected: false},
+		{filter: noFilesystemSignature, dev: internal.BlockDevice{FSType: "xfs"}, policy: &localv1alpha1.ProvisioningPolicy{AllowedFilesystemTypes: []string{"ext4", "XFS"}}, expected: true},
+		{filter: noFilesystemSignature, dev: internal.BlockDevice{FSType: "LVM2_member"}, policy: &localv1alpha1.ProvisioningPolicy{AllowedFilesystemTypes: []string{"ext4", "xfs"}}, expected: false},
+	}
+	for _, result := range results {
+		relaxed, found := relaxedFilterMap[result.filter]
+		assert.True(t, found, "expected to find filter in relaxedFilterMap", result.filter)
+		assert.Equalf(t, result.expected, relaxed(result.dev, result.policy), "filter: %s, dev: %+v, policy: %+v", result.filter, result.dev, result.policy)
+	}
+	// filters that protect against data loss can't be relaxed
+	for _, filter := range []string{notReadOnly, noChildren, canOpenExclusively, noBindMounts, noBiosBootInPartLabel} {
+		_, found := relaxedFilterMap[filter]
+		assert.False(t, found, "filter %s should not be relaxable", filter)
+	}
+}
+
+func TestNoEmptyPartitionTable(t *testing.T) {
+	origGlob := internal.FilePathGlob
+	defer func() {
+		internal.FilePathGlob = origGlob
+	}()
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		switch pattern {
+		case "/sys/block/sda/*":
+			return []string{"/sys/block/sda/sda1", "/sys/block/sda/queue"}, nil
+		case "/sys/block/sdb/*":
+			return []string{"/sys/block/sdb/queue"}, nil
+		}
+		return []string{}, nil
+	}
+
+	matcherMap := DefaultFilterMap
+	matcher := NoEmptyPartitionTable
+	results := []knownMatcherResult{
+		// no partition table
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{KName: "sdb", Type: "disk"},
+			expectMatch: true, expectErr: false,
+		},
+		// partition table with partitions
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{KName: "sda", Type: "disk", PTType: "gpt"},
+			expectMatch: true, expectErr: false,
+		},
+		// partition table without partitions
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{KName: "sdb", Type: "disk", PTType: "gpt"},
+			expectMatch: false, expectErr: false,
+		},
+		// partitions report the partition table of their parent
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{KName: "sda1", Type: "part", PTType: "gpt"},
+			expectMatch: true, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+}
@@ -0,0 +1,88 @@
+package lvset
+
+import (
+	"fmt"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/diskmaker"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// partitionedDisks returns the kernel names of the devices in blockDevices that carry partitions
+// created by the diskmaker. Their partitions are provisioned instead of the disks.
+func partitionedDisks(blockDevices []internal.BlockDevice) sets.Set[string] {
+	disks := sets.New[string]()
+	for _, blockDevice := range blockDevices {
+		if blockDevice.IsLSOPartition() && blockDevice.PKName != "" {
+			disks.Insert(blockDevice.PKName)
+		}
+	}
+	return disks
+}
+
+// partitionDisksOf returns the devices of blockDevices that the partitions created by the diskmaker in devices are on
+func partitionDisksOf(devices []internal.BlockDevice, blockDevices []internal.BlockDevice) []internal.BlockDevice {
+	diskKNames := partitionedDisks(devices)
+	disks := make([]internal.BlockDevice, 0, diskKNames.Len())
+	for _, blockDevice := range blockDevices {
+		if diskKNames.Has(blockDevice.KName) {
+			disks = append(disks, blockDevice)
+		}
+	}
+	return disks
+}
+
+// specDevice returns the device that is matched against the spec of lvset in place of blockDevice.
+// If lvset partitions disks, the partitions created by the diskmaker are matched by the disk they
+// are on, which is looked up in devicesByKName. Returns false if that disk is not one of them.
+func specDevice(lvset *localv1alpha1.LocalVolumeSet, blockDevice internal.BlockDevice, devicesByKName map[string]internal.BlockDevice) (internal.BlockDevice, bool) {
+	if lvset == nil || lvset.Spec.Partitioning == nil || !blockDevice.IsLSOPartition() {
+		return blockDevice, true
+	}
+	disk, found := devicesByKName[blockDevice.PKName]
+	return disk, found
+}
+
+// partitionDisks partitions the whole disks of validDevices according to the partitioning of lvset.
+// Disks that a symlink in symLinkRoot already points to back a PV and are not partitioned, neither are
+// disks with a filesystem that the provisioning policy allows, they are provisioned as they are.
+// It returns the devices that are provisioned as they are, and whether any disk was partitioned,
+// in which case the partitions are provisioned once they show up in a later reconcile.
+func (r *LocalVolumeSetReconciler) partitionDisks(lvset *localv1alpha1.LocalVolumeSet, validDevices []internal.BlockDevice, symLinkRoot string) ([]internal.BlockDevice, bool) {
+	if lvset.Spec.Partitioning == nil {
+		return validDevices, false
+	}
+	devices := make([]internal.BlockDevice, 0, len(validDevices))
+	partitioned := false
+	for _, blockDevice := range validDevices {
+		if !blockDevice.IsPartitionable() {
+			devices = append(devices, blockDevice)
+			continue
+		}
+		if blockDevice.FSType != "" {
+			// admitted by the allowed filesystem types of the provisioning policy, its data is kept
+			klog.InfoS("not partitioning device with an allowed filesystem", "device", blockDevice.Name, "fsType", blockDevice.FSType)
+			devices = append(devices, blockDevice)
+			continue
+		}
+		diskPartitioned, count, size, err := blockDevice.PartitionDisk(*lvset.Spec.Partitioning, symLinkRoot, false)
+		if err != nil {
+			msg := fmt.Sprintf("failed to partition %s: %v", blockDevice.Name, err)
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorPartitioningDisk, msg, blockDevice.KName, corev1.EventTypeWarning))
+			klog.Error(msg)
+			continue
+		}
+		if !diskPartitioned {
+			// the whole disk already backs a PV, keep it in sync
+			devices = append(devices, blockDevice)
+			continue
+		}
+		msg := fmt.Sprintf("created %d partitions of %d bytes", count, size)
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.DiskPartitioned, msg, blockDevice.KName, corev1.EventTypeNormal))
+		partitioned = true
+	}
+	return devices, partitioned
+}
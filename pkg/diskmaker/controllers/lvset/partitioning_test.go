@@ -0,0 +1,77 @@
+package lvset
+
+import (
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/diskmaker"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	testingexec "k8s.io/utils/exec/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetValidDevicesPartitioning(t *testing.T) {
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := matcherMap
+	matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
+		inTypeList: matcherMap[inTypeList],
+	}
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+	}()
+
+	sda := internal.BlockDevice{KName: "sda", Type: "disk"}
+	sda1 := internal.BlockDevice{KName: "sda1", Type: "part", PartLabel: "lso-part-1", PKName: "sda"}
+	sda2 := internal.BlockDevice{KName: "sda2", Type: "part", PartLabel: "lso-part-2", PKName: "sda"}
+	sdb := internal.BlockDevice{KName: "sdb", Type: "disk"}
+	sdc := internal.BlockDevice{KName: "sdc", Type: "disk"}
+	sdc1 := internal.BlockDevice{KName: "sdc1", Type: "part", PartLabel: "data", PKName: "sdc"}
+	// the disk of sdd1 is not a candidate, e.g. because it was claimed by another class
+	sdd1 := internal.BlockDevice{KName: "sdd1", Type: "part", PartLabel: "lso-part-1", PKName: "sdd"}
+	blockDevices := []internal.BlockDevice{sda, sda1, sda2, sdb, sdc, sdc1, sdd1}
+
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			Partitioning: &localv1.Partitioning{Count: ptr.To[int32](2)},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	_, delayedDevices, _, _ := r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	// the partitions are matched by their disk, the partitioned disk itself is not provisioned
+	assert.Equal(t, []internal.BlockDevice{sda1, sda2, sdb, sdc}, delayedDevices)
+	assert.Equal(t, []internal.BlockDevice{sda}, partitionDisksOf(delayedDevices, blockDevices))
+
+	// without partitioning, the partitions are matched on their own
+	lvset.Spec.Partitioning = nil
+	_, delayedDevices, _, _ = r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Equal(t, []internal.BlockDevice{sda, sdb, sdc}, delayedDevices)
+}
+
+func TestPartitionDisksWithSignature(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	defer func() { internal.CmdExecutor = oldExecutor }()
+	// no command must be run, the fake panics if one is
+	internal.CmdExecutor = &testingexec.FakeExec{}
+
+	// sda carries a filesystem the provisioning policy allows, sdb an empty partition table
+	sda := internal.BlockDevice{Name: "sda", KName: "sda", Type: "disk", FSType: "xfs"}
+	sdb := internal.BlockDevice{Name: "sdb", KName: "sdb", Type: "disk", PTType: "gpt"}
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			Partitioning: &localv1.Partitioning{Count: ptr.To[int32](2)},
+			ProvisioningPolicy: &localv1alpha1.ProvisioningPolicy{
+				AllowedFilesystemTypes: []string{"xfs"},
+			},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	devices, partitioned := r.partitionDisks(lvset, []internal.BlockDevice{sda, sdb}, t.TempDir())
+	assert.Equal(t, []internal.BlockDevice{sda}, devices)
+	assert.False(t, partitioned)
+	assert.True(t, r.eventReporter.hasReported(lvset, newDiskEvent(diskmaker.ErrorPartitioningDisk, "", "sdb", corev1.EventTypeWarning)))
+}
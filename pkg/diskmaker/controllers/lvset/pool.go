@@ -0,0 +1,306 @@
+package lvset
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// poolVolumeGroupName returns the volume group that pools the devices of lvset, either to carve
+// logical volumes from or to cache them, or an empty string if lvset provisions its devices as they are
+func poolVolumeGroupName(lvset *localv1alpha1.LocalVolumeSet) string {
+	if lvset == nil || (lvset.Spec.Pool == nil && lvset.Spec.Cache == nil) {
+		return ""
+	}
+	return internal.PoolVolumeGroupName(lvset.Spec.StorageClassName)
+}
+
+// storageClassOf returns the class of lvset that provisions storageClassName, or nil if lvset has none
+func storageClassOf(lvset *localv1alpha1.LocalVolumeSet, storageClassName string) *localv1alpha1.LocalVolumeSet {
+	for _, class := range common.LocalVolumeSetClasses(lvset) {
+		if class.Spec.StorageClassName == storageClassName {
+			return class
+		}
+	}
+	return nil
+}
+
+// isPoolVolume checks whether the device is a logical volume the diskmaker created in the volume group vgName
+func isPoolVolume(blockDevice internal.BlockDevice, vgName string) bool {
+	if blockDevice.Type != internal.LVMDeviceType {
+		return false
+	}
+	deviceVGName, lvName, err := blockDevice.GetLVMNames()
+	if err != nil {
+		klog.ErrorS(err, "could not determine volume group of logical volume", "device", blockDevice.Name)
+		return false
+	}
+	return deviceVGName == vgName && strings.HasPrefix(lvName, internal.PoolLogicalVolumePrefix)
+}
+
+// poolMembers returns the kernel names of the devices that are physical volumes of the volume group vgName
+func poolMembers(vgName string) sets.Set[string] {
+	members := sets.New[string]()
+	if vgName == "" {
+		return members
+	}
+	physicalVolumes, err := internal.ListPhysicalVolumes()
+	if err != nil {
+		// the members carry an LVM signature and are rejected by the filters instead
+		klog.ErrorS(err, "could not list members of volume group", "volumeGroup", vgName)
+		return members
+	}
+	for kname, pv := range physicalVolumes {
+		if pv.VGName == vgName {
+			members.Insert(kname)
+		}
+	}
+	return members
+}
+
+// poolMembersOf returns the devices of blockDevices whose kernel names are in members
+func poolMembersOf(members sets.Set[string], blockDevices []internal.BlockDevice) []internal.BlockDevice {
+	devices := make([]internal.BlockDevice, 0, members.Len())
+	for _, blockDevice := range blockDevices {
+		if members.Has(blockDevice.KName) {
+			devices = append(devices, blockDevice)
+		}
+	}
+	return devices
+}
+
+// growPool adds the devices of validDevices to the volume group of lvset. Logical volumes are provisioned
+// as they are, and so are devices that a symlink in symLinkRoot already points to because they back a PV,
+// and devices with a filesystem that the provisioning policy allows. MaxDeviceCount and MaxCapacityPerNode
+// of lvset limit the devices in the volume group. If lvset caches its devices, only devices that have a
+// counterpart in the volume group or among validDevices are added, see unpairedCacheDevices. It returns
+// the devices to provision and whether any device was added to the volume group.
+func (r *LocalVolumeSetReconciler) growPool(lvset *localv1alpha1.LocalVolumeSet, validDevices, blockDevices []internal.BlockDevice, symLinkRoot string) ([]internal.BlockDevice, bool) {
+	vgName := poolVolumeGroupName(lvset)
+	if vgName == "" {
+		return validDevices, false
+	}
+	devices := make([]internal.BlockDevice, 0, len(validDevices))
+	members, memberCapacity, err := poolUsage(vgName)
+	if err != nil {
+		// the limits can't be enforced, only provision the logical volumes
+		klog.ErrorS(err, "could not list members of volume group", "volumeGroup", vgName)
+		for _, blockDevice := range validDevices {
+			if blockDevice.Type == internal.LVMDeviceType {
+				devices = append(devices, blockDevice)
+			}
+		}
+		return devices, false
+	}
+	memberCount := members.Len()
+	var unpaired sets.Set[string]
+	if lvset.Spec.Cache != nil {
+		unpaired = r.unpairedCacheDevices(lvset, poolMembersOf(members, blockDevices), validDevices)
+	}
+	pooled := false
+	for _, blockDevice := range validDevices {
+		if blockDevice.Type == internal.LVMDeviceType {
+			devices = append(devices, blockDevice)
+			continue
+		}
+		if signature := blockDevice.Signature(); signature != "" {
+			klog.InfoS("not adding device with a signature to volume group", "device", blockDevice.Name, "volumeGroup", vgName, "signature", signature)
+			devices = append(devices, blockDevice)
+			continue
+		}
+		if unpaired.Has(blockDevice.KName) {
+			// waits for a device to cache it or to be cached by it
+			continue
+		}
+		if lvset.Spec.MaxDeviceCount != nil && int32(memberCount) >= *lvset.Spec.MaxDeviceCount {
+			msg := fmt.Sprintf("not adding devices to volume group %s, maximum count of devices reached", vgName)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorMaxCountReached, msg, "", corev1.EventTypeWarning))
+			break
+		}
+		size, err := blockDevice.GetSize()
+		if err != nil {
+			r.reportProvisioningFailure(lvset, blockDevice.KName, err)
+			continue
+		}
+		if lvset.Spec.MaxCapacityPerNode != nil && memberCapacity+size > lvset.Spec.MaxCapacityPerNode.Value() {
+			msg := fmt.Sprintf("not adding device of %d bytes to volume group %s, maximum capacity of %s per node would be exceeded", size, vgName, lvset.Spec.MaxCapacityPerNode.String())
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorMaxCapacityReached, msg, blockDevice.KName, corev1.EventTypeWarning))
+			continue
+		}
+		added, err := blockDevice.WithUnprovisionedDevice(symLinkRoot, func() error {
+			return blockDevice.AddToVolumeGroup(vgName)
+		})
+		if err != nil {
+			msg := fmt.Sprintf("failed to add %s to volume group %s: %v", blockDevice.Name, vgName, err)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorPoolingDevice, msg, blockDevice.KName, corev1.EventTypeWarning))
+			klog.Error(msg)
+			continue
+		}
+		if !added {
+			// the whole device already backs a PV, keep it in sync
+			devices = append(devices, blockDevice)
+			continue
+		}
+		msg := fmt.Sprintf("added device to volume group %s", vgName)
+		r.eventReporter.Report(lvset, newDiskEvent(DevicePooled, msg, blockDevice.KName, corev1.EventTypeNormal))
+		memberCount++
+		memberCapacity += size
+		pooled = true
+	}
+	return devices, pooled
+}
+
+// poolUsage returns the kernel names and the total size in bytes of the physical volumes of the volume group vgName
+func poolUsage(vgName string) (sets.Set[string], int64, error) {
+	physicalVolumes, err := internal.ListPhysicalVolumes()
+	if err != nil {
+		return nil, 0, err
+	}
+	members := sets.New[string]()
+	var capacity int64
+	for kname, pv := range physicalVolumes {
+		if pv.VGName == vgName {
+			members.Insert(kname)
+			capacity += pv.Size
+		}
+	}
+	return members, capacity, nil
+}
+
+// unpairedCacheDevices returns the kernel names of the devices of validDevices that are not added to the
+// volume group of lvset, which caches its devices: Rotational devices while there is no NonRotational device
+// to cache them, and NonRotational devices while there is no Rotational device to cache, among the members
+// of the volume group and the devices of validDevices that can be added to it. Devices whose rotational
+// property is unknown are never added. It reports an event if there is no NonRotational device.
+func (r *LocalVolumeSetReconciler) unpairedCacheDevices(lvset *localv1alpha1.LocalVolumeSet, members, validDevices []internal.BlockDevice) sets.Set[string] {
+	unpaired := sets.New[string]()
+	origins := sets.New[string]()
+	caches := sets.New[string]()
+	for i, blockDevice := range append(slices.Clone(members), validDevices...) {
+		candidate := i >= len(members)
+		if candidate && (blockDevice.Type == internal.LVMDeviceType || blockDevice.Signature() != "") {
+			continue
+		}
+		rotational, err := blockDevice.GetRotational()
+		switch {
+		case err != nil:
+			klog.ErrorS(err, "could not determine whether device is rotational", "device", blockDevice.Name)
+			unpaired.Insert(blockDevice.KName)
+		case rotational:
+			origins.Insert(blockDevice.KName)
+		default:
+			caches.Insert(blockDevice.KName)
+		}
+	}
+	if caches.Len() == 0 && origins.Len() > 0 {
+		msg := fmt.Sprintf("no NonRotational device to cache the Rotational devices of storage class %s", lvset.Spec.StorageClassName)
+		r.eventReporter.Report(lvset, newDiskEvent(ErrorNoCacheDevice, msg, "", corev1.EventTypeWarning))
+		klog.Info(msg)
+		unpaired.Insert(origins.UnsortedList()...)
+	}
+	if origins.Len() == 0 {
+		unpaired.Insert(caches.UnsortedList()...)
+	}
+	return unpaired
+}
+
+// carvePool creates logical volumes of the pool size of lvset in its volume group while they fit,
+// up to the maximum number of volumes. It returns whether any logical volume was created.
+// The logical volumes are provisioned once they show up in a later reconcile.
+func (r *LocalVolumeSetReconciler) carvePool(lvset *localv1alpha1.LocalVolumeSet) bool {
+	if lvset.Spec.Pool == nil {
+		return false
+	}
+	vgName := poolVolumeGroupName(lvset)
+	vg, err := internal.GetVolumeGroup(vgName)
+	if err != nil {
+		klog.ErrorS(err, "could not get volume group", "volumeGroup", vgName)
+		return false
+	}
+	if vg == nil {
+		// no device was added yet
+		return false
+	}
+	size := lvset.Spec.Pool.VolumeSize.Value()
+	if vg.ExtentSize > 0 {
+		// LVM allocates whole extents
+		size = (size + vg.ExtentSize - 1) / vg.ExtentSize * vg.ExtentSize
+	}
+	if size <= 0 {
+		return false
+	}
+
+	// the limit counts the logical volumes that are provisioned as PVs, not the caches or the ones being set up
+	lvNames, err := internal.ListLogicalVolumes(vgName)
+	if err != nil {
+		klog.ErrorS(err, "could not list logical volumes of volume group", "volumeGroup", vgName)
+		return false
+	}
+	poolVolumes := 0
+	for _, lvName := range lvNames {
+		if strings.HasPrefix(lvName, internal.PoolLogicalVolumePrefix) {
+			poolVolumes++
+		}
+	}
+
+	created := false
+	for count := poolVolumes; vg.Free >= size; count++ {
+		if lvset.Spec.Pool.MaxVolumes != nil && count >= int(*lvset.Spec.Pool.MaxVolumes) {
+			break
+		}
+		lvName := internal.PoolLogicalVolumePrefix + rand.String(10)
+		if err := internal.CreateLogicalVolume(vgName, lvName, size); err != nil {
+			msg := fmt.Sprintf("failed to create logical volume in volume group %s: %v", vgName, err)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorCreatingPoolVolume, msg, "", corev1.EventTypeWarning))
+			klog.Error(msg)
+			break
+		}
+		klog.InfoS("created logical volume", "volumeGroup", vgName, "logicalVolume", lvName, "size", size)
+		vg.Free -= size
+		created = true
+	}
+	return created
+}
+
+// removePoolVolume removes the logical volume that the deleted PV was provisioned from, if it was carved
+// from the volume group of its storage class, so that its space is returned to the volume group.
+// The cache of a cached logical volume is removed along with it.
+// It returns whether the symlink of the PV can be removed, i.e. whether the PV was carved from the pool
+// and its logical volume has been removed.
+func (r *LocalVolumeSetReconciler) removePoolVolume(lvset *localv1alpha1.LocalVolumeSet, pv *corev1.PersistentVolume) bool {
+	if pv.Spec.Local == nil {
+		return false
+	}
+	devPath, err := internal.FilePathEvalSymLinks(pv.Spec.Local.Path)
+	if err != nil {
+		// without the device it is not known whether the PV was carved from the pool
+		klog.V(4).InfoS("could not resolve symlink of deleted PV", "pv", pv.Name, "err", err)
+		return false
+	}
+	vgName, lvName, err := internal.GetLogicalVolume(devPath)
+	if err != nil {
+		klog.ErrorS(err, "could not determine logical volume of deleted PV", "pv", pv.Name)
+		return false
+	}
+	if vgName != internal.PoolVolumeGroupName(pv.Spec.StorageClassName) || !strings.HasPrefix(lvName, internal.PoolLogicalVolumePrefix) {
+		// not carved from the pool, the device is provisioned again
+		return false
+	}
+	if err := internal.RemoveLogicalVolume(vgName, lvName); err != nil {
+		msg := fmt.Sprintf("failed to remove logical volume of PV %s: %v", pv.Name, err)
+		r.eventReporter.Report(lvset, newDiskEvent(ErrorRemovingPoolVolume, msg, filepath.Base(devPath), corev1.EventTypeWarning))
+		klog.Error(msg)
+		return false
+	}
+	klog.InfoS("removed logical volume of deleted PV", "pv", pv.Name, "volumeGroup", vgName, "logicalVolume", lvName)
+	return true
+}
@@ -0,0 +1,298 @@
+package lvset
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetValidDevicesPool(t *testing.T) {
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := matcherMap
+	matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
+		inTypeList: matcherMap[inTypeList],
+	}
+	origGlob := internal.FilePathGlob
+	origEval := internal.FilePathEvalSymLinks
+	oldExecutor := internal.CmdExecutor
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+		internal.FilePathGlob = origGlob
+		internal.FilePathEvalSymLinks = origEval
+		internal.CmdExecutor = oldExecutor
+	}()
+
+	// the kernel names don't exist in sysfs, so the device mapper UUIDs are read from the by-id links
+	vgUUID := "Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uN"
+	links := map[string]string{
+		"/dev/disk/by-id/dm-uuid-LVM-" + vgUUID + "aB3dE5fG7hJ9kL1mN3pQ5rS7tU9vW1xY": "/dev/dm-pooltest1",
+		"/dev/disk/by-id/dm-uuid-LVM-" + vgUUID + "cD4eF6gH8iJ0kL2mN4oP6qR8sT0uV2wX": "/dev/dm-pooltest2",
+	}
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		matches := make([]string, 0)
+		for link := range links {
+			if matched, _ := filepath.Match(pattern, link); matched {
+				matches = append(matches, link)
+			}
+		}
+		return matches, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		if target, found := links[path]; found {
+			return target, nil
+		}
+		return path, nil
+	}
+	pvs := func(cmd string, args ...string) utilexec.Cmd {
+		assert.Equal(t, "pvs", cmd)
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) {
+					return []byte("  /dev/sdb:lso-fast:1069547520:0\n  /dev/sdd:other-vg:1069547520:0\n"), nil, nil
+				},
+			},
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{CommandScript: []testingexec.FakeCommandAction{pvs, pvs}}
+
+	// sdb is in the pool of the storage class
+	sdb := internal.BlockDevice{Name: "sdb", KName: "sdb", Type: "disk"}
+	sdc := internal.BlockDevice{Name: "sdc", KName: "sdc", Type: "disk"}
+	// a logical volume of the pool, selected although lvm is not in the device types
+	poolLV := internal.BlockDevice{Name: "lso--fast-vol--abc", KName: "dm-pooltest1", Type: "lvm"}
+	otherLV := internal.BlockDevice{Name: "other--vg-data", KName: "dm-pooltest2", Type: "lvm"}
+	blockDevices := []internal.BlockDevice{sdb, sdc, poolLV, otherLV}
+
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "fast",
+			DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{
+				DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk},
+			},
+			Pool: &localv1alpha1.LocalVolumeSetPool{VolumeSize: resource.MustParse("1Gi")},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	_, delayedDevices, _, _ := r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Equal(t, []internal.BlockDevice{sdc, poolLV}, delayedDevices)
+	assert.Equal(t, []internal.BlockDevice{sdb}, poolMembersOf(poolMembers("lso-fast"), blockDevices))
+}
+
+func TestCarvePool(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	defer func() { internal.CmdExecutor = oldExecutor }()
+
+	var lvcreates [][]string
+	lvcreate := func(cmd string, args ...string) utilexec.Cmd {
+		lvcreates = append(lvcreates, append([]string{cmd}, args...))
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) { return nil, nil, nil },
+			},
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, args ...string) utilexec.Cmd {
+				assert.Equal(t, "vgs", cmd)
+				return &testingexec.FakeCmd{
+					CombinedOutputScript: []testingexec.FakeAction{
+						// 10GiB free in extents of 4MiB
+						func() ([]byte, []byte, error) { return []byte("  10737418240:4194304"), nil, nil },
+					},
+				}
+			},
+			func(cmd string, args ...string) utilexec.Cmd {
+				assert.Equal(t, "lvs", cmd)
+				return &testingexec.FakeCmd{
+					CombinedOutputScript: []testingexec.FakeAction{
+						// one logical volume carved already, a cached one being set up doesn't count
+						func() ([]byte, []byte, error) { return []byte("  vol-abc\n  new-def\n"), nil, nil },
+					},
+				}
+			},
+			lvcreate,
+			lvcreate,
+		},
+	}
+
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "fast",
+			Pool: &localv1alpha1.LocalVolumeSetPool{
+				// rounded up to whole extents
+				VolumeSize: resource.MustParse("3G"),
+				MaxVolumes: ptr.To[int32](3),
+			},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	assert.True(t, r.carvePool(lvset))
+	assert.Len(t, lvcreates, 2)
+	for _, args := range lvcreates {
+		assert.Equal(t, "lvcreate", args[0])
+		assert.Contains(t, args, "3003121664b")
+		assert.Equal(t, "lso-fast", args[len(args)-1])
+	}
+}
+
+func TestGrowPoolLimits(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	defer func() { internal.CmdExecutor = oldExecutor }()
+	// sdb of 1GiB is in the pool already
+	pvs := func(cmd string, args ...string) utilexec.Cmd {
+		assert.Equal(t, "pvs", cmd)
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) { return []byte("  /dev/sdb:lso-fast:1073741824:0\n"), nil, nil },
+			},
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{CommandScript: []testingexec.FakeCommandAction{pvs, pvs}}
+
+	// sdc carries a filesystem the provisioning policy allows, it is provisioned as it is
+	sdc := internal.BlockDevice{Name: "sdc", KName: "sdc", Type: "disk", Size: "1073741824", FSType: "xfs"}
+	sdd := internal.BlockDevice{Name: "sdd", KName: "sdd", Type: "disk", Size: "1073741824"}
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "fast",
+			MaxDeviceCount:   ptr.To[int32](1),
+			Pool:             &localv1alpha1.LocalVolumeSetPool{VolumeSize: resource.MustParse("1Gi")},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	devices, pooled := r.growPool(lvset, []internal.BlockDevice{sdc, sdd}, nil, t.TempDir())
+	assert.Equal(t, []internal.BlockDevice{sdc}, devices)
+	assert.False(t, pooled)
+	assert.True(t, r.eventReporter.hasReported(lvset, newDiskEvent(ErrorMaxCountReached, "", "", corev1.EventTypeWarning)))
+
+	lvset.Spec.MaxDeviceCount = nil
+	lvset.Spec.MaxCapacityPerNode = ptr.To(resource.MustParse("1.5Gi"))
+	devices, pooled = r.growPool(lvset, []internal.BlockDevice{sdd}, nil, t.TempDir())
+	assert.Empty(t, devices)
+	assert.False(t, pooled)
+	assert.True(t, r.eventReporter.hasReported(lvset, newDiskEvent(ErrorMaxCapacityReached, "", "sdd", corev1.EventTypeWarning)))
+}
+
+func TestGrowPoolCache(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	defer func() { internal.CmdExecutor = oldExecutor }()
+	pvs := func(output string) testingexec.FakeCommandAction {
+		return func(cmd string, args ...string) utilexec.Cmd {
+			assert.Equal(t, "pvs", cmd)
+			return &testingexec.FakeCmd{
+				CombinedOutputScript: []testingexec.FakeAction{
+					func() ([]byte, []byte, error) { return []byte(output), nil, nil },
+				},
+			}
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			pvs(""),
+			pvs("  /dev/nvme0n1:lso-archive:3217031168:3217031168\n"),
+		},
+	}
+
+	sda := internal.BlockDevice{Name: "sda", KName: "sda", Type: "disk", Rotational: "1"}
+	sdb := internal.BlockDevice{Name: "sdb", KName: "sdb", Type: "disk", Rotational: "1"}
+	nvme0n1 := internal.BlockDevice{Name: "nvme0n1", KName: "nvme0n1", Type: "disk", Rotational: "0", FSType: "LVM2_member"}
+	nvme1n1 := internal.BlockDevice{Name: "nvme1n1", KName: "nvme1n1", Type: "disk", Rotational: "0"}
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "archive",
+			Cache:            &localv1alpha1.LocalVolumeSetCache{CacheSize: resource.MustParse("1Gi")},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+
+	// the Rotational devices wait for a device to cache them
+	devices, pooled := r.growPool(lvset, []internal.BlockDevice{sda, sdb}, []internal.BlockDevice{sda, sdb}, t.TempDir())
+	assert.Empty(t, devices)
+	assert.False(t, pooled)
+	assert.True(t, r.eventReporter.hasReported(lvset, newDiskEvent(ErrorNoCacheDevice, "", "", corev1.EventTypeWarning)))
+
+	// a NonRotational device is not added while there is nothing to cache
+	devices, pooled = r.growPool(lvset, []internal.BlockDevice{nvme1n1}, []internal.BlockDevice{nvme0n1, nvme1n1}, t.TempDir())
+	assert.Empty(t, devices)
+	assert.False(t, pooled)
+}
+
+func TestRemovePoolVolumeOfClass(t *testing.T) {
+	origEval := internal.FilePathEvalSymLinks
+	oldExecutor := internal.CmdExecutor
+	defer func() {
+		internal.FilePathEvalSymLinks = origEval
+		internal.CmdExecutor = oldExecutor
+	}()
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		if path == "/mnt/local-storage/nvme-fast/dangling" {
+			return "", fmt.Errorf("lstat %s: no such file or directory", path)
+		}
+		return "/dev/dm-5", nil
+	}
+
+	var commands [][]string
+	command := func(output string) testingexec.FakeCommandAction {
+		return func(cmd string, args ...string) utilexec.Cmd {
+			commands = append(commands, append([]string{cmd}, args...))
+			return &testingexec.FakeCmd{
+				CombinedOutputScript: []testingexec.FakeAction{
+					func() ([]byte, []byte, error) { return []byte(output), nil, nil },
+				},
+			}
+		}
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{CommandScript: []testingexec.FakeCommandAction{
+		command("  lso-nvme-fast:vol-abc\n"),
+		command(""),
+		// a logical volume of the volume group that was not carved for a PV
+		command("  lso-nvme-fast:new-abc\n"),
+	}}
+
+	// the pool applies to every class and pools the devices of each class in its own volume group
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "default-sc",
+			Classes:          []localv1alpha1.LocalVolumeSetClass{{StorageClassName: "nvme-fast"}},
+			Pool:             &localv1alpha1.LocalVolumeSetPool{VolumeSize: resource.MustParse("1Gi")},
+		},
+	}
+	assert.Equal(t, "lso-nvme-fast", poolVolumeGroupName(storageClassOf(lvset, "nvme-fast")))
+	assert.Equal(t, "lso-default-sc", poolVolumeGroupName(storageClassOf(lvset, "default-sc")))
+	assert.Nil(t, storageClassOf(lvset, "other-sc"))
+
+	pv := func(name string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName: "nvme-fast",
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					Local: &corev1.LocalVolumeSource{Path: "/mnt/local-storage/nvme-fast/" + name},
+				},
+			},
+		}
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	assert.True(t, r.removePoolVolume(lvset, pv("pooled")))
+	assert.Equal(t, []string{"lvremove", "-y", "lso-nvme-fast/vol-abc"}, commands[1])
+
+	// nothing is known about a device that can't be resolved, its symlink is kept
+	assert.False(t, r.removePoolVolume(lvset, pv("dangling")))
+	assert.Len(t, commands, 2)
+
+	assert.False(t, r.removePoolVolume(lvset, pv("unknown")))
+	assert.Len(t, commands, 3)
+}
@@ -0,0 +1,215 @@
+package lvset
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/common"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/openshift/local-storage-operator/pkg/localmetrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// raidArrayNamePrefix returns the prefix of the names of the md arrays assembled for lvset,
+// or an empty string if lvset provisions its devices as they are
+func raidArrayNamePrefix(lvset *localv1alpha1.LocalVolumeSet) string {
+	if lvset == nil || lvset.Spec.Raid == nil {
+		return ""
+	}
+	return internal.RaidArrayNamePrefix(lvset.Spec.StorageClassName)
+}
+
+// isRaidArrayOf checks whether the device is an md array whose name starts with prefix
+func isRaidArrayOf(blockDevice internal.BlockDevice, prefix string) bool {
+	if !blockDevice.IsRaidArray() {
+		return false
+	}
+	name, err := blockDevice.GetRaidArrayName()
+	if err != nil {
+		klog.ErrorS(err, "could not determine name of md array", "device", blockDevice.Name)
+		return false
+	}
+	return strings.HasPrefix(name, prefix)
+}
+
+// raidMembers returns the kernel names of the members of the md arrays in blockDevices whose names start with prefix
+func raidMembers(blockDevices []internal.BlockDevice, prefix string) sets.Set[string] {
+	members := sets.New[string]()
+	if prefix == "" {
+		return members
+	}
+	for _, blockDevice := range blockDevices {
+		if !isRaidArrayOf(blockDevice, prefix) {
+			continue
+		}
+		arrayMembers, err := blockDevice.GetRaidMembers()
+		if err != nil {
+			// the members carry an md superblock and are rejected by the filters instead
+			klog.ErrorS(err, "could not list members of md array", "device", blockDevice.Name)
+			continue
+		}
+		members.Insert(arrayMembers...)
+	}
+	return members
+}
+
+// raidMembersOf returns the devices of blockDevices whose kernel names are in members
+func raidMembersOf(members sets.Set[string], blockDevices []internal.BlockDevice) []internal.BlockDevice {
+	devices := make([]internal.BlockDevice, 0, members.Len())
+	for _, blockDevice := range blockDevices {
+		if members.Has(blockDevice.KName) {
+			devices = append(devices, blockDevice)
+		}
+	}
+	return devices
+}
+
+// reassembleRaidArrays assembles the md arrays of all classes of lvset that are not running,
+// e.g. because the node was rebooted. It returns whether any array was assembled.
+func (r *LocalVolumeSetReconciler) reassembleRaidArrays(lvset *localv1alpha1.LocalVolumeSet) bool {
+	prefixes := make([]string, 0)
+	for _, class := range common.LocalVolumeSetClasses(lvset) {
+		if prefix := raidArrayNamePrefix(class); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return false
+	}
+	arrays, err := internal.ListRaidArrays()
+	if err != nil {
+		klog.ErrorS(err, "could not scan for md arrays")
+		return false
+	}
+	assembled := false
+	for _, array := range arrays {
+		ours := slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(array.Name, prefix) })
+		if !ours || internal.IsRaidArrayAssembled(array.Name) {
+			continue
+		}
+		if err := internal.AssembleRaidArray(array); err != nil {
+			msg := fmt.Sprintf("failed to assemble md array %s: %v", array.Name, err)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorAssemblingRaidArray, msg, array.Name, corev1.EventTypeWarning))
+			klog.Error(msg)
+			continue
+		}
+		klog.InfoS("assembled md array", "array", array.Name, "uuid", array.UUID)
+		assembled = true
+	}
+	return assembled
+}
+
+// assembleRaidArrays creates md arrays of the level and member count of lvset from the devices of
+// validDevices. Devices of similar size are put into the same array, as the smallest member limits
+// the size of an array. Arrays are provisioned as they are, and so are devices that a symlink in
+// symLinkRoot already points to because they back a PV, and devices with a filesystem that the
+// provisioning policy allows. Devices that don't make up a whole array
+// wait for more devices. It returns the devices to provision and whether any array was created,
+// in which case it is provisioned once it shows up in a later reconcile.
+func (r *LocalVolumeSetReconciler) assembleRaidArrays(lvset *localv1alpha1.LocalVolumeSet, validDevices []internal.BlockDevice, symLinkRoot string) ([]internal.BlockDevice, bool) {
+	prefix := raidArrayNamePrefix(lvset)
+	if prefix == "" {
+		return validDevices, false
+	}
+	devices := make([]internal.BlockDevice, 0, len(validDevices))
+	candidates := make([]internal.BlockDevice, 0, len(validDevices))
+	for _, blockDevice := range validDevices {
+		if blockDevice.IsRaidArray() {
+			devices = append(devices, blockDevice)
+			continue
+		}
+		if signature := blockDevice.Signature(); signature != "" {
+			klog.InfoS("not adding device with a signature to an md array", "device", blockDevice.Name, "signature", signature)
+			devices = append(devices, blockDevice)
+			continue
+		}
+		devPath, err := blockDevice.GetDevPath()
+		if err != nil {
+			klog.ErrorS(err, "could not get path of device", "device", blockDevice.Name)
+			continue
+		}
+		existingSymlinks, err := internal.GetMatchingSymlinksInDirs(devPath, symLinkRoot)
+		if err != nil {
+			klog.ErrorS(err, "error reading existing symlinks for device", "device", blockDevice.Name)
+			continue
+		}
+		if len(existingSymlinks) > 0 {
+			// the whole device already backs a PV, keep it in sync
+			devices = append(devices, blockDevice)
+			continue
+		}
+		candidates = append(candidates, blockDevice)
+	}
+
+	slices.SortStableFunc(candidates, func(a, b internal.BlockDevice) int {
+		aSize, _ := a.GetSize()
+		bSize, _ := b.GetSize()
+		return cmp.Compare(aSize, bSize)
+	})
+	memberCount := int(lvset.Spec.Raid.Members)
+	created := false
+	for len(candidates) >= memberCount {
+		members := candidates[:memberCount]
+		candidates = candidates[memberCount:]
+		name := prefix + rand.String(6)
+		memberNames := make([]string, 0, len(members))
+		for _, member := range members {
+			memberNames = append(memberNames, member.KName)
+		}
+		assembled, err := internal.WithUnprovisionedDevices(members, symLinkRoot, func() error {
+			return internal.CreateRaidArray(name, int32(lvset.Spec.Raid.Level), members)
+		})
+		if err != nil {
+			msg := fmt.Sprintf("failed to create md array %s from %s: %v", name, strings.Join(memberNames, ", "), err)
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorCreatingRaidArray, msg, name, corev1.EventTypeWarning))
+			klog.Error(msg)
+			continue
+		}
+		if !assembled {
+			// a member was provisioned in the meantime, it is synced in the next reconcile
+			continue
+		}
+		msg := fmt.Sprintf("created RAID %d array from %s", lvset.Spec.Raid.Level, strings.Join(memberNames, ", "))
+		r.eventReporter.Report(lvset, newDiskEvent(RaidArrayCreated, msg, name, corev1.EventTypeNormal))
+		klog.InfoS("created md array", "array", name, "level", lvset.Spec.Raid.Level, "members", memberNames)
+		created = true
+	}
+	if len(candidates) > 0 {
+		klog.InfoS("waiting for more devices to create an md array", "storageClass", lvset.Spec.StorageClassName,
+			"devices", len(candidates), "members", memberCount)
+	}
+	return devices, created
+}
+
+// reportDegradedRaidArrays reports the md arrays of lvset in blockDevices that are missing members
+func (r *LocalVolumeSetReconciler) reportDegradedRaidArrays(lvset *localv1alpha1.LocalVolumeSet, blockDevices []internal.BlockDevice) {
+	prefix := raidArrayNamePrefix(lvset)
+	if prefix == "" {
+		return
+	}
+	degradedArrays := 0
+	for _, blockDevice := range blockDevices {
+		if !isRaidArrayOf(blockDevice, prefix) {
+			continue
+		}
+		missing, err := blockDevice.GetRaidDegradedDevices()
+		if err != nil {
+			klog.ErrorS(err, "could not determine whether md array is degraded", "device", blockDevice.Name)
+			continue
+		}
+		if missing == 0 {
+			continue
+		}
+		degradedArrays++
+		msg := fmt.Sprintf("md array is degraded, %d members are missing", missing)
+		r.eventReporter.Report(lvset, newDiskEvent(RaidArrayDegraded, msg, blockDevice.KName, corev1.EventTypeWarning))
+		klog.Warning(msg)
+	}
+	localmetrics.SetLVSDegradedRaidArrayMetric(nodeName, lvset.Spec.StorageClassName, degradedArrays)
+}
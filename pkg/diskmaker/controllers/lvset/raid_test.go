@@ -0,0 +1,115 @@
+package lvset
+
+import (
+	"path/filepath"
+	"testing"
+
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/pkg/internal"
+	"github.com/stretchr/testify/assert"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestGetValidDevicesRaid(t *testing.T) {
+	oldFilterMap := DefaultFilterMap
+	DefaultFilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := matcherMap
+	matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
+		inTypeList: matcherMap[inTypeList],
+	}
+	origGlob := internal.FilePathGlob
+	defer func() {
+		DefaultFilterMap = oldFilterMap
+		matcherMap = oldMatcherMap
+		internal.FilePathGlob = origGlob
+	}()
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		if pattern == "/sys/class/block/md127/slaves/*" {
+			return []string{"/sys/class/block/md127/slaves/sdb", "/sys/class/block/md127/slaves/sdc"}, nil
+		}
+		return nil, nil
+	}
+
+	prefix := internal.RaidArrayNamePrefix("fast")
+	// sdb and sdc are the members of an array of the storage class
+	sdb := internal.BlockDevice{Name: "sdb", KName: "sdb", Type: "disk"}
+	sdc := internal.BlockDevice{Name: "sdc", KName: "sdc", Type: "disk"}
+	sdd := internal.BlockDevice{Name: "sdd", KName: "sdd", Type: "disk"}
+	// the array is selected although raid1 is not in the device types
+	array := internal.BlockDevice{Name: "md127", KName: "md127", Type: "raid1",
+		UdevProperties: map[string]string{internal.UdevMDDevName: prefix + "abcdef"}}
+	otherArray := internal.BlockDevice{Name: "md126", KName: "md126", Type: "raid1",
+		UdevProperties: map[string]string{internal.UdevMDDevName: "data"}}
+	blockDevices := []internal.BlockDevice{sdb, sdc, sdd, array, otherArray}
+
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "fast",
+			DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{
+				DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk},
+			},
+			Raid: &localv1alpha1.LocalVolumeSetRaid{Level: localv1alpha1.Raid1, Members: 2},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	_, delayedDevices, _, _ := r.getValidDevices(lvset, nil, nil, nil, blockDevices)
+	assert.Equal(t, []internal.BlockDevice{sdd, array}, delayedDevices)
+	assert.Equal(t, []internal.BlockDevice{sdb, sdc}, raidMembersOf(raidMembers(blockDevices, prefix), blockDevices))
+}
+
+func TestAssembleRaidArrays(t *testing.T) {
+	oldExecutor := internal.CmdExecutor
+	defer func() { internal.CmdExecutor = oldExecutor }()
+
+	var commands [][]string
+	action := func(cmd string, args ...string) utilexec.Cmd {
+		commands = append(commands, append([]string{cmd}, args...))
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) { return nil, nil, nil },
+			},
+		}
+	}
+	// find for every device, then find for each member and mdadm for every array
+	script := make([]testingexec.FakeCommandAction, 0)
+	for i := 0; i < 5+2*3; i++ {
+		script = append(script, action)
+	}
+	internal.CmdExecutor = &testingexec.FakeExec{CommandScript: script}
+
+	// /dev/null can be opened exclusively by the PV creation lock
+	devices := []internal.BlockDevice{
+		{KName: "null", Type: "disk", Size: "4000"},
+		{KName: "null", Type: "disk", Size: "1000"},
+		{KName: "null", Type: "disk", Size: "3000"},
+		{KName: "null", Type: "disk", Size: "2000"},
+		{KName: "null", Type: "disk", Size: "5000"},
+		// a filesystem the provisioning policy allows, it is provisioned as it is
+		{KName: "null", Type: "disk", Size: "5000", FSType: "xfs"},
+	}
+	lvset := &localv1alpha1.LocalVolumeSet{
+		Spec: localv1alpha1.LocalVolumeSetSpec{
+			StorageClassName: "fast",
+			Raid:             &localv1alpha1.LocalVolumeSetRaid{Level: localv1alpha1.Raid1, Members: 2},
+		},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	provisionedDevices, created := r.assembleRaidArrays(lvset, devices, t.TempDir())
+	assert.True(t, created)
+	// the devices are in the arrays or wait for another device
+	assert.Equal(t, devices[5:], provisionedDevices)
+
+	arrays := make([][]string, 0)
+	for _, command := range commands {
+		if command[0] == "mdadm" {
+			arrays = append(arrays, command)
+		}
+	}
+	assert.Len(t, arrays, 2)
+	for _, args := range arrays {
+		assert.Contains(t, args, "--level=1")
+		assert.Contains(t, args, "--raid-devices=2")
+		assert.Contains(t, args, "--name="+filepath.Base(args[2]))
+	}
+}
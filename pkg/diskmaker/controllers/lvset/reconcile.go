@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
 	"time"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
@@ -59,11 +60,13 @@ type LocalVolumeSetReconciler struct {
 	nodeName      string
 	eventReporter *eventReporter
 	cacheSynced   bool
-	// map from KNAME of device to time when the device was first observed since the process started
+	// map from KNAME and ID of device to time when the device was first observed, persisted in the symlink root
 	deviceAgeMap *ageMap
 	// a cache of existing devices on the node
 	pvLinkCache       *common.LocalVolumeDeviceLinkCache
 	deviceLinkHandler *common.DeviceLinkHandler
+	// volume groups that have no cached logical volumes left behind by a failed create, see buildCaches
+	completeVolumeGroups sets.Set[string]
 
 	// static-provisioner stuff
 	cleanupTracker *provDeleter.CleanupStatusTracker
@@ -71,13 +74,15 @@ type LocalVolumeSetReconciler struct {
 	deleter        *provDeleter.Deleter
 }
 
-func NewLocalVolumeSetReconciler(client client.Client, clientReader client.Reader, scheme *runtime.Scheme, time timeInterface, cleanupTracker *provDeleter.CleanupStatusTracker, rc *provCommon.RuntimeConfig, pvLinkCache *common.LocalVolumeDeviceLinkCache) (*LocalVolumeSetReconciler, error) {
+func NewLocalVolumeSetReconciler(client client.Client, clientReader client.Reader, scheme *runtime.Scheme, symlinkLocation string, time timeInterface, cleanupTracker *provDeleter.CleanupStatusTracker, rc *provCommon.RuntimeConfig, pvLinkCache *common.LocalVolumeDeviceLinkCache) (*LocalVolumeSetReconciler, error) {
 	deleter := provDeleter.NewDeleter(rc, cleanupTracker)
 	eventReporter := newEventReporter(rc.Recorder)
 	deviceLinkHandler, err := common.NewDeviceLinkHandler(client, clientReader, rc.Recorder, pvLinkCache, nodeName)
 	if err != nil {
 		return nil, err
 	}
+	stateFile := filepath.Join(deviceAgeStateDir, deviceAgeStateFile)
+	migrateDeviceAgeState(filepath.Join(symlinkLocation, deviceAgeStateFile), stateFile)
 	lvsReconciler := &LocalVolumeSetReconciler{
 		Client:            client,
 		ClientReader:      clientReader,
@@ -86,10 +91,12 @@ func NewLocalVolumeSetReconciler(client client.Client, clientReader client.Reade
 		pvLinkCache:       pvLinkCache,
 		deviceLinkHandler: deviceLinkHandler,
 		eventReporter:     eventReporter,
-		deviceAgeMap:      newAgeMap(time),
+		deviceAgeMap:      newAgeMap(time, stateFile),
 		cleanupTracker:    cleanupTracker,
 		runtimeConfig:     rc,
 		deleter:           deleter,
+		// a create may have been interrupted before the diskmaker was restarted
+		completeVolumeGroups: sets.New[string](),
 	}
 
 	return lvsReconciler, nil
@@ -103,6 +110,9 @@ func (r *LocalVolumeSetReconciler) WithManager(mgr ctrl.Manager) error {
 		Watches(
 			&corev1.ConfigMap{},
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &localv1.LocalVolume{})).
+		// reconcile all LocalVolumeSets when the blocklist changes, this also keeps it in the cache
+		Watches(&localv1alpha1.LocalVolumeDeviceBlocklist{}, common.EnqueueAllForDeviceBlocklist(mgr.GetClient(), watchNamespace,
+			func() client.ObjectList { return &localv1alpha1.LocalVolumeSetList{} })).
 		// update owned-pv cache used by provisioner/deleter libs and enequeue owning lvset
 		// only the cache is touched by
 		Watches(&corev1.PersistentVolume{}, &handler.Funcs{
@@ -190,6 +200,16 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 		return ctrl.Result{}, nil
 	}
 
+	// from here on lvset is the effective LocalVolumeSet for this node
+	lvset, override, err := common.ApplyNodeOverrides(lvset, r.runtimeConfig.Node)
+	if err != nil {
+		klog.ErrorS(err, "failed to apply node overrides")
+		return ctrl.Result{}, err
+	}
+	if override >= 0 {
+		klog.V(2).InfoS("applying node override", "namespace", request.Namespace, "name", request.Name, "nodeOverride", override)
+	}
+
 	// Delete PV's before creating new ones
 	klog.InfoS("Looking for released PVs to cleanup", "namespace", request.Namespace, "name", request.Name)
 	r.deleter.DeletePVs()
@@ -202,7 +222,11 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 		common.PVOwnerNameLabel:      lvset.Name,
 	}
 	err = common.CleanupSymlinks(r.Client, r.runtimeConfig, ownerLabels,
-		func() bool {
+		func(pv *corev1.PersistentVolume) bool {
+			// the logical volumes of a pool or cache are removed along with their PV
+			if poolVolumeGroupName(storageClassOf(lvset, pv.Spec.StorageClassName)) != "" && r.removePoolVolume(lvset, pv) {
+				return true
+			}
 			// Only delete the symlink if the owner LVSet is deleted.
 			return !lvset.DeletionTimestamp.IsZero()
 		})
@@ -229,23 +253,14 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 	// since deletion timestamp is notset, clear out its metrics
 	localmetrics.RemoveLVSDeletionTimestampMetric(lvset.GetName())
 
-	klog.InfoS("Looking for valid block devices", "namespace", request.Namespace, "name", request.Name)
-	// get associated storageclass
-	storageClassName := lvset.Spec.StorageClassName
-	storageClass := &storagev1.StorageClass{}
-	err = r.Client.Get(ctx, types.NamespacedName{Name: storageClassName}, storageClass)
-	if err != nil {
-		klog.ErrorS(err, "could not get storageclass")
-		return ctrl.Result{}, err
-	}
-
-	// get symlinkdir
-	symLinkConfig, ok := r.runtimeConfig.DiscoveryMap[storageClassName]
-	if !ok {
-		return ctrl.Result{}, fmt.Errorf("could not find storageclass entry %q in provisioner config: %+v", storageClassName, r.runtimeConfig.DiscoveryMap)
+	// md arrays are not assembled again by themselves after a reboot
+	if r.reassembleRaidArrays(lvset) {
+		requeueTime = fastRequeueTime
 	}
-	symLinkDir := symLinkConfig.HostDir
+	// and neither are the volume groups of cached devices
+	r.activateCaches(lvset)
 
+	klog.InfoS("Looking for valid block devices", "namespace", request.Namespace, "name", request.Name)
 	// list block devices
 	blockDevices, badRows, err := internal.ListBlockDevices([]string{})
 	if err != nil {
@@ -259,18 +274,130 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 		klog.Error(msg)
 	}
 
-	// find disks that match lvset filters and matchers
-	validDevices, delayedDevices, rejectedButSpecMatchedDevices := r.getValidDevices(lvset, blockDevices)
+	// forget the devices that are gone, so that they wait again when they come back
+	deviceAgeKeys := sets.New[string]()
+	for _, blockDevice := range blockDevices {
+		deviceAgeKeys.Insert(deviceAgeKey(blockDevice))
+	}
+	r.deviceAgeMap.prune(deviceAgeKeys)
+
+	// compile the device selector expression once for all devices in this reconcile
+	deviceSelector, err := common.CompileDeviceSelector(lvset.Spec.DeviceSelector)
+	if err != nil {
+		msg := fmt.Sprintf("not provisioning devices, %v", err)
+		r.eventReporter.Report(lvset, newDiskEvent(ErrorInvalidDeviceSelector, msg, "", corev1.EventTypeWarning))
+		klog.Error(msg)
+		// a broken expression will not fix itself, look again after requeueTime in case the LocalVolumeSet was updated
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
+	}
+
+	// and the identifier patterns, so that an invalid one is reported instead of just never matching
+	err = common.CompileIdentifierPatterns(lvset)
+	if err != nil {
+		msg := fmt.Sprintf("not provisioning devices, %v", err)
+		r.eventReporter.Report(lvset, newDiskEvent(ErrorInvalidIdentifierPattern, msg, "", corev1.EventTypeWarning))
+		klog.Error(msg)
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
+	}
+
+	// devices the host runs from are never provisioned, don't guess if they can't be determined
+	systemDevices, err := internal.GetSystemDevices()
+	if err != nil {
+		msg := fmt.Sprintf("not provisioning devices, failed to determine system devices: %v", err)
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorListingSystemDevices, msg, "", corev1.EventTypeWarning))
+		klog.Error(msg)
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
+	}
+
+	// devices listed in a LocalVolumeDeviceBlocklist are never touched, fail closed as well
+	blocklist, err := common.LoadDeviceBlocklist(ctx, r.Client)
+	if err != nil {
+		msg := fmt.Sprintf("not provisioning devices, %v", err)
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorLoadingDeviceBlocklist, msg, "", corev1.EventTypeWarning))
+		klog.Error(msg)
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
+	}
 
-	// update metrics for unmatched disks
-	localmetrics.SetLVSUnmatchedDiskMetric(nodeName, storageClassName, len(blockDevices)-len(validDevices))
 	mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// process valid devices
-	for _, blockDevice := range validDevices {
+	// each device is only provisioned for the first class that matches it
+	claimedDevices := sets.New[string]()
+	for _, class := range common.LocalVolumeSetClasses(lvset) {
+		unclaimedDevices := make([]internal.BlockDevice, 0, len(blockDevices))
+		for _, blockDevice := range blockDevices {
+			if !claimedDevices.Has(blockDevice.KName) {
+				unclaimedDevices = append(unclaimedDevices, blockDevice)
+			}
+		}
+		classRequeueTime, specMatchedDevices, err := r.reconcileClass(ctx, class, deviceSelector, systemDevices, blocklist, unclaimedDevices, blockDevices, mountPointMap)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		requeueTime = min(requeueTime, classRequeueTime)
+		for _, blockDevice := range specMatchedDevices {
+			claimedDevices.Insert(blockDevice.KName)
+		}
+	}
+
+	return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
+}
+
+// reconcileClass provisions the devices of candidateDevices that match lvset, which is one of the classes
+// returned by common.LocalVolumeSetClasses, for its StorageClass. It returns when the LocalVolumeSet
+// has to be reconciled again and the devices that matched the spec of lvset.
+func (r *LocalVolumeSetReconciler) reconcileClass(
+	ctx context.Context,
+	lvset *localv1alpha1.LocalVolumeSet,
+	deviceSelector *common.DeviceSelectorProgram,
+	systemDevices sets.Set[string],
+	blocklist *common.DeviceBlocklist,
+	candidateDevices []internal.BlockDevice,
+	blockDevices []internal.BlockDevice,
+	mountPointMap sets.Set[string],
+) (time.Duration, []internal.BlockDevice, error) {
+	requeueTime := defaultRequeueTime
+	// get associated storageclass
+	storageClassName := lvset.Spec.StorageClassName
+	storageClass := &storagev1.StorageClass{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: storageClassName}, storageClass)
+	if err != nil {
+		klog.ErrorS(err, "could not get storageclass")
+		return 0, nil, err
+	}
+
+	// get symlinkdir
+	symLinkConfig, ok := r.runtimeConfig.DiscoveryMap[storageClassName]
+	if !ok {
+		return 0, nil, fmt.Errorf("could not find storageclass entry %q in provisioner config: %+v", storageClassName, r.runtimeConfig.DiscoveryMap)
+	}
+	symLinkDir := symLinkConfig.HostDir
+
+	// find disks that match lvset filters and matchers
+	validDevices, delayedDevices, rejectedButSpecMatchedDevices, blockedDevices := r.getValidDevices(lvset, deviceSelector, systemDevices, blocklist, candidateDevices)
+
+	// update metrics for unmatched and blocked disks
+	localmetrics.SetLVSUnmatchedDiskMetric(nodeName, storageClassName, len(candidateDevices)-len(validDevices))
+	localmetrics.SetBlockedDeviceMetric(nodeName, storageClassName, len(blockedDevices))
+
+	// whole disks are partitioned first, their partitions are provisioned in a later reconcile
+	provisionedDevices, partitioned := r.partitionDisks(lvset, validDevices, filepath.Dir(symLinkDir))
+	// devices are added to the pool first, its logical volumes are provisioned in a later reconcile
+	provisionedDevices, pooled := r.growPool(lvset, provisionedDevices, candidateDevices, filepath.Dir(symLinkDir))
+	carved := r.carvePool(lvset)
+	// the cached logical volumes are provisioned in a later reconcile as well
+	cached := r.buildCaches(lvset, candidateDevices)
+	// devices are assembled into md arrays first, the arrays are provisioned in a later reconcile
+	provisionedDevices, assembled := r.assembleRaidArrays(lvset, provisionedDevices, filepath.Dir(symLinkDir))
+	if partitioned || pooled || carved || cached || assembled {
+		requeueTime = fastRequeueTime
+	}
+	r.reportDegradedRaidArrays(lvset, candidateDevices)
+
+	// process valid devices, in the order they should be claimed
+	for _, blockDevice := range orderDevices(provisionedDevices, lvset.Spec.SelectionStrategy) {
 		existingSymlink, err := common.GetSymlinkedForCurrentSC(symLinkDir, blockDevice.KName)
 		if err != nil {
 			klog.ErrorS(err, "error reading existing symlinks for device",
@@ -289,7 +416,7 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 
 		result, err := r.processNewSymlink(ctx, lvset, blockDevice, blockDevices, *storageClass, mountPointMap, symLinkDir)
 		if err != nil {
-			return ctrl.Result{}, err
+			return 0, nil, err
 		}
 		if result.fastRequeue {
 			requeueTime = fastRequeueTime
@@ -310,7 +437,19 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 	// update metrics for total persistent volumes provisioned
 	localmetrics.SetLVSProvisionedPVMetric(nodeName, storageClassName, totalProvisionedPVs)
 
-	specMatchedDevices := slices.Concat(validDevices, delayedDevices, rejectedButSpecMatchedDevices)
+	specMatchedDevices := slices.Concat(validDevices, delayedDevices, rejectedButSpecMatchedDevices, blockedDevices)
+	if lvset.Spec.Partitioning != nil {
+		// the disks of matched partitions belong to this class as well
+		specMatchedDevices = append(specMatchedDevices, partitionDisksOf(specMatchedDevices, blockDevices)...)
+	}
+	if vgName := poolVolumeGroupName(lvset); vgName != "" {
+		// so are the devices in the pool
+		specMatchedDevices = append(specMatchedDevices, poolMembersOf(poolMembers(vgName), candidateDevices)...)
+	}
+	if raidPrefix := raidArrayNamePrefix(lvset); raidPrefix != "" {
+		// and the members of the md arrays
+		specMatchedDevices = append(specMatchedDevices, raidMembersOf(raidMembers(candidateDevices, raidPrefix), candidateDevices)...)
+	}
 	orphanSymlinkDevices, err := internal.GetOrphanedSymlinks(symLinkDir, specMatchedDevices)
 
 	if err != nil {
@@ -333,11 +472,11 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 	r.processRejectedDevicesForDeviceLinks(ctx, lvset, rejectedButSpecMatchedDevices, symLinkDir, storageClassName)
 
 	// shorten the requeueTime if there are delayed devices
-	if len(delayedDevices) > 1 && requeueTime == defaultRequeueTime {
-		requeueTime = deviceMinAge / 2
+	if len(delayedDevices) > 0 && requeueTime == defaultRequeueTime {
+		requeueTime = max(deviceSettleDuration(lvset)/2, fastRequeueTime)
 	}
 
-	return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
+	return requeueTime, specMatchedDevices, nil
 }
 
 // processRejectedDevicesForDeviceLinks reconciles devices which were rejected for PV creation
@@ -360,12 +499,15 @@ func (r *LocalVolumeSetReconciler) processRejectedDevicesForDeviceLinks(ctx cont
 			}
 			if !errors.As(err, &internal.IDPathNotFoundError{}) {
 				klog.ErrorS(err, "failed to check for existing symlink for device", "volume", blockDevice.Name)
+			} else {
+				r.reportRejectedDevice(lvset, blockDevice)
 			}
 			continue
 		}
 
 		if symlinkPath == "" {
 			klog.V(4).InfoS("skipping processing of rejected device", "volume", blockDevice.Name)
+			r.reportRejectedDevice(lvset, blockDevice)
 			continue
 		}
 
@@ -400,6 +542,24 @@ func (r *LocalVolumeSetReconciler) processRejectedDevicesForDeviceLinks(ctx cont
 	}
 }
 
+// reportRejectedDevice reports a device that matched the LocalVolumeSet but was rejected
+// by the provisioning filters and is not used by a local PV, along with its likely owner.
+func (r *LocalVolumeSetReconciler) reportRejectedDevice(lvset *localv1alpha1.LocalVolumeSet, blockDevice internal.BlockDevice) {
+	event := newDiskEvent(DeviceRejected, "", blockDevice.KName, corev1.EventTypeNormal)
+	// probing is not free, only do it once per device and LocalVolumeSet
+	if r.eventReporter.hasReported(lvset, event) {
+		return
+	}
+	event.Message = "device matched but is not eligible for provisioning"
+	owner, err := blockDevice.GetOwner()
+	if err != nil {
+		klog.ErrorS(err, "failed to detect owner of rejected device", "device", blockDevice.Name)
+	} else if owner != "" {
+		event.Message = fmt.Sprintf("%s, likely owner: %s", event.Message, owner)
+	}
+	r.eventReporter.Report(lvset, event)
+}
+
 func (r *LocalVolumeSetReconciler) syncCaches() error {
 	pvList := &corev1.PersistentVolumeList{}
 	err := r.Client.List(context.TODO(), pvList)
@@ -422,6 +582,7 @@ func matchesDeviceSpec(
 	blockDevice internal.BlockDevice,
 	inclusionSpec *localv1alpha1.DeviceInclusionSpec,
 	exclusionSpec *localv1alpha1.DeviceExclusionSpec,
+	deviceSelector *common.DeviceSelectorProgram,
 ) bool {
 	for name, matcher := range matcherMap {
 		valid, err := matcher(blockDevice, inclusionSpec)
@@ -450,34 +611,116 @@ func matchesDeviceSpec(
 			return false
 		}
 	}
+
+	// the expression runs last so it only sees devices the structured specs accepted
+	valid, err := deviceSelector.Matches(blockDevice)
+	if err != nil {
+		klog.ErrorS(err, "device selector error", "device", blockDevice.Name)
+		return false
+	}
+	if !valid {
+		klog.InfoS("device selector negative", "device", blockDevice.Name)
+		return false
+	}
 	return true
 }
 
-// getValidDevices runs spec matchers/excluders, then provisioning-eligibility
-// filters on the blockDeviceList and returns three lists:
+// getValidDevices runs spec matchers/excluders and the device selector, then provisioning-eligibility
+// filters on the blockDeviceList and returns four lists:
 //   - validDevices: devices that passed all checks and are ready to provision
 //   - delayedDevices: devices that matched spec and passed filters but are
-//     younger than deviceMinAge
+//     younger than the device settle duration of lvset
 //   - rejectedDevices: devices that matched spec but failed provisioning filters
+//   - blockedDevices: devices that matched spec but are listed in the blocklist.
+//     They are not processed any further, not even for LocalVolumeDeviceLinks.
 //
-// The union of all three lists represents all spec-matched devices, which is
-// used for orphan detection.
+// The union of all four lists represents all spec-matched devices, which is
+// used for orphan detection. Devices in systemDevices are in none of them.
 func (r *LocalVolumeSetReconciler) getValidDevices(
 	lvset *localv1alpha1.LocalVolumeSet,
+	deviceSelector *common.DeviceSelectorProgram,
+	systemDevices sets.Set[string],
+	blocklist *common.DeviceBlocklist,
 	blockDevices []internal.BlockDevice,
-) ([]internal.BlockDevice, []internal.BlockDevice, []internal.BlockDevice) {
+) ([]internal.BlockDevice, []internal.BlockDevice, []internal.BlockDevice, []internal.BlockDevice) {
 	validDevices := make([]internal.BlockDevice, 0)
 	delayedDevices := make([]internal.BlockDevice, 0)
 	rejectedDevices := make([]internal.BlockDevice, 0)
+	blockedDevices := make([]internal.BlockDevice, 0)
+	var policy *localv1alpha1.ProvisioningPolicy
+	if lvset != nil {
+		policy = lvset.Spec.ProvisioningPolicy
+	}
+	settleDuration := deviceSettleDuration(lvset)
+	// udevadm settle reports on all devices, run it at most once
+	isUdevSettled := sync.OnceValue(func() bool {
+		settled, err := udevSettled()
+		if err != nil {
+			klog.ErrorS(err, "could not check whether udev has settled")
+			return false
+		}
+		return settled
+	})
+
+	// the logical volumes of the pool are provisioned instead of the devices in the pool
+	vgName := poolVolumeGroupName(lvset)
+	members := poolMembers(vgName)
+	// and so are the md arrays instead of their members
+	raidPrefix := raidArrayNamePrefix(lvset)
+	members = members.Union(raidMembers(blockDevices, raidPrefix))
+
+	// the partitions the diskmaker created are matched by their disk, which itself is not provisioned
+	var devicesByKName map[string]internal.BlockDevice
+	var partitioned sets.Set[string]
+	if lvset != nil && lvset.Spec.Partitioning != nil {
+		devicesByKName = make(map[string]internal.BlockDevice, len(blockDevices))
+		for _, blockDevice := range blockDevices {
+			devicesByKName[blockDevice.KName] = blockDevice
+		}
+		partitioned = partitionedDisks(blockDevices)
+	}
 
 DeviceLoop:
 	for _, blockDevice := range blockDevices {
-		if !matchesDeviceSpec(blockDevice, lvset.Spec.DeviceInclusionSpec, lvset.Spec.DeviceExclusionSpec) {
+		// devices that back the host filesystems, or share a disk or stack with one, are never probed
+		if systemDevices.Has(blockDevice.KName) {
+			klog.V(4).InfoS("ignoring system device", "device", blockDevice.Name)
+			continue DeviceLoop
+		}
+		matchedDevice, found := specDevice(lvset, blockDevice, devicesByKName)
+		poolVolume := (vgName != "" && isPoolVolume(blockDevice, vgName)) || (raidPrefix != "" && isRaidArrayOf(blockDevice, raidPrefix))
+		if !poolVolume && (!found || !matchesDeviceSpec(matchedDevice, lvset.Spec.DeviceInclusionSpec, lvset.Spec.DeviceExclusionSpec, deviceSelector)) {
+			continue DeviceLoop
+		}
+		if partitioned.Has(blockDevice.KName) {
+			klog.V(4).InfoS("provisioning partitions of disk instead of the disk", "device", blockDevice.Name)
+			continue DeviceLoop
+		}
+		if members.Has(blockDevice.KName) {
+			klog.V(4).InfoS("provisioning logical volumes or md arrays instead of the device", "device", blockDevice.Name)
+			continue DeviceLoop
+		}
+
+		blocked, reason, err := blocklist.IsBlocked(matchedDevice)
+		if err == nil && !blocked && matchedDevice.KName != blockDevice.KName {
+			blocked, reason, err = blocklist.IsBlocked(blockDevice)
+		}
+		if err != nil {
+			// can't tell whether the device is blocked, don't touch it
+			klog.ErrorS(err, "blocklist error", "device", blockDevice.Name)
+			blockedDevices = append(blockedDevices, blockDevice)
+			continue DeviceLoop
+		}
+		if blocked {
+			klog.InfoS("ignoring blocked device", "device", blockDevice.Name, "reason", reason)
+			blockedDevices = append(blockedDevices, blockDevice)
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.DeviceBlocked,
+				fmt.Sprintf("device is not provisioned, %s", reason), blockDevice.KName, corev1.EventTypeNormal))
 			continue DeviceLoop
 		}
 
 		// store device in deviceAgeMap
-		r.deviceAgeMap.storeDeviceAge(blockDevice.KName)
+		r.deviceAgeMap.storeDeviceAge(deviceAgeKey(blockDevice))
 
 		// DefaultFilterMap is a map of filters which can filter out devices with a known set of
 		// filters that is hardcoded in LSO. Such as - device in-use, has file system or has children
@@ -492,16 +735,23 @@ DeviceLoop:
 				rejectedDevices = append(rejectedDevices, blockDevice)
 				continue DeviceLoop
 			} else if !valid {
+				if relaxed, found := relaxedFilterMap[name]; found && relaxed(blockDevice, policy) {
+					klog.InfoS("filter negative, relaxed by provisioning policy", "device", blockDevice.Name, "filter", name)
+					continue
+				}
 				klog.InfoS("filter negative", "device", blockDevice.Name, "filter", name)
 				rejectedDevices = append(rejectedDevices, blockDevice)
 				continue DeviceLoop
 			}
 		}
 
-		// check if the device is older than deviceMinAge
-		isOldEnough := r.deviceAgeMap.isOlderThan(blockDevice.KName)
+		// check if the device is older than the settle duration, or udev is done with it
+		isOldEnough := r.deviceAgeMap.isOlderThan(deviceAgeKey(blockDevice), settleDuration)
+		if !isOldEnough && lvset != nil && lvset.Spec.UdevSettle && len(blockDevice.UdevProperties) > 0 {
+			isOldEnough = isUdevSettled()
+		}
 
-		// skip devices younger than deviceMinAge
+		// skip devices younger than the settle duration
 		if !isOldEnough {
 			delayedDevices = append(delayedDevices, blockDevice)
 			if lvset != nil {
@@ -509,7 +759,7 @@ DeviceLoop:
 					lvset,
 					newDiskEvent(
 						DiscoveredNewDevice,
-						fmt.Sprintf("found possible matching disk, waiting %v to claim", deviceMinAge),
+						fmt.Sprintf("found possible matching disk, waiting %v to claim", settleDuration),
 						blockDevice.KName, corev1.EventTypeNormal,
 					),
 				)
@@ -520,18 +770,46 @@ DeviceLoop:
 		klog.InfoS("matched disk", "device", blockDevice.Name)
 		validDevices = append(validDevices, blockDevice)
 	}
-	return validDevices, delayedDevices, rejectedDevices
+	return validDevices, delayedDevices, rejectedDevices, blockedDevices
 }
 
 // getAlreadySymlinked returns:
 // count: number of symlinks in symLinkDir that resolve to one of validDevices
 // noMatch: symlinks that don't match any validDevice
 func getAlreadySymlinked(symLinkDir string, validDevices []internal.BlockDevice) (int, []string, error) {
-	count := 0
+	symlinked, noMatch, err := getSymlinkedDevices(symLinkDir, validDevices)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(symlinked), noMatch, nil
+}
+
+// getAlreadySymlinkedCapacity returns the total size in bytes of the devices
+// that symlinks in symLinkDir resolve to
+func getAlreadySymlinkedCapacity(symLinkDir string, validDevices []internal.BlockDevice) (int64, error) {
+	symlinked, _, err := getSymlinkedDevices(symLinkDir, validDevices)
+	if err != nil {
+		return 0, err
+	}
+	var capacity int64
+	for _, device := range symlinked {
+		size, err := device.GetSize()
+		if err != nil {
+			return 0, err
+		}
+		capacity += size
+	}
+	return capacity, nil
+}
+
+// getSymlinkedDevices returns the device of validDevices that each symlink in symLinkDir resolves to,
+// and the symlinks that don't resolve to any of them
+func getSymlinkedDevices(symLinkDir string, validDevices []internal.BlockDevice) ([]internal.BlockDevice, []string, error) {
+	symlinked := make([]internal.BlockDevice, 0)
 	noMatch := make([]string, 0)
 	paths, err := filepath.Glob(filepath.Join(symLinkDir, "/*"))
 	if err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
 
 PathLoop:
@@ -539,16 +817,16 @@ PathLoop:
 		for _, device := range validDevices {
 			isMatch, err := internal.PathEvalsToDiskLabel(path, device.KName)
 			if err != nil {
-				return 0, nil, err
+				return nil, nil, err
 			}
 			if isMatch {
-				count++
+				symlinked = append(symlinked, device)
 				continue PathLoop
 			}
 		}
 		noMatch = append(noMatch, path)
 	}
-	return count, noMatch, nil
+	return symlinked, noMatch, nil
 }
 
 type processNewSymlinkResult struct {
@@ -578,19 +856,41 @@ func (r *LocalVolumeSetReconciler) processNewSymlink(
 			"blockDevice", blockDevice.Name)
 	}
 
+	// the limits apply to the devices in the volume group of a pool instead of its logical volumes, see growPool
+	limited := !isPoolVolume(blockDevice, poolVolumeGroupName(lvset))
+
 	// validate MaxDeviceCount
 	alreadyProvisionedCount, _, err := getAlreadySymlinked(symLinkDir, blockDevices)
 
-	if err != nil && lvset.Spec.MaxDeviceCount != nil {
+	if err != nil && limited && lvset.Spec.MaxDeviceCount != nil {
 		r.eventReporter.Report(lvset, newDiskEvent(ErrorListingExistingSymlinks, "error determining already provisioned disks", "", corev1.EventTypeWarning))
 		return nil, fmt.Errorf("could not determine how many devices are already provisioned: %w", err)
 	}
-	if lvset.Spec.MaxDeviceCount != nil && int32(alreadyProvisionedCount) >= *lvset.Spec.MaxDeviceCount {
+	if limited && lvset.Spec.MaxDeviceCount != nil && int32(alreadyProvisionedCount) >= *lvset.Spec.MaxDeviceCount {
 		r.eventReporter.Report(lvset, newDiskEvent(ErrorMaxCountReached, "error provisioning maximum count of devices reached", "", corev1.EventTypeWarning))
 		result.maxCountReached = true
 		return result, nil
 	}
 
+	// validate MaxCapacityPerNode, smaller devices that still fit are provisioned
+	if limited && lvset.Spec.MaxCapacityPerNode != nil {
+		alreadyProvisionedCapacity, err := getAlreadySymlinkedCapacity(symLinkDir, blockDevices)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorListingExistingSymlinks, "error determining already provisioned capacity", "", corev1.EventTypeWarning))
+			return nil, fmt.Errorf("could not determine the capacity that is already provisioned: %w", err)
+		}
+		size, err := blockDevice.GetSize()
+		if err != nil {
+			r.reportProvisioningFailure(lvset, blockDevice.KName, err)
+			return result, nil
+		}
+		if alreadyProvisionedCapacity+size > lvset.Spec.MaxCapacityPerNode.Value() {
+			msg := fmt.Sprintf("not provisioning device of %d bytes, maximum capacity of %s per node would be exceeded", size, lvset.Spec.MaxCapacityPerNode.String())
+			r.eventReporter.Report(lvset, newDiskEvent(ErrorMaxCapacityReached, msg, blockDevice.KName, corev1.EventTypeWarning))
+			return result, nil
+		}
+	}
+
 	currentDeviceInfo, found, err := r.pvLinkCache.FindLSOManagedDeviceInfo(symlinkSourcePath, blockDevice)
 	if err != nil {
 		r.reportProvisioningFailure(lvset, blockDevice.KName, err)
@@ -687,9 +987,11 @@ func (r *LocalVolumeSetReconciler) createSymlinkAndSyncPVAndLVDL(
 		Client:                r.Client,
 		ClientReader:          r.ClientReader,
 		SymLinkPath:           symlinkPath,
-		ExtraLabelsForPV:      map[string]string{},
+		ExtraLabelsForPV:      common.DeviceLabelsForPV(obj.Spec.PVLabelPolicy, dev),
 		BlockDevice:           dev,
 		CacheWriter:           r.pvLinkCache,
+		FSType:                obj.Spec.FSType,
+		PVTopology:            obj.Spec.PVTopology,
 	}
 
 	defer unlockFunc()
@@ -749,9 +1051,11 @@ func (r *LocalVolumeSetReconciler) syncExistingPVAndLVDL(
 		Client:                r.Client,
 		ClientReader:          r.ClientReader,
 		SymLinkPath:           symlinkPath,
-		ExtraLabelsForPV:      map[string]string{},
+		ExtraLabelsForPV:      common.DeviceLabelsForPV(obj.Spec.PVLabelPolicy, blockDevice),
 		BlockDevice:           blockDevice,
 		CacheWriter:           r.pvLinkCache,
+		FSType:                obj.Spec.FSType,
+		PVTopology:            obj.Spec.PVTopology,
 	}
 	return common.SyncPVAndLVDL(ctx, syncArgs)
 }
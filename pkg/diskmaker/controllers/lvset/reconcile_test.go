@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -51,6 +52,10 @@ type testContext struct {
 }
 
 func newFakeLocalVolumeSetReconciler(t *testing.T, objs ...runtime.Object) (*LocalVolumeSetReconciler, *testContext) {
+	oldDeviceAgeStateDir := deviceAgeStateDir
+	t.Cleanup(func() { deviceAgeStateDir = oldDeviceAgeStateDir })
+	deviceAgeStateDir = t.TempDir()
+
 	scheme := scheme.Scheme
 
 	err := v1api.AddToScheme(scheme)
@@ -108,6 +113,7 @@ func newFakeLocalVolumeSetReconciler(t *testing.T, objs ...runtime.Object) (*Loc
 		fakeClient,
 		fakeClient,
 		scheme,
+		t.TempDir(),
 		fakeClock,
 		&provDeleter.CleanupStatusTracker{ProcTable: provDeleter.NewProcTable()},
 		runtimeConfig,
@@ -135,14 +141,35 @@ func TestGetAlreadySymlinked(t *testing.T) {
 	assert.ElementsMatch(t, []string{nonMatching, broken}, noMatch)
 }
 
+func TestGetAlreadySymlinkedCapacity(t *testing.T) {
+	tmpDir := diskmakertest.TempDir(t, "already-symlinked-capacity-")
+
+	assert.NoError(t, os.Symlink("/dev/null", filepath.Join(tmpDir, "null")))
+	assert.NoError(t, os.Symlink("/dev/zero", filepath.Join(tmpDir, "zero")))
+	assert.NoError(t, os.Symlink("/dev/full", filepath.Join(tmpDir, "full")))
+
+	capacity, err := getAlreadySymlinkedCapacity(tmpDir, []internal.BlockDevice{
+		{KName: "null", Size: "1024"},
+		{KName: "zero", Size: "2048"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3072), capacity)
+
+	_, err = getAlreadySymlinkedCapacity(tmpDir, []internal.BlockDevice{{KName: "null", Size: "unknown"}})
+	assert.Error(t, err)
+}
+
 func TestProcessNewSymlink(t *testing.T) {
 	reclaimPolicyDelete := corev1.PersistentVolumeReclaimDelete
 	maxCountOne := int32(1)
+	tenGi := resource.MustParse("10Gi")
+	fifteenGi := resource.MustParse("15Gi")
 
 	testCases := []struct {
 		name             string
 		symLinkDir       string
 		maxDeviceCount   *int32
+		maxCapacity      *resource.Quantity
 		existingSymlink  bool
 		existingReleased bool
 		expectError      string
@@ -162,6 +189,18 @@ func TestProcessNewSymlink(t *testing.T) {
 			existingSymlink: true,
 			expectMax:       true,
 		},
+		{
+			name:        "provisions a new pv when it fits in max capacity",
+			symLinkDir:  "sc-test",
+			maxCapacity: &tenGi,
+			expectPV:    true,
+		},
+		{
+			name:            "skips device that would exceed max capacity",
+			symLinkDir:      "sc-test",
+			maxCapacity:     &fifteenGi,
+			existingSymlink: true,
+		},
 		{
 			name:             "requests fast requeue when pv is released",
 			symLinkDir:       "sc-test",
@@ -191,8 +230,9 @@ func TestProcessNewSymlink(t *testing.T) {
 					Namespace: testNamespace,
 				},
 				Spec: v1alphav1api.LocalVolumeSetSpec{
-					StorageClassName: "sc-test",
-					MaxDeviceCount:   tc.maxDeviceCount,
+					StorageClassName:   "sc-test",
+					MaxDeviceCount:     tc.maxDeviceCount,
+					MaxCapacityPerNode: tc.maxCapacity,
 				},
 			}
 			node := &corev1.Node{
@@ -218,6 +258,7 @@ func TestProcessNewSymlink(t *testing.T) {
 			device := internal.BlockDevice{
 				Name:     "null",
 				KName:    "null",
+				Size:     strconv.FormatInt(10*common.GiB, 10),
 				PathByID: fakeIDPath,
 			}
 			targetName := filepath.Base(device.PathByID)
@@ -770,6 +811,7 @@ func TestMatchesDeviceSpec(t *testing.T) {
 		blockDevice   internal.BlockDevice
 		inclusionSpec *v1alphav1api.DeviceInclusionSpec
 		exclusionSpec *v1alphav1api.DeviceExclusionSpec
+		selector      *v1alphav1api.DeviceSelector
 		expectMatch   bool
 	}{
 		{
@@ -804,11 +846,34 @@ func TestMatchesDeviceSpec(t *testing.T) {
 			exclusionSpec: &v1alphav1api.DeviceExclusionSpec{DeviceNameFilter: []string{"nvme*"}},
 			expectMatch:   true,
 		},
+		{
+			name:          "matches device accepted by device selector",
+			blockDevice:   internal.BlockDevice{KName: "nvme0n1", Name: "nvme0n1", Size: hundredGi, Type: "disk", Rotational: "0"},
+			inclusionSpec: &v1alphav1api.DeviceInclusionSpec{MinSize: &minSize},
+			selector:      &v1alphav1api.DeviceSelector{Expression: `!device.rotational && device.size > quantity("50Gi")`},
+			expectMatch:   true,
+		},
+		{
+			name:          "rejects device refused by device selector",
+			blockDevice:   internal.BlockDevice{KName: "sda", Name: "sda", Size: tenGi, Type: "disk", Rotational: "1"},
+			inclusionSpec: &v1alphav1api.DeviceInclusionSpec{MinSize: &minSize},
+			selector:      &v1alphav1api.DeviceSelector{Expression: `!device.rotational && device.size > quantity("50Gi")`},
+			expectMatch:   false,
+		},
+		{
+			name:          "device selector does not override inclusion spec",
+			blockDevice:   internal.BlockDevice{KName: "sdb", Name: "sdb", Size: hundredGi, Type: "disk", Rotational: "1"},
+			inclusionSpec: &v1alphav1api.DeviceInclusionSpec{MinSize: &minSize, MaxSize: &maxSize},
+			selector:      &v1alphav1api.DeviceSelector{Expression: `true`},
+			expectMatch:   false,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := matchesDeviceSpec(tc.blockDevice, tc.inclusionSpec, tc.exclusionSpec)
+			selector, err := common.CompileDeviceSelector(tc.selector)
+			assert.NoError(t, err)
+			got := matchesDeviceSpec(tc.blockDevice, tc.inclusionSpec, tc.exclusionSpec, selector)
 			assert.Equal(t, tc.expectMatch, got)
 		})
 	}
@@ -185,8 +185,8 @@ func getDiscoverdDevices(blockDevices []internal.BlockDevice) []v1alpha1.Discove
 		}
 		discoveredDevice := v1alpha1.DiscoveredDevice{
 			Path:     path,
-			Model:    blockDevice.Model,
-			Vendor:   blockDevice.Vendor,
+			Model:    blockDevice.GetModel(),
+			Vendor:   blockDevice.GetVendor(),
 			FSType:   blockDevice.FSType,
 			Serial:   blockDevice.Serial,
 			Type:     parseDeviceType(blockDevice.Type),
@@ -195,6 +195,13 @@ func getDiscoverdDevices(blockDevices []internal.BlockDevice) []v1alpha1.Discove
 			Property: parseDeviceProperty(blockDevice.Rotational),
 			Status:   getDeviceStatus(blockDevice),
 		}
+		if discoveredDevice.Status.State == v1alpha1.NotAvailable {
+			owner, err := blockDevice.GetOwner()
+			if err != nil {
+				klog.Warningf("failed to detect the owner of the device %q. Error %v", blockDevice.Name, err)
+			}
+			discoveredDevice.Owner = owner
+		}
 		discoveredDevices = append(discoveredDevices, discoveredDevice)
 	}
 
@@ -265,6 +272,17 @@ func getDeviceStatus(dev internal.BlockDevice) v1alpha1.DeviceStatus {
 		return status
 	}
 
+	noEmptyPartitionTable, err := lvset.DefaultFilterMap[lvset.NoEmptyPartitionTable](dev, nil)
+	if err != nil {
+		status.State = v1alpha1.Unknown
+		return status
+	}
+	if !noEmptyPartitionTable {
+		klog.Infof("device %q with an empty %q partition table is not available", dev.Name, dev.PTType)
+		status.State = v1alpha1.NotAvailable
+		return status
+	}
+
 	canOpen, err := lvset.DefaultFilterMap["canOpenExclusively"](dev, nil)
 	if err != nil {
 		status.State = v1alpha1.Unknown
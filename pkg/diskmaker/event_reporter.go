@@ -24,6 +24,14 @@ const (
 
 	FailedLVDLProcessing = "FailedLVDLProcessing"
 
+	ErrorListingSystemDevices = "ErrorListingSystemDevices"
+
+	DeviceBlocked               = "DeviceBlocked"
+	ErrorLoadingDeviceBlocklist = "ErrorLoadingDeviceBlocklist"
+
+	DiskPartitioned       = "DiskPartitioned"
+	ErrorPartitioningDisk = "ErrorPartitioningDisk"
+
 	// LocalVolumeDiscovery events
 	ErrorCreatingDiscoveryResultObject = "ErrorCreatingDiscoveryResultObject"
 	ErrorUpdatingDiscoveryResultObject = "ErrorUpdatingDiscoveryResultObject"
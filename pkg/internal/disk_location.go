@@ -1,5 +1,9 @@
 package internal
 
+import (
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+)
+
 // DiskLocation stores all tracked paths/details for a matched disk.
 type DiskLocation struct {
 	// DiskNamePath stores full device name path - "/dev/sda"
@@ -10,6 +14,13 @@ type DiskLocation struct {
 	BlockDevice      BlockDevice
 	ForceWipe        bool
 
+	// settings overriding those of the storage class for this disk, empty when not overridden
+	FSType        string
+	VolumeMode    string
+	PVLabels      map[string]string
+	PVAnnotations map[string]string
+	ClaimRef      *localv1.PVClaimRef
+
 	// provisioning related fields set for later
 
 	// SymlinkPath represents path in /mnt/local-storage directory
@@ -27,10 +27,18 @@ var (
 const (
 	// StateSuspended is a possible value of BlockDevice.State
 	StateSuspended = "suspended"
+	// partitionDeviceType is the lsblk type of partitions
+	partitionDeviceType = "part"
 	// DiskByIDDir is the path for symlinks to the device by id.
 	DiskByIDDir = "/dev/disk/by-id/"
+	// DiskByPathDir is the path for symlinks to the device by its hardware path.
+	DiskByPathDir = "/dev/disk/by-path/"
 	// DiskDMDir is the path for symlinks of device mapper disks (e.g. mpath)
 	DiskDMDir = "/dev/mapper/"
+	// DiskByPartUUIDDir is the path for symlinks to partitions by their partition UUID.
+	DiskByPartUUIDDir = "/dev/disk/by-partuuid/"
+	// DiskByPartLabelDir is the path for symlinks to partitions by their partition label.
+	DiskByPartLabelDir = "/dev/disk/by-partlabel/"
 )
 
 var (
@@ -75,7 +83,26 @@ type BlockDevice struct {
 	Removable  string `json:"rm,omitempty"`
 	PathByID   string `json:"pathByID,omitempty"`
 	Serial     string `json:"serial,omitempty"`
+	WWN        string `json:"wwn,omitempty"`
 	PartLabel  string `json:"partLabel,omitempty"`
+	// PartType is the partition type, a GUID for GPT partitions or a hex code such as 0x83 for DOS partitions
+	PartType string `json:"partType,omitempty"`
+	PartUUID string `json:"partUUID,omitempty"`
+	// Transport is only reported for whole devices, e.g. nvme, sata, sas, usb, iscsi
+	Transport          string `json:"tran,omitempty"`
+	LogicalSectorSize  string `json:"log_sec,omitempty"`
+	PhysicalSectorSize string `json:"phy_sec,omitempty"`
+	// Zoned is one of none, host-aware or host-managed
+	Zoned      string `json:"zoned,omitempty"`
+	DiscardMax string `json:"disc_max,omitempty"`
+	MajMin     string `json:"maj_min,omitempty"`
+	// PTType is the partition table type, e.g. gpt or dos.
+	// Partitions report the partition table type of their parent.
+	PTType string `json:"pttype,omitempty"`
+	// PKName is the kernel name of the parent device, e.g. the disk of a partition
+	PKName string `json:"pkname,omitempty"`
+	// UdevProperties are read from the udev database, not from lsblk
+	UdevProperties map[string]string `json:"udevProperties,omitempty"`
 }
 
 // IDPathNotFoundError indicates that a symlink to the device was not found in /dev/disk/by-id/
@@ -125,6 +152,39 @@ func (b BlockDevice) GetSize() (int64, error) {
 	return v, err
 }
 
+// GetLogicalSectorSize as int64
+func (b BlockDevice) GetLogicalSectorSize() (int64, error) {
+	v, err := strconv.ParseInt(b.LogicalSectorSize, 10, 64)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse logical sector size property %q as int64", b.LogicalSectorSize)
+	}
+	return v, err
+}
+
+// GetDiscardMax as int64. An empty value is treated as no discard support.
+func (b BlockDevice) GetDiscardMax() (int64, error) {
+	if b.DiscardMax == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(b.DiscardMax, 10, 64)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse discard max property %q as int64", b.DiscardMax)
+	}
+	return v, err
+}
+
+// Signature describes the filesystem or partition table signature of the device,
+// or returns an empty string if it has none
+func (b BlockDevice) Signature() string {
+	if b.FSType != "" {
+		return "filesystem signature " + b.FSType
+	}
+	if b.PTType != "" {
+		return "partition table " + b.PTType
+	}
+	return ""
+}
+
 // HasChildren check on BlockDevice
 func (b BlockDevice) HasChildren() (bool, error) {
 	sysDevDir := filepath.Join("/sys/block/", b.KName, "/*")
@@ -183,19 +243,14 @@ func (b BlockDevice) GetDevPath() (path string, err error) {
 func (b *BlockDevice) GetPathByID() (string, error) {
 
 	// return if previously populated value is valid
-	if len(b.PathByID) > 0 && strings.HasPrefix(b.PathByID, DiskByIDDir) {
+	if len(b.PathByID) > 0 && IsStableDevicePath(b.PathByID) {
 		evalsCorrectly, err := PathEvalsToDiskLabel(b.PathByID, b.KName)
 		if err == nil && evalsCorrectly {
 			return b.PathByID, nil
 		}
 	}
 	b.PathByID = ""
-	allDisks, err := FilePathGlob(filepath.Join(DiskByIDDir, "/*"))
-	if err != nil {
-		return "", fmt.Errorf("error listing files in %s: %v", DiskByIDDir, err)
-	}
-
-	diskPathID, err := b.findDeviceInSortedSymlink(allDisks)
+	diskPathID, err := b.findPreferredPath()
 	if err != nil {
 		return "", err
 	}
@@ -214,11 +269,7 @@ func (b *BlockDevice) GetPathByID() (string, error) {
 }
 
 func (b *BlockDevice) GetUncachedPathID() (string, error) {
-	allDisks, err := FilePathGlob(filepath.Join(DiskByIDDir, "/*"))
-	if err != nil {
-		return "", fmt.Errorf("error listing files in %s: %v", DiskByIDDir, err)
-	}
-	diskPathID, err := b.findDeviceInSortedSymlink(allDisks)
+	diskPathID, err := b.findPreferredPath()
 	if err != nil {
 		return "", err
 	}
@@ -231,10 +282,132 @@ func (b *BlockDevice) GetUncachedPathID() (string, error) {
 	return "", IDPathNotFoundError{DeviceName: b.KName}
 }
 
+// findPreferredPath returns the preferred stable path of the device, or an empty string if it
+// has none. LVM logical volumes prefer their /dev/mapper link, which is named after the volume
+// group and the logical volume, md arrays their /dev/disk/by-id/md-uuid-* link, all other
+// devices their preferred /dev/disk/by-id link.
+// Partitions without a /dev/disk/by-id link fall back to their /dev/disk/by-partuuid link
+// and then, if their label is unique on the node, to their /dev/disk/by-partlabel link.
+func (b *BlockDevice) findPreferredPath() (string, error) {
+	if b.Type == LVMDeviceType {
+		dmPath, err := b.GetDMPath()
+		if err != nil || dmPath != "" {
+			return dmPath, err
+		}
+	}
+	if b.IsRaidArray() {
+		uuidLinks, err := FilePathGlob(filepath.Join(DiskByIDDir, mdUUIDLinkPrefix+"*"))
+		if err != nil {
+			return "", fmt.Errorf("error listing files in %s: %v", DiskByIDDir, err)
+		}
+		for _, link := range uuidLinks {
+			isMatch, err := PathEvalsToDiskLabel(link, b.KName)
+			if err != nil {
+				return "", err
+			}
+			if isMatch {
+				return link, nil
+			}
+		}
+	}
+	allDisks, err := FilePathGlob(filepath.Join(DiskByIDDir, "/*"))
+	if err != nil {
+		return "", fmt.Errorf("error listing files in %s: %v", DiskByIDDir, err)
+	}
+	diskPathID, err := b.findDeviceInSortedSymlink(allDisks)
+	if err != nil || diskPathID != "" || b.Type != partitionDeviceType {
+		return diskPathID, err
+	}
+	links, err := b.getValidSymlinksInDir(DiskByPartUUIDDir)
+	if err != nil {
+		return "", fmt.Errorf("error listing files in %s: %v", DiskByPartUUIDDir, err)
+	}
+	if len(links) > 0 {
+		return links[0], nil
+	}
+	unique, err := b.hasUniquePartLabel()
+	if err != nil || !unique {
+		return "", err
+	}
+	links, err = b.getValidSymlinksInDir(DiskByPartLabelDir)
+	if err != nil {
+		return "", fmt.Errorf("error listing files in %s: %v", DiskByPartLabelDir, err)
+	}
+	if len(links) > 0 {
+		return links[0], nil
+	}
+	return "", nil
+}
+
+// hasUniquePartLabel checks whether the partition has a label that no other partition on the node has.
+// udev points the /dev/disk/by-partlabel link of a label that is used more than once at any of its
+// partitions, so that link is only stable for unique labels.
+func (b *BlockDevice) hasUniquePartLabel() (bool, error) {
+	partLabel, err := readPartName(filepath.Join(sysClassBlockDir, b.KName, "uevent"))
+	if err != nil || partLabel == "" {
+		return false, err
+	}
+	uevents, err := FilePathGlob(filepath.Join(sysClassBlockDir, "*", "uevent"))
+	if err != nil {
+		return false, fmt.Errorf("error listing files in %s: %v", sysClassBlockDir, err)
+	}
+	count := 0
+	for _, uevent := range uevents {
+		name, err := readPartName(uevent)
+		if err != nil {
+			return false, err
+		}
+		if name == partLabel {
+			count++
+		}
+	}
+	return count == 1, nil
+}
+
+// readPartName returns the PARTNAME, i.e. the partition label, of a sysfs uevent file,
+// or an empty string if the device has none
+func readPartName(uevent string) (string, error) {
+	data, err := os.ReadFile(uevent)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", uevent, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, found := strings.CutPrefix(line, "PARTNAME="); found {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// IsStableDevicePath checks whether path is a link that keeps pointing to the same device
+// across reboots, i.e. a /dev/disk/by-id, /dev/mapper, /dev/disk/by-partuuid or
+// /dev/disk/by-partlabel link
+func IsStableDevicePath(path string) bool {
+	for _, dir := range []string{DiskByIDDir, DiskDMDir, DiskByPartUUIDDir, DiskByPartLabelDir} {
+		if strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetValidByIDSymlinks returns all /dev/disk/by-id/ symlinks that resolve to
 // the same underlying device as this BlockDevice (matched by KName).
 func (b *BlockDevice) GetValidByIDSymlinks() ([]string, error) {
-	paths, err := FilePathGlob(DiskByIDDir + "*")
+	return b.getValidSymlinksInDir(DiskByIDDir)
+}
+
+// GetValidByPathSymlinks returns all /dev/disk/by-path/ symlinks that resolve to
+// the same underlying device as this BlockDevice (matched by KName).
+func (b *BlockDevice) GetValidByPathSymlinks() ([]string, error) {
+	return b.getValidSymlinksInDir(DiskByPathDir)
+}
+
+func (b *BlockDevice) getValidSymlinksInDir(dir string) ([]string, error) {
+	paths, err := FilePathGlob(dir + "*")
 	if err != nil {
 		return nil, err
 	}
@@ -334,7 +507,7 @@ func ListBlockDevices(devices []string) ([]BlockDevice, []string, error) {
 		return []BlockDevice{}, []string{}, errors.Wrap(err, "failed to list block devices")
 	}
 
-	columns := "NAME,ROTA,TYPE,SIZE,MODEL,VENDOR,RO,RM,STATE,KNAME,SERIAL,PARTLABEL"
+	columns := "NAME,ROTA,TYPE,SIZE,MODEL,VENDOR,RO,RM,STATE,KNAME,SERIAL,WWN,PARTLABEL,TRAN,LOG-SEC,PHY-SEC,ZONED,DISC-MAX,MAJ:MIN,PTTYPE,PARTTYPE,PARTUUID,PKNAME"
 	args := []string{"--pairs", "-b", "-o", columns}
 	cmd := CmdExecutor.Command("lsblk", args...)
 	klog.Infof("Executing command: %#v", cmd)
@@ -345,6 +518,9 @@ func ListBlockDevices(devices []string) ([]BlockDevice, []string, error) {
 	badRows := make([]string, 0)
 	// convert to json and then Marshal.
 	outputMapList := make([]map[string]interface{}, 0)
+	// device mapper devices, e.g. LVM logical volumes, are listed once below each device
+	// they are built from, only the first row of every kernel name is used
+	seenKNames := sets.New[string]()
 	rowList := strings.Split(output, "\n")
 	for _, row := range rowList {
 		if len(strings.Trim(row, " ")) == 0 {
@@ -354,6 +530,11 @@ func ListBlockDevices(devices []string) ([]BlockDevice, []string, error) {
 		if outputMap == nil {
 			badRows = append(badRows, row)
 			klog.Warningf("failed to parse the lsblk rows. Bad rows: %+v", row)
+		} else if kname, _ := outputMap["kname"].(string); kname != "" {
+			if seenKNames.Has(kname) {
+				continue
+			}
+			seenKNames.Insert(kname)
 		}
 		outputMapList = append(outputMapList, outputMap)
 	}
@@ -372,6 +553,13 @@ func ListBlockDevices(devices []string) ([]BlockDevice, []string, error) {
 		return []BlockDevice{}, badRows, err
 	}
 
+	for i := range blockDevices {
+		// udev properties are best effort, devices without a database entry are still usable
+		if err := blockDevices[i].LoadUdevProperties(); err != nil {
+			klog.V(4).InfoS("could not load udev properties", "device", blockDevices[i].KName, "err", err)
+		}
+	}
+
 	return blockDevices, badRows, nil
 }
 
@@ -384,7 +572,9 @@ func parseLSBLKRow(row string, deviceFSMap map[string]string) map[string]any {
 		if len(keyValueList) != 2 {
 			continue
 		}
-		key := strings.ToLower(keyValueList[0])
+		// newer lsblk versions print shell compatible column names, e.g. MAJ_MIN and LOG_SEC
+		// instead of MAJ:MIN and LOG-SEC, accept both
+		key := strings.NewReplacer(":", "_", "-", "_").Replace(strings.ToLower(keyValueList[0]))
 		value := strings.Replace(keyValueList[1], `"`, "", -1)
 		outputMap[key] = strings.TrimSpace(value)
 	}
@@ -398,9 +588,12 @@ func parseLSBLKRow(row string, deviceFSMap map[string]string) map[string]any {
 	if len(strings.Trim(name, " ")) == 0 {
 		return nil
 	}
-	// Update device filesystem using `blkid`
+	// Update device filesystem using `blkid`, which reports device mapper devices
+	// such as LVM logical volumes by their /dev/mapper path
 	if fs, ok := deviceFSMap[fmt.Sprintf("/dev/%s", name)]; ok {
 		outputMap["fsType"] = fs
+	} else if fs, ok := deviceFSMap[DiskDMDir+name]; ok {
+		outputMap["fsType"] = fs
 	}
 
 	return outputMap
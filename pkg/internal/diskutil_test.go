@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,14 +13,25 @@ import (
 )
 
 const (
-	lsblkOutput1 = `NAME="sda" KNAME="sda" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="0" STATE="running" SERIAL="" PARTLABEL=""
-NAME="sda1" KNAME="sda1" ROTA="1" TYPE="part" SIZE="62913494528" MODEL="" VENDOR="" RO="0" RM="0" STATE="" SERIAL="" PARTLABEL="BIOS-BOOT"
+	lsblkOutput1 = `NAME="sda" KNAME="sda" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="0" STATE="running" SERIAL="" WWN="0x5000c500a1b2c3d4" PARTLABEL="" TRAN="sata" LOG-SEC="512" PHY-SEC="4096" ZONED="none" DISC-MAX="0" MAJ:MIN="8:0"
+NAME="sda1" KNAME="sda1" ROTA="1" TYPE="part" SIZE="62913494528" MODEL="" VENDOR="" RO="0" RM="0" STATE="" SERIAL="" PARTLABEL="BIOS-BOOT" TRAN="" LOG-SEC="512" PHY-SEC="4096" ZONED="none" DISC-MAX="0" PARTTYPE="21686148-6449-6e6f-744e-656564454649" PARTUUID="5c2f7a3e-9d41-4b8e-a0c6-1f2e3d4c5b6a"
 `
 	lsblkOutput2 = `NAME="sdc" KNAME="sdc" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="1" STATE="running" SERIAL=""
 NAME="sdc3" KNAME="sdc3" ROTA="1" TYPE="part" SIZE="62913494528" MODEL="" VENDOR="" RO="0" RM="1" STATE="" SERIAL=""
 `
 	blkIDOutput1 = `/dev/sdc: TYPE="ext4"
 /dev/sdc3: TYPE="ext2"
+`
+	// the logical volume data-lv1 spans both PVs and is listed below each of them
+	lsblkOutputLVM = `NAME="sdd" KNAME="sdd" ROTA="0" TYPE="disk" SIZE="10737418240" MODEL="" VENDOR="" RO="0" RM="0" STATE="running" SERIAL=""
+NAME="data-lv1" KNAME="dm-1" ROTA="0" TYPE="lvm" SIZE="16106127360" MODEL="" VENDOR="" RO="0" RM="0" STATE="running" SERIAL=""
+NAME="sde" KNAME="sde" ROTA="0" TYPE="disk" SIZE="10737418240" MODEL="" VENDOR="" RO="0" RM="0" STATE="running" SERIAL=""
+NAME="data-lv1" KNAME="dm-1" ROTA="0" TYPE="lvm" SIZE="16106127360" MODEL="" VENDOR="" RO="0" RM="0" STATE="running" SERIAL=""
+NAME="data-lv2" KNAME="dm-2" ROTA="0" TYPE="lvm" SIZE="4294967296" MODEL="" VENDOR="" RO="0" RM="0" STATE="running" SERIAL=""
+`
+	blkIDOutputLVM = `/dev/sdd: TYPE="LVM2_member"
+/dev/sde: TYPE="LVM2_member"
+/dev/mapper/data-lv2: TYPE="xfs"
 `
 )
 
@@ -60,33 +72,46 @@ func TestListBlockDevices(t *testing.T) {
 			totalBadRows:      0,
 			expected: []BlockDevice{
 				{
-					Name:       "sda",
-					FSType:     "",
-					Type:       "disk",
-					Size:       "62914560000",
-					Model:      "VBOX HARDDISK",
-					Vendor:     "ATA",
-					Serial:     "",
-					Rotational: "1",
-					ReadOnly:   "0",
-					Removable:  "0",
-					State:      "running",
-					PartLabel:  "",
+					Name:               "sda",
+					FSType:             "",
+					Type:               "disk",
+					Size:               "62914560000",
+					Model:              "VBOX HARDDISK",
+					Vendor:             "ATA",
+					Serial:             "",
+					Rotational:         "1",
+					ReadOnly:           "0",
+					Removable:          "0",
+					State:              "running",
+					PartLabel:          "",
+					WWN:                "0x5000c500a1b2c3d4",
+					Transport:          "sata",
+					LogicalSectorSize:  "512",
+					PhysicalSectorSize: "4096",
+					Zoned:              "none",
+					DiscardMax:         "0",
+					MajMin:             "8:0",
 				},
 				{
 
-					Name:       "sda1",
-					FSType:     "",
-					Type:       "part",
-					Size:       "62913494528",
-					Model:      "",
-					Vendor:     "",
-					Serial:     "",
-					Rotational: "1",
-					ReadOnly:   "0",
-					Removable:  "0",
-					State:      "running",
-					PartLabel:  "BIOS-BOOT",
+					Name:               "sda1",
+					FSType:             "",
+					Type:               "part",
+					Size:               "62913494528",
+					Model:              "",
+					Vendor:             "",
+					Serial:             "",
+					Rotational:         "1",
+					ReadOnly:           "0",
+					Removable:          "0",
+					State:              "running",
+					PartLabel:          "BIOS-BOOT",
+					PartType:           "21686148-6449-6e6f-744e-656564454649",
+					PartUUID:           "5c2f7a3e-9d41-4b8e-a0c6-1f2e3d4c5b6a",
+					LogicalSectorSize:  "512",
+					PhysicalSectorSize: "4096",
+					Zoned:              "none",
+					DiscardMax:         "0",
 				},
 			},
 		},
@@ -137,7 +162,20 @@ func TestListBlockDevices(t *testing.T) {
 			expected:          []BlockDevice{},
 		},
 		{
-			label:             "Case 4: lsblk output with white space",
+			label:             "Case 4: logical volumes on multiple PVs",
+			lsblkOutput:       lsblkOutputLVM,
+			blkIDOutput:       blkIDOutputLVM,
+			totalBlockDevices: 4,
+			totalBadRows:      0,
+			expected: []BlockDevice{
+				{Name: "sdd", Type: "disk", FSType: "LVM2_member", Size: "10737418240", Rotational: "0", ReadOnly: "0"},
+				{Name: "data-lv1", Type: "lvm", FSType: "", Size: "16106127360", Rotational: "0", ReadOnly: "0"},
+				{Name: "sde", Type: "disk", FSType: "LVM2_member", Size: "10737418240", Rotational: "0", ReadOnly: "0"},
+				{Name: "data-lv2", Type: "lvm", FSType: "xfs", Size: "4294967296", Rotational: "0", ReadOnly: "0"},
+			},
+		},
+		{
+			label:             "Case 5: lsblk output with white space",
 			lsblkOutput:       `NAME="sda" MODEL="VBOX HARDDISK   " VENDOR="ATA   "`,
 			blkIDOutput:       "",
 			totalBlockDevices: 1,
@@ -174,6 +212,15 @@ func TestListBlockDevices(t *testing.T) {
 				assert.Equalf(t, tc.expected[i].Rotational, blockDevices[i].Rotational, "[Device: %d]: invalid block device rotational property", i+1)
 				assert.Equalf(t, tc.expected[i].ReadOnly, blockDevices[i].ReadOnly, "[Device: %d]: invalid block device read only value", i+1)
 				assert.Equalf(t, tc.expected[i].PartLabel, blockDevices[i].PartLabel, "[Device: %d]: invalid block device PartLabel value", i+1)
+				assert.Equalf(t, tc.expected[i].PartType, blockDevices[i].PartType, "[Device: %d]: invalid block device PartType value", i+1)
+				assert.Equalf(t, tc.expected[i].PartUUID, blockDevices[i].PartUUID, "[Device: %d]: invalid block device PartUUID value", i+1)
+				assert.Equalf(t, tc.expected[i].WWN, blockDevices[i].WWN, "[Device: %d]: invalid block device WWN value", i+1)
+				assert.Equalf(t, tc.expected[i].Transport, blockDevices[i].Transport, "[Device: %d]: invalid block device Transport value", i+1)
+				assert.Equalf(t, tc.expected[i].LogicalSectorSize, blockDevices[i].LogicalSectorSize, "[Device: %d]: invalid block device LogicalSectorSize value", i+1)
+				assert.Equalf(t, tc.expected[i].PhysicalSectorSize, blockDevices[i].PhysicalSectorSize, "[Device: %d]: invalid block device PhysicalSectorSize value", i+1)
+				assert.Equalf(t, tc.expected[i].Zoned, blockDevices[i].Zoned, "[Device: %d]: invalid block device Zoned value", i+1)
+				assert.Equalf(t, tc.expected[i].MajMin, blockDevices[i].MajMin, "[Device: %d]: invalid block device MajMin value", i+1)
+				assert.Equalf(t, tc.expected[i].DiscardMax, blockDevices[i].DiscardMax, "[Device: %d]: invalid block device DiscardMax value", i+1)
 			}
 		})
 	}
@@ -341,6 +388,16 @@ func TestHasChildrenFail(t *testing.T) {
 }
 
 func TestGetPathByID(t *testing.T) {
+	// partition labels in sysfs, scratch is used twice
+	origSysClassBlockDir := sysClassBlockDir
+	defer func() { sysClassBlockDir = origSysClassBlockDir }()
+	sysClassBlockDir = t.TempDir()
+	for name, partLabel := range map[string]string{"vda1": "boot", "vda4": "data", "vda5": "scratch", "vdb1": "scratch"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(sysClassBlockDir, name), 0755))
+		uevent := fmt.Sprintf("MAJOR=252\nDEVNAME=%s\nDEVTYPE=partition\nPARTNAME=%s\n", name, partLabel)
+		assert.NoError(t, os.WriteFile(filepath.Join(sysClassBlockDir, name, "uevent"), []byte(uevent), 0644))
+	}
+
 	testcases := []struct {
 		label               string
 		blockDevice         BlockDevice
@@ -495,6 +552,95 @@ func TestGetPathByID(t *testing.T) {
 			},
 			expected: "/dev/disk/by-id/scsi-3faeb3bf4dc5abcde",
 		},
+		{
+			label:       "Prefer /dev/mapper paths for logical volumes",
+			blockDevice: BlockDevice{Name: "data-lv1", KName: "dm-1", Type: LVMDeviceType},
+			fakeGlobfunc: func(path string) ([]string, error) {
+				if strings.HasPrefix(path, DiskDMDir) {
+					return []string{"/dev/mapper/control", "/dev/mapper/data-lv1", "/dev/mapper/data-lv2"}, nil
+				}
+				return []string{"/dev/disk/by-id/dm-name-data-lv1", "/dev/disk/by-id/dm-uuid-LVM-abcde"}, nil
+			},
+			fakeEvalSymlinkfunc: func(path string) (string, error) {
+				links := map[string]string{
+					"/dev/mapper/control":               "/dev/mapper/control",
+					"/dev/mapper/data-lv1":              "/dev/dm-1",
+					"/dev/mapper/data-lv2":              "/dev/dm-2",
+					"/dev/disk/by-id/dm-name-data-lv1":  "/dev/dm-1",
+					"/dev/disk/by-id/dm-uuid-LVM-abcde": "/dev/dm-1",
+				}
+				return links[path], nil
+			},
+			expected: "/dev/mapper/data-lv1",
+		},
+		{
+			label:       "Use by-id paths for other device mapper devices",
+			blockDevice: BlockDevice{Name: "mpatha", KName: "dm-3", Type: "mpath"},
+			fakeGlobfunc: func(path string) ([]string, error) {
+				if strings.HasPrefix(path, DiskDMDir) {
+					return []string{"/dev/mapper/mpatha"}, nil
+				}
+				return []string{"/dev/disk/by-id/dm-name-mpatha", "/dev/disk/by-id/wwn-0x6001405abcde"}, nil
+			},
+			fakeEvalSymlinkfunc: func(string) (string, error) {
+				return "/dev/dm-3", nil
+			},
+			expected: "/dev/disk/by-id/wwn-0x6001405abcde",
+		},
+		{
+			label:       "Fall back to by-partuuid paths for partitions without by-id path",
+			blockDevice: BlockDevice{Name: "vda4", KName: "vda4", Type: "part"},
+			fakeGlobfunc: func(path string) ([]string, error) {
+				switch {
+				case strings.HasPrefix(path, DiskByPartUUIDDir):
+					return []string{"/dev/disk/by-partuuid/5c2f7a3e-9d41-4b8e-a0c6-1f2e3d4c5b6a"}, nil
+				case strings.HasPrefix(path, DiskByPartLabelDir):
+					return []string{"/dev/disk/by-partlabel/data"}, nil
+				}
+				return []string{}, nil
+			},
+			fakeEvalSymlinkfunc: func(string) (string, error) {
+				return "/dev/vda4", nil
+			},
+			expected: "/dev/disk/by-partuuid/5c2f7a3e-9d41-4b8e-a0c6-1f2e3d4c5b6a",
+		},
+		{
+			label:       "Fall back to by-partlabel paths for partitions without by-id or by-partuuid path",
+			blockDevice: BlockDevice{Name: "vda4", KName: "vda4", Type: "part"},
+			fakeGlobfunc: func(path string) ([]string, error) {
+				switch {
+				case strings.HasPrefix(path, DiskByPartLabelDir):
+					return []string{"/dev/disk/by-partlabel/boot", "/dev/disk/by-partlabel/data"}, nil
+				case strings.HasPrefix(path, sysClassBlockDir):
+					return filepath.Glob(path)
+				}
+				return []string{}, nil
+			},
+			fakeEvalSymlinkfunc: func(path string) (string, error) {
+				if path == "/dev/disk/by-partlabel/data" {
+					return "/dev/vda4", nil
+				}
+				return "/dev/vda1", nil
+			},
+			expected: "/dev/disk/by-partlabel/data",
+		},
+		{
+			label:       "Prefer by-id paths for partitions",
+			blockDevice: BlockDevice{Name: "sdb1", KName: "sdb1", Type: "part"},
+			fakeGlobfunc: func(path string) ([]string, error) {
+				switch {
+				case strings.HasPrefix(path, DiskByIDDir):
+					return []string{"/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1"}, nil
+				case strings.HasPrefix(path, DiskByPartUUIDDir):
+					return []string{"/dev/disk/by-partuuid/5c2f7a3e-9d41-4b8e-a0c6-1f2e3d4c5b6a"}, nil
+				}
+				return []string{}, nil
+			},
+			fakeEvalSymlinkfunc: func(string) (string, error) {
+				return "/dev/sdb1", nil
+			},
+			expected: "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1",
+		},
 	}
 
 	for _, tc := range testcases {
@@ -510,6 +656,24 @@ func TestGetPathByID(t *testing.T) {
 		assert.Equalf(t, tc.expected, actual, "[%s] failed to get device path by ID", tc.label)
 
 	}
+
+	// the by-partlabel link of a label that is used more than once may point to any of its partitions
+	FilePathGlob = func(path string) ([]string, error) {
+		switch {
+		case strings.HasPrefix(path, DiskByPartLabelDir):
+			return []string{"/dev/disk/by-partlabel/scratch"}, nil
+		case strings.HasPrefix(path, sysClassBlockDir):
+			return filepath.Glob(path)
+		}
+		return []string{}, nil
+	}
+	FilePathEvalSymLinks = func(string) (string, error) {
+		return "/dev/vda5", nil
+	}
+	blockDevice := BlockDevice{Name: "vda5", KName: "vda5", Type: "part"}
+	_, err := blockDevice.GetPathByID()
+	assert.ErrorAs(t, err, &IDPathNotFoundError{})
+	assert.Empty(t, blockDevice.PathByID)
 }
 
 func TestGetPathByIDFail(t *testing.T) {
@@ -560,6 +724,30 @@ func TestGetPathByIDFail(t *testing.T) {
 	}
 }
 
+func TestParseLSBLKRowColumnNames(t *testing.T) {
+	testcases := []struct {
+		label string
+		row   string
+	}{
+		{
+			label: "hyphenated column names",
+			row:   `NAME="sdb" KNAME="sdb" LOG-SEC="4096" PHY-SEC="4096" DISC-MAX="2147450880" MAJ:MIN="8:16"`,
+		},
+		{
+			label: "shell compatible column names",
+			row:   `NAME="sdb" KNAME="sdb" LOG_SEC="4096" PHY_SEC="4096" DISC_MAX="2147450880" MAJ_MIN="8:16"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		outputMap := parseLSBLKRow(tc.row, map[string]string{})
+		assert.Equalf(t, "4096", outputMap["log_sec"], "[%s] logical sector size", tc.label)
+		assert.Equalf(t, "4096", outputMap["phy_sec"], "[%s] physical sector size", tc.label)
+		assert.Equalf(t, "2147450880", outputMap["disc_max"], "[%s] discard max", tc.label)
+		assert.Equalf(t, "8:16", outputMap["maj_min"], "[%s] major and minor number", tc.label)
+	}
+}
+
 func TestParseBitBool(t *testing.T) {
 	testcases := []struct {
 		label    string
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// LVMDeviceType is the lsblk type of LVM logical volumes
+	LVMDeviceType = "lvm"
+	// UdevDMVGName is the udev property holding the volume group of an LVM logical volume
+	UdevDMVGName = "DM_VG_NAME"
+	// UdevDMLVName is the udev property holding the name of an LVM logical volume
+	UdevDMLVName = "DM_LV_NAME"
+
+	// lvmUUIDPrefix is the prefix of the device mapper UUID of LVM logical volumes
+	lvmUUIDPrefix = "LVM-"
+	// lvmUUIDLength is the length of an LVM UUID without dashes. The device mapper UUID of a
+	// logical volume is lvmUUIDPrefix followed by the VG UUID and the LV UUID. LVM appends a
+	// suffix such as "-tpool" or "-real" to the UUID of the internal devices it creates.
+	lvmUUIDLength = 2 * 32
+	// dmUUIDLinkPrefix is the prefix of the /dev/disk/by-id links udev creates for device mapper UUIDs
+	dmUUIDLinkPrefix = "dm-uuid-"
+)
+
+// GetDMUUID returns the device mapper UUID of the device, read from sysfs or, if that is not
+// available, from its /dev/disk/by-id/dm-uuid-* link.
+// Returns an empty string if the device is not a device mapper device or has no UUID.
+func (b BlockDevice) GetDMUUID() (string, error) {
+	data, err := os.ReadFile(filepath.Join(sysClassBlockDir, b.KName, "dm", "uuid"))
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read device mapper UUID of %q: %w", b.KName, err)
+	}
+
+	links, err := FilePathGlob(filepath.Join(DiskByIDDir, dmUUIDLinkPrefix+"*"))
+	if err != nil {
+		return "", fmt.Errorf("error listing files in %s: %w", DiskByIDDir, err)
+	}
+	for _, link := range links {
+		isMatch, err := PathEvalsToDiskLabel(link, b.KName)
+		if err != nil {
+			return "", err
+		}
+		if isMatch {
+			return strings.TrimPrefix(filepath.Base(link), dmUUIDLinkPrefix), nil
+		}
+	}
+	return "", nil
+}
+
+// IsLogicalVolume checks whether the device is an LVM logical volume that can be used on its own.
+// LVM internal devices, e.g. the data of thin pools or the origin of snapshots, are not.
+func (b BlockDevice) IsLogicalVolume() (bool, error) {
+	if b.Type != LVMDeviceType {
+		return false, nil
+	}
+	uuid, err := b.GetDMUUID()
+	if err != nil {
+		return false, err
+	}
+	return isLogicalVolumeUUID(uuid), nil
+}
+
+func isLogicalVolumeUUID(uuid string) bool {
+	return strings.HasPrefix(uuid, lvmUUIDPrefix) && len(uuid) == len(lvmUUIDPrefix)+lvmUUIDLength
+}
+
+// GetLVMNames returns the volume group and logical volume names of an LVM logical volume.
+// The names are read from the udev database and, if they are missing there, parsed from the
+// device mapper name. Empty strings are returned for devices that are not logical volumes.
+func (b BlockDevice) GetLVMNames() (string, string, error) {
+	isLV, err := b.IsLogicalVolume()
+	if err != nil || !isLV {
+		return "", "", err
+	}
+	vgName, lvName := b.UdevProperties[UdevDMVGName], b.UdevProperties[UdevDMLVName]
+	if vgName != "" && lvName != "" {
+		return vgName, lvName, nil
+	}
+	// lsblk reports the device mapper name as the name of dm devices
+	vgName, lvName, ok := splitDMName(b.Name)
+	if !ok {
+		return "", "", fmt.Errorf("could not parse volume group and logical volume from device mapper name %q", b.Name)
+	}
+	return vgName, lvName, nil
+}
+
+// splitDMName splits the device mapper name of a logical volume into the volume group and logical
+// volume names. LVM joins them with a single dash and doubles the dashes within the names,
+// e.g. "data--vg-lv--1" is logical volume "lv-1" of volume group "data-vg".
+func splitDMName(name string) (string, string, bool) {
+	for i := 0; i < len(name); i++ {
+		if name[i] != '-' {
+			continue
+		}
+		if i+1 < len(name) && name[i+1] == '-' {
+			// escaped dash
+			i++
+			continue
+		}
+		vgName, lvName := name[:i], name[i+1:]
+		if vgName == "" || lvName == "" {
+			return "", "", false
+		}
+		return strings.ReplaceAll(vgName, "--", "-"), strings.ReplaceAll(lvName, "--", "-"), true
+	}
+	return "", "", false
+}
+
+// GetDMPath returns the /dev/mapper link of the device, or an empty string if it has none
+func (b *BlockDevice) GetDMPath() (string, error) {
+	paths, err := FilePathGlob(filepath.Join(DiskDMDir, "*"))
+	if err != nil {
+		return "", fmt.Errorf("error listing files in %s: %w", DiskDMDir, err)
+	}
+	for _, path := range paths {
+		isMatch, err := PathEvalsToDiskLabel(path, b.KName)
+		if err != nil {
+			return "", err
+		}
+		if isMatch {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// lvmVolumeGroupUUID returns the UUID of the volume group of the device with the kernel name,
+// based on its device mapper UUID in sysfs. Returns an empty string if the device was not created by LVM.
+func lvmVolumeGroupUUID(name string) string {
+	data, err := os.ReadFile(filepath.Join(sysClassBlockDir, name, "dm", "uuid"))
+	if err != nil {
+		return ""
+	}
+	uuid, found := strings.CutPrefix(strings.TrimSpace(string(data)), lvmUUIDPrefix)
+	if !found || len(uuid) < lvmUUIDLength/2 {
+		return ""
+	}
+	return uuid[:lvmUUIDLength/2]
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// IncompleteLogicalVolumePrefix is the prefix of the cached logical volumes that are being set up.
+	// They are renamed to PoolLogicalVolumePrefix once their cache is attached, so that they are never
+	// provisioned without it.
+	IncompleteLogicalVolumePrefix = "new-"
+	// cacheLogicalVolumeSuffix is appended to the name of a cached logical volume to name its cache
+	cacheLogicalVolumeSuffix = "_cache"
+)
+
+// CreateCachedLogicalVolume creates the logical volume PoolLogicalVolumePrefix+id in the volume group. It spans the
+// physical volume origin, and is cached by a logical volume of cacheSize bytes on the physical volume cache with
+// the dm-cache mode, i.e. writethrough or writeback. A failed attempt leaves logical volumes named
+// IncompleteLogicalVolumePrefix+id behind, see RemoveIncompleteLogicalVolumes.
+func CreateCachedLogicalVolume(vgName, id string, origin, cache BlockDevice, cacheSize int64, mode string) error {
+	originPath, err := origin.GetDevPath()
+	if err != nil {
+		return err
+	}
+	cachePath, err := cache.GetDevPath()
+	if err != nil {
+		return err
+	}
+	lvName := IncompleteLogicalVolumePrefix + id
+	cacheLVName := lvName + cacheLogicalVolumeSuffix
+	commands := [][]string{
+		{"lvcreate", "-y", "--wipesignatures", "y", "-n", lvName, "-l", "100%PVS", vgName, originPath},
+		{"lvcreate", "-y", "--wipesignatures", "y", "-n", cacheLVName, "-L", fmt.Sprintf("%db", cacheSize), vgName, cachePath},
+		{"lvconvert", "-y", "--type", "cache", "--cachevol", vgName + "/" + cacheLVName, "--cachemode", mode, vgName + "/" + lvName},
+		{"lvrename", vgName, lvName, PoolLogicalVolumePrefix + id},
+	}
+	for _, command := range commands {
+		cmd := CmdExecutor.Command(command[0], command[1:]...)
+		output, err := executeCmdWithCombinedOutput(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to create cached logical volume %s/%s: %s: %w, output: %q", vgName, lvName, command[0], err, output)
+		}
+	}
+	return nil
+}
+
+// RemoveIncompleteLogicalVolumes removes the logical volumes of the volume group that CreateCachedLogicalVolume
+// failed to set up, and returns their names
+func RemoveIncompleteLogicalVolumes(vgName string) ([]string, error) {
+	lvNames, err := ListLogicalVolumes(vgName)
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]string, 0)
+	for _, lvName := range lvNames {
+		if !strings.HasPrefix(lvName, IncompleteLogicalVolumePrefix) {
+			continue
+		}
+		if err := RemoveLogicalVolume(vgName, lvName); err != nil {
+			return removed, err
+		}
+		removed = append(removed, lvName)
+	}
+	return removed, nil
+}
+
+// ActivateVolumeGroup activates the logical volumes of the volume group, so that their devices show up
+func ActivateVolumeGroup(vgName string) error {
+	cmd := CmdExecutor.Command("vgchange", "-ay", vgName)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to activate volume group %s: %w, output: %q", vgName, err, output)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCachedLogicalVolume(t *testing.T) {
+	var commands [][]string
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	CmdExecutor = fakeLVMExec(
+		lvmAction(&commands, "", nil),
+		lvmAction(&commands, "", nil),
+		lvmAction(&commands, "", nil),
+		lvmAction(&commands, "", nil),
+	)
+
+	err := CreateCachedLogicalVolume("lso-archive", "abc", BlockDevice{KName: "sdb"}, BlockDevice{KName: "nvme0n1"}, 4194304, "writeback")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"lvcreate", "-y", "--wipesignatures", "y", "-n", "new-abc", "-l", "100%PVS", "lso-archive", "/dev/sdb"},
+		{"lvcreate", "-y", "--wipesignatures", "y", "-n", "new-abc_cache", "-L", "4194304b", "lso-archive", "/dev/nvme0n1"},
+		{"lvconvert", "-y", "--type", "cache", "--cachevol", "lso-archive/new-abc_cache", "--cachemode", "writeback", "lso-archive/new-abc"},
+		{"lvrename", "lso-archive", "new-abc", "vol-abc"},
+	}, commands)
+}
+
+func TestRemoveIncompleteLogicalVolumes(t *testing.T) {
+	var commands [][]string
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	CmdExecutor = fakeLVMExec(
+		lvmAction(&commands, "  vol-abc\n  new-def\n  new-def_cache\n", nil),
+		lvmAction(&commands, "", nil),
+		lvmAction(&commands, "", nil),
+	)
+
+	removed, err := RemoveIncompleteLogicalVolumes("lso-archive")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new-def", "new-def_cache"}, removed)
+	assert.Equal(t, []string{"lvremove", "-y", "lso-archive/new-def"}, commands[1])
+}
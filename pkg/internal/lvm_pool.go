@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	// poolVolumeGroupPrefix is the prefix of the volume groups that pool the devices of a storage class
+	poolVolumeGroupPrefix = "lso-"
+	// PoolLogicalVolumePrefix is the prefix of the logical volumes carved from a pool volume group
+	PoolLogicalVolumePrefix = "vol-"
+	// maxVolumeGroupNameLength is the maximum length of LVM volume group names
+	maxVolumeGroupNameLength = 127
+	// lvmNotFoundExitStatus is the exit status of the LVM commands if the volume group or volume does not exist
+	lvmNotFoundExitStatus = 5
+)
+
+// VolumeGroup is the space of an LVM volume group as reported by vgs
+type VolumeGroup struct {
+	Name string
+	// Free is the number of bytes that are not allocated to logical volumes
+	Free int64
+	// ExtentSize is the allocation unit of the volume group in bytes
+	ExtentSize int64
+}
+
+// PhysicalVolume is an LVM physical volume as reported by pvs
+type PhysicalVolume struct {
+	// VGName is the volume group of the physical volume, empty if it does not belong to one
+	VGName string
+	// Size is the number of bytes of the physical volume that can be allocated
+	Size int64
+	// Free is the number of bytes that are not allocated to logical volumes
+	Free int64
+}
+
+// PoolVolumeGroupName returns the name of the volume group that pools the devices of the storage class.
+// Names that don't fit into a volume group name are replaced by their hash.
+func PoolVolumeGroupName(storageClassName string) string {
+	name := poolVolumeGroupPrefix + storageClassName
+	if len(name) <= maxVolumeGroupNameLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(storageClassName))
+	return poolVolumeGroupPrefix + hex.EncodeToString(hash[:16])
+}
+
+// ListPhysicalVolumes maps the kernel names of all LVM physical volumes to their volume group and space.
+func ListPhysicalVolumes() (map[string]PhysicalVolume, error) {
+	cmd := CmdExecutor.Command("pvs", "--noheadings", "--units", "b", "--nosuffix", "--separator", ":",
+		"-o", "pv_name,vg_name,pv_size,pv_free")
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LVM physical volumes: %w, output: %q", err, output)
+	}
+	physicalVolumes := make(map[string]PhysicalVolume)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if len(fields) != 4 || fields[0] == "" {
+			continue
+		}
+		pvName := fields[0]
+		pv := PhysicalVolume{VGName: fields[1]}
+		if pv.Size, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse size of physical volume %s: %w", pvName, err)
+		}
+		if pv.Free, err = strconv.ParseInt(fields[3], 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse free space of physical volume %s: %w", pvName, err)
+		}
+		// physical volumes on device mapper devices are listed by their /dev/mapper path
+		if devPath, err := FilePathEvalSymLinks(pvName); err == nil {
+			pvName = devPath
+		}
+		physicalVolumes[filepath.Base(pvName)] = pv
+	}
+	return physicalVolumes, nil
+}
+
+// GetVolumeGroup returns the space of the volume group, or nil if it does not exist
+func GetVolumeGroup(vgName string) (*VolumeGroup, error) {
+	cmd := CmdExecutor.Command("vgs", "--noheadings", "--units", "b", "--nosuffix", "--separator", ":",
+		"-o", "vg_free,vg_extent_size", vgName)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.ExitStatus() == lvmNotFoundExitStatus {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get volume group %s: %w, output: %q", vgName, err, output)
+	}
+	fields := strings.Split(strings.TrimSpace(output), ":")
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("failed to parse volume group %s: %q", vgName, output)
+	}
+	vg := &VolumeGroup{Name: vgName}
+	if vg.Free, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("failed to parse free space of volume group %s: %w", vgName, err)
+	}
+	if vg.ExtentSize, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("failed to parse extent size of volume group %s: %w", vgName, err)
+	}
+	return vg, nil
+}
+
+// AddToVolumeGroup adds the device to the volume group, which is created if it does not exist yet.
+// Devices with a filesystem or partition table signature are refused, their data is never wiped.
+func (b BlockDevice) AddToVolumeGroup(vgName string) error {
+	if signature := b.Signature(); signature != "" {
+		return fmt.Errorf("refusing to add %s to volume group %s, it has a %s", b.Name, vgName, signature)
+	}
+	devPath, err := b.GetDevPath()
+	if err != nil {
+		return err
+	}
+	vg, err := GetVolumeGroup(vgName)
+	if err != nil {
+		return err
+	}
+	command := "vgextend"
+	if vg == nil {
+		command = "vgcreate"
+	}
+	cmd := CmdExecutor.Command(command, "-y", vgName, devPath)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to volume group %s: %w, output: %q", devPath, vgName, err, output)
+	}
+	return nil
+}
+
+// CreateLogicalVolume creates a logical volume of size bytes in the volume group
+func CreateLogicalVolume(vgName, lvName string, size int64) error {
+	cmd := CmdExecutor.Command("lvcreate", "-y", "--wipesignatures", "y", "-n", lvName, "-L", fmt.Sprintf("%db", size), vgName)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create logical volume %s/%s: %w, output: %q", vgName, lvName, err, output)
+	}
+	return nil
+}
+
+// ListLogicalVolumes returns the names of the logical volumes of the volume group.
+// Hidden logical volumes, e.g. the caches of cached logical volumes, are not listed.
+func ListLogicalVolumes(vgName string) ([]string, error) {
+	cmd := CmdExecutor.Command("lvs", "--noheadings", "-o", "lv_name", vgName)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logical volumes of volume group %s: %w, output: %q", vgName, err, output)
+	}
+	lvNames := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if lvName := strings.TrimSpace(line); lvName != "" {
+			lvNames = append(lvNames, lvName)
+		}
+	}
+	return lvNames, nil
+}
+
+// GetLogicalVolume returns the volume group and name of the logical volume at devPath.
+// Empty strings are returned if devPath is not a logical volume.
+func GetLogicalVolume(devPath string) (string, string, error) {
+	cmd := CmdExecutor.Command("lvs", "--noheadings", "--separator", ":", "-o", "vg_name,lv_name", devPath)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.ExitStatus() == lvmNotFoundExitStatus {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("failed to get logical volume %s: %w, output: %q", devPath, err, output)
+	}
+	vgName, lvName, ok := strings.Cut(strings.TrimSpace(output), ":")
+	if !ok {
+		return "", "", fmt.Errorf("failed to parse logical volume %s: %q", devPath, output)
+	}
+	return vgName, lvName, nil
+}
+
+// RemoveLogicalVolume removes the logical volume, which returns its space to the volume group
+func RemoveLogicalVolume(vgName, lvName string) error {
+	cmd := CmdExecutor.Command("lvremove", "-y", vgName+"/"+lvName)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to remove logical volume %s/%s: %w, output: %q", vgName, lvName, err, output)
+	}
+	return nil
+}
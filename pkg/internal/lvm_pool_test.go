@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+// lvmAction answers a command with output and err, and records it in commands
+func lvmAction(commands *[][]string, output string, err error) testingexec.FakeCommandAction {
+	return func(cmd string, cmdArgs ...string) utilexec.Cmd {
+		*commands = append(*commands, append([]string{cmd}, cmdArgs...))
+		return &testingexec.FakeCmd{
+			CombinedOutputScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) { return []byte(output), nil, err },
+			},
+		}
+	}
+}
+
+func fakeLVMExec(actions ...testingexec.FakeCommandAction) *testingexec.FakeExec {
+	return &testingexec.FakeExec{CommandScript: actions}
+}
+
+func TestPoolVolumeGroupName(t *testing.T) {
+	assert.Equal(t, "lso-fast", PoolVolumeGroupName("fast"))
+	long := PoolVolumeGroupName(strings.Repeat("a", 200))
+	assert.True(t, strings.HasPrefix(long, "lso-"))
+	assert.LessOrEqual(t, len(long), maxVolumeGroupNameLength)
+	assert.NotEqual(t, long, PoolVolumeGroupName(strings.Repeat("b", 200)))
+}
+
+func TestListPhysicalVolumes(t *testing.T) {
+	var commands [][]string
+	oldExecutor := CmdExecutor
+	origEval := FilePathEvalSymLinks
+	defer func() {
+		CmdExecutor = oldExecutor
+		FilePathEvalSymLinks = origEval
+	}()
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "  /dev/sdb:lso-fast:1069547520:0\n  /dev/sdc:lso-fast:1069547520:4194304\n  /dev/mapper/mpatha::1073741824:1073741824\n", nil))
+	FilePathEvalSymLinks = func(path string) (string, error) {
+		if path == "/dev/mapper/mpatha" {
+			return "/dev/dm-0", nil
+		}
+		return path, nil
+	}
+
+	physicalVolumes, err := ListPhysicalVolumes()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]PhysicalVolume{
+		"sdb":  {VGName: "lso-fast", Size: 1069547520, Free: 0},
+		"sdc":  {VGName: "lso-fast", Size: 1069547520, Free: 4194304},
+		"dm-0": {VGName: "", Size: 1073741824, Free: 1073741824},
+	}, physicalVolumes)
+	assert.Equal(t, "pvs", commands[0][0])
+}
+
+func TestGetVolumeGroup(t *testing.T) {
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+
+	var commands [][]string
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "  21470642176:4194304\n", nil))
+	vg, err := GetVolumeGroup("lso-fast")
+	assert.NoError(t, err)
+	assert.Equal(t, &VolumeGroup{Name: "lso-fast", Free: 21470642176, ExtentSize: 4194304}, vg)
+	assert.Equal(t, "lso-fast", commands[0][len(commands[0])-1])
+
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "  Volume group \"lso-fast\" not found", testingexec.FakeExitError{Status: lvmNotFoundExitStatus}))
+	vg, err = GetVolumeGroup("lso-fast")
+	assert.NoError(t, err)
+	assert.Nil(t, vg)
+
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "garbage", nil))
+	_, err = GetVolumeGroup("lso-fast")
+	assert.Error(t, err)
+}
+
+func TestAddToVolumeGroup(t *testing.T) {
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+
+	var commands [][]string
+	CmdExecutor = fakeLVMExec(
+		lvmAction(&commands, "", testingexec.FakeExitError{Status: lvmNotFoundExitStatus}),
+		lvmAction(&commands, "", nil),
+	)
+	err := BlockDevice{KName: "sdb"}.AddToVolumeGroup("lso-fast")
+	assert.NoError(t, err)
+	assert.Len(t, commands, 2)
+	assert.Equal(t, []string{"vgcreate", "-y", "lso-fast", "/dev/sdb"}, commands[1])
+
+	commands = nil
+	CmdExecutor = fakeLVMExec(
+		lvmAction(&commands, "  1048576:4194304", nil),
+		lvmAction(&commands, "", nil),
+	)
+	err = BlockDevice{KName: "sdc"}.AddToVolumeGroup("lso-fast")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vgextend", "-y", "lso-fast", "/dev/sdc"}, commands[1])
+
+	// the data of devices with a signature is never wiped
+	commands = nil
+	CmdExecutor = fakeLVMExec()
+	err = BlockDevice{Name: "sdd", KName: "sdd", FSType: "xfs"}.AddToVolumeGroup("lso-fast")
+	assert.ErrorContains(t, err, "filesystem signature xfs")
+	err = BlockDevice{Name: "sde", KName: "sde", PTType: "gpt"}.AddToVolumeGroup("lso-fast")
+	assert.ErrorContains(t, err, "partition table gpt")
+	assert.Empty(t, commands)
+}
+
+func TestGetLogicalVolume(t *testing.T) {
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+
+	var commands [][]string
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "  lso-fast:vol-abc", nil))
+	vgName, lvName, err := GetLogicalVolume("/dev/dm-3")
+	assert.NoError(t, err)
+	assert.Equal(t, "lso-fast", vgName)
+	assert.Equal(t, "vol-abc", lvName)
+
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "", testingexec.FakeExitError{Status: lvmNotFoundExitStatus}))
+	vgName, lvName, err = GetLogicalVolume("/dev/sdb")
+	assert.NoError(t, err)
+	assert.Empty(t, vgName)
+	assert.Empty(t, lvName)
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitDMName(t *testing.T) {
+	testcases := []struct {
+		name     string
+		vgName   string
+		lvName   string
+		expectOK bool
+	}{
+		{name: "vg0-data", vgName: "vg0", lvName: "data", expectOK: true},
+		{name: "data--vg-lv--1", vgName: "data-vg", lvName: "lv-1", expectOK: true},
+		{name: "a---b", vgName: "a-", lvName: "b", expectOK: true},
+		{name: "vg0-lv-with-dashes", vgName: "vg0", lvName: "lv-with-dashes", expectOK: true},
+		{name: "mpatha", expectOK: false},
+		{name: "only--escaped", expectOK: false},
+		{name: "-lv", expectOK: false},
+		{name: "vg-", expectOK: false},
+	}
+	for _, tc := range testcases {
+		vgName, lvName, ok := splitDMName(tc.name)
+		assert.Equalf(t, tc.expectOK, ok, "%s: unexpected result", tc.name)
+		assert.Equalf(t, tc.vgName, vgName, "%s: unexpected volume group", tc.name)
+		assert.Equalf(t, tc.lvName, lvName, "%s: unexpected logical volume", tc.name)
+	}
+}
+
+func TestGetLVMNames(t *testing.T) {
+	origSysClassBlockDir := sysClassBlockDir
+	defer func() {
+		sysClassBlockDir = origSysClassBlockDir
+	}()
+	sysClassBlockDir = t.TempDir()
+	for kname, uuid := range map[string]string{
+		"dm-0": "LVM-Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uNaB3dE5fG7hJ9kL1mN3pQ5rS7tU9vW1xY",
+		"dm-1": "LVM-Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uNcD4eF6gH8iJ0kL2mN4oP6qR8sT0uV2wX-tpool",
+		"dm-2": "mpath-36001405a1b2c3d4e5f6a7b8c9d0e1f2a",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(sysClassBlockDir, kname, "dm"), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(sysClassBlockDir, kname, "dm", "uuid"), []byte(uuid+"\n"), 0644))
+	}
+
+	testcases := []struct {
+		label  string
+		device BlockDevice
+		vgName string
+		lvName string
+		isLV   bool
+	}{
+		{
+			label:  "names from the device mapper name",
+			device: BlockDevice{Name: "data--vg-lv1", KName: "dm-0", Type: LVMDeviceType},
+			vgName: "data-vg", lvName: "lv1", isLV: true,
+		},
+		{
+			label: "names from udev",
+			device: BlockDevice{Name: "data--vg-lv1", KName: "dm-0", Type: LVMDeviceType,
+				UdevProperties: map[string]string{UdevDMVGName: "data-vg", UdevDMLVName: "lv1"}},
+			vgName: "data-vg", lvName: "lv1", isLV: true,
+		},
+		{
+			label:  "thin pool",
+			device: BlockDevice{Name: "data--vg-pool-tpool", KName: "dm-1", Type: LVMDeviceType},
+		},
+		{
+			label:  "multipath device",
+			device: BlockDevice{Name: "mpatha", KName: "dm-2", Type: "mpath"},
+		},
+	}
+	for _, tc := range testcases {
+		isLV, err := tc.device.IsLogicalVolume()
+		assert.NoErrorf(t, err, "[%s] IsLogicalVolume", tc.label)
+		assert.Equalf(t, tc.isLV, isLV, "[%s] IsLogicalVolume", tc.label)
+
+		vgName, lvName, err := tc.device.GetLVMNames()
+		assert.NoErrorf(t, err, "[%s] GetLVMNames", tc.label)
+		assert.Equalf(t, tc.vgName, vgName, "[%s] unexpected volume group", tc.label)
+		assert.Equalf(t, tc.lvName, lvName, "[%s] unexpected logical volume", tc.label)
+	}
+}
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	// raidDeviceTypePrefix is the prefix of the lsblk types of md arrays, e.g. raid1
+	raidDeviceTypePrefix = "raid"
+	// RaidArrayDir holds the links udev creates for md arrays, named after the arrays
+	RaidArrayDir = "/dev/md/"
+	// UdevMDDevName is the udev property holding the name of an md array
+	UdevMDDevName = "MD_DEVNAME"
+	// mdUUIDLinkPrefix is the prefix of the /dev/disk/by-id links udev creates for md array UUIDs
+	mdUUIDLinkPrefix = "md-uuid-"
+	// raidArrayPrefix is the prefix of the names of the md arrays assembled for a storage class
+	raidArrayPrefix = "lso-"
+	// raidHomehost is stored in the arrays instead of the hostname, so that the names of the arrays are
+	// not prefixed with the hostname and the arrays are assembled regardless of the hostname
+	raidHomehost = "any"
+	// mdadmNothingAssembledExitStatus is the exit status of mdadm --assemble if no array was assembled
+	mdadmNothingAssembledExitStatus = 1
+)
+
+// RaidArray is an md array as reported by mdadm --examine --scan
+type RaidArray struct {
+	Name string
+	UUID string
+}
+
+// IsRaidArray checks whether the device is an md array
+func (b BlockDevice) IsRaidArray() bool {
+	return strings.HasPrefix(b.Type, raidDeviceTypePrefix)
+}
+
+// RaidArrayNamePrefix returns the prefix of the names of the md arrays assembled for the storage class.
+// The storage class name is hashed because the names of md arrays are limited to 32 characters.
+func RaidArrayNamePrefix(storageClassName string) string {
+	hash := sha256.Sum256([]byte(storageClassName))
+	return raidArrayPrefix + hex.EncodeToString(hash[:4]) + "-"
+}
+
+// GetRaidArrayName returns the name of the md array, i.e. the name of its link in RaidArrayDir.
+// Returns an empty string if the array has no name.
+func (b BlockDevice) GetRaidArrayName() (string, error) {
+	if name := b.UdevProperties[UdevMDDevName]; name != "" {
+		return name, nil
+	}
+	links, err := FilePathGlob(filepath.Join(RaidArrayDir, "*"))
+	if err != nil {
+		return "", fmt.Errorf("error listing files in %s: %w", RaidArrayDir, err)
+	}
+	for _, link := range links {
+		isMatch, err := PathEvalsToDiskLabel(link, b.KName)
+		if err != nil {
+			return "", err
+		}
+		if isMatch {
+			return filepath.Base(link), nil
+		}
+	}
+	return "", nil
+}
+
+// GetRaidMembers returns the kernel names of the devices the md array is assembled from
+func (b BlockDevice) GetRaidMembers() ([]string, error) {
+	links, err := FilePathGlob(filepath.Join(sysClassBlockDir, b.KName, "slaves", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members of md array %q: %w", b.KName, err)
+	}
+	members := make([]string, 0, len(links))
+	for _, link := range links {
+		members = append(members, filepath.Base(link))
+	}
+	return members, nil
+}
+
+// GetRaidDegradedDevices returns the number of members that are missing from the md array
+func (b BlockDevice) GetRaidDegradedDevices() (int, error) {
+	data, err := os.ReadFile(filepath.Join(sysClassBlockDir, b.KName, "md", "degraded"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// RAID 0 arrays have no redundancy to degrade
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read degraded state of md array %q: %w", b.KName, err)
+	}
+	degraded, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse degraded state of md array %q: %w", b.KName, err)
+	}
+	return degraded, nil
+}
+
+// CreateRaidArray creates the md array /dev/md/<name> of the RAID level from the members.
+// The caller must make sure that the members are not in use. Members with a filesystem or partition
+// table signature are refused, as --run skips the confirmation mdadm asks for before overwriting them.
+func CreateRaidArray(name string, level int32, members []BlockDevice) error {
+	for _, member := range members {
+		if signature := member.Signature(); signature != "" {
+			return fmt.Errorf("refusing to create md array %s, member %s has a %s", name, member.Name, signature)
+		}
+	}
+	args := []string{
+		"--create", filepath.Join(RaidArrayDir, name),
+		"--run",
+		"--metadata=1.2",
+		"--homehost=" + raidHomehost,
+		"--name=" + name,
+		fmt.Sprintf("--level=%d", level),
+		fmt.Sprintf("--raid-devices=%d", len(members)),
+	}
+	for _, member := range members {
+		devPath, err := member.GetDevPath()
+		if err != nil {
+			return err
+		}
+		args = append(args, devPath)
+	}
+	cmd := CmdExecutor.Command("mdadm", args...)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create md array %s: %w, output: %q", name, err, output)
+	}
+	return nil
+}
+
+// ListRaidArrays returns the md arrays whose superblocks are found on the devices of the node,
+// whether they are assembled or not
+func ListRaidArrays() ([]RaidArray, error) {
+	cmd := CmdExecutor.Command("mdadm", "--examine", "--scan")
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for md arrays: %w, output: %q", err, output)
+	}
+	arrays := make([]RaidArray, 0)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "ARRAY" {
+			continue
+		}
+		var array RaidArray
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "UUID":
+				array.UUID = value
+			case "name":
+				// the name is prefixed with the homehost
+				if _, name, found := strings.Cut(value, ":"); found {
+					value = name
+				}
+				array.Name = value
+			}
+		}
+		if array.UUID != "" {
+			arrays = append(arrays, array)
+		}
+	}
+	return arrays, nil
+}
+
+// IsRaidArrayAssembled checks whether the md array is running, i.e. whether its link in RaidArrayDir exists
+func IsRaidArrayAssembled(name string) bool {
+	_, err := FilePathEvalSymLinks(filepath.Join(RaidArrayDir, name))
+	return err == nil
+}
+
+// AssembleRaidArray assembles the md array from the devices that carry its superblock. Arrays with
+// missing members are started degraded, so that their data remains available.
+func AssembleRaidArray(array RaidArray) error {
+	cmd := CmdExecutor.Command("mdadm", "--assemble", "--scan", "--run", "--homehost="+raidHomehost, "--uuid="+array.UUID)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.ExitStatus() == mdadmNothingAssembledExitStatus {
+			return fmt.Errorf("no members of md array %s found: %q", array.Name, output)
+		}
+		return fmt.Errorf("failed to assemble md array %s: %w, output: %q", array.Name, err, output)
+	}
+	return nil
+}
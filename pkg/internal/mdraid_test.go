@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestRaidArrayNamePrefix(t *testing.T) {
+	prefix := RaidArrayNamePrefix(strings.Repeat("a", 200))
+	assert.True(t, strings.HasPrefix(prefix, "lso-"))
+	// leaves room for the random suffix within the 32 characters of an md array name
+	assert.LessOrEqual(t, len(prefix)+6, 32)
+	assert.NotEqual(t, prefix, RaidArrayNamePrefix("fast"))
+	assert.True(t, BlockDevice{Type: "raid10"}.IsRaidArray())
+	assert.False(t, BlockDevice{Type: "disk"}.IsRaidArray())
+}
+
+func TestListRaidArrays(t *testing.T) {
+	var commands [][]string
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, strings.Join([]string{
+		"ARRAY /dev/md/lso-1a2b3c4d-abcdef  metadata=1.2 UUID=5c1e8a1f:2b3d4e5f:6a7b8c9d:0e1f2a3b name=any:lso-1a2b3c4d-abcdef",
+		"ARRAY /dev/md/data metadata=1.2 UUID=1c1e8a1f:2b3d4e5f:6a7b8c9d:0e1f2a3b name=host1:data",
+	}, "\n"), nil))
+
+	arrays, err := ListRaidArrays()
+	assert.NoError(t, err)
+	assert.Equal(t, []RaidArray{
+		{Name: "lso-1a2b3c4d-abcdef", UUID: "5c1e8a1f:2b3d4e5f:6a7b8c9d:0e1f2a3b"},
+		{Name: "data", UUID: "1c1e8a1f:2b3d4e5f:6a7b8c9d:0e1f2a3b"},
+	}, arrays)
+	assert.Equal(t, []string{"mdadm", "--examine", "--scan"}, commands[0])
+}
+
+func TestCreateRaidArray(t *testing.T) {
+	var commands [][]string
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "", nil))
+
+	err := CreateRaidArray("lso-1a2b3c4d-abcdef", 1, []BlockDevice{{KName: "sdb"}, {KName: "sdc"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"mdadm", "--create", "/dev/md/lso-1a2b3c4d-abcdef", "--run", "--metadata=1.2", "--homehost=any",
+		"--name=lso-1a2b3c4d-abcdef", "--level=1", "--raid-devices=2", "/dev/sdb", "/dev/sdc",
+	}, commands[0])
+
+	// --run would overwrite the signature without asking
+	CmdExecutor = fakeLVMExec()
+	err = CreateRaidArray("lso-1a2b3c4d-abcdef", 1, []BlockDevice{{Name: "sdb", KName: "sdb"}, {Name: "sdc", KName: "sdc", FSType: "ext4"}})
+	assert.ErrorContains(t, err, "member sdc has a filesystem signature ext4")
+	assert.Len(t, commands, 1)
+
+	CmdExecutor = fakeLVMExec(lvmAction(&commands, "mdadm: no devices found", testingexec.FakeExitError{Status: mdadmNothingAssembledExitStatus}))
+	err = AssembleRaidArray(RaidArray{Name: "lso-1a2b3c4d-abcdef", UUID: "5c1e8a1f:2b3d4e5f:6a7b8c9d:0e1f2a3b"})
+	assert.Error(t, err)
+	assert.Contains(t, commands[1], "--uuid=5c1e8a1f:2b3d4e5f:6a7b8c9d:0e1f2a3b")
+}
+
+func TestGetRaidMembersAndDegradedDevices(t *testing.T) {
+	origSysClassBlockDir := sysClassBlockDir
+	origGlob := FilePathGlob
+	defer func() {
+		sysClassBlockDir = origSysClassBlockDir
+		FilePathGlob = origGlob
+	}()
+	sysClassBlockDir = t.TempDir()
+	FilePathGlob = filepath.Glob
+	for _, member := range []string{"sdb", "sdc"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(sysClassBlockDir, "md127", "slaves", member), 0755))
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(sysClassBlockDir, "md127", "md"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(sysClassBlockDir, "md127", "md", "degraded"), []byte("1\n"), 0644))
+
+	array := BlockDevice{KName: "md127", Type: "raid1"}
+	members, err := array.GetRaidMembers()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sdb", "sdc"}, members)
+	degraded, err := array.GetRaidDegradedDevices()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, degraded)
+
+	// RAID 0 arrays have no degraded state
+	degraded, err = BlockDevice{KName: "md126", Type: "raid0"}.GetRaidDegradedDevices()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, degraded)
+}
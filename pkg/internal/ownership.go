@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+	utilexec "k8s.io/utils/exec"
+)
+
+// signature types reported by blkid that identify the owner of a device
+const (
+	cephBluestoreSignature = "ceph_bluestore"
+	lvmMemberSignature     = "LVM2_member"
+	mdRaidMemberSignature  = "linux_raid_member"
+	luksSignature          = "crypto_LUKS"
+	zfsMemberSignature     = "zfs_member"
+
+	// cephVGPrefix is the prefix ceph-volume uses for the volume groups of LVM based OSDs
+	cephVGPrefix = "ceph-"
+)
+
+// ProbeSignatures runs a low level blkid probe on the device and returns all the
+// reported values, e.g. TYPE, LABEL, PTTYPE and PART_ENTRY_*.
+// Returns an empty map if the device carries no signature.
+func (b BlockDevice) ProbeSignatures() (map[string]string, error) {
+	devPath, err := b.GetDevPath()
+	if err != nil {
+		return nil, err
+	}
+	cmd := CmdExecutor.Command("blkid", "-p", "-o", "export", devPath)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		// blkid returns 2 when no signature is found on the device
+		if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.ExitStatus() == 2 {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to probe signatures of %s: %w", devPath, err)
+	}
+	signatures := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || key == "" {
+			continue
+		}
+		signatures[key] = value
+	}
+	return signatures, nil
+}
+
+// GetOwner returns a human readable description of what is likely using the device,
+// for example "Ceph OSD (bluestore)", "LVM PV of VG foo" or "GPT partition table without partitions".
+// Returns an empty string if the device carries no known signature.
+func (b BlockDevice) GetOwner() (string, error) {
+	signatures, err := b.ProbeSignatures()
+	if err != nil {
+		return "", err
+	}
+	hasChildren, err := b.HasChildren()
+	if err != nil {
+		return "", err
+	}
+	vgName := ""
+	if signatures["TYPE"] == lvmMemberSignature {
+		vgName = b.lvmVolumeGroup()
+	}
+	return classifyOwner(signatures, hasChildren, vgName), nil
+}
+
+// lvmVolumeGroup returns the volume group of the LVM PV, or an empty string if it is not known
+func (b BlockDevice) lvmVolumeGroup() string {
+	devPath, err := b.GetDevPath()
+	if err != nil {
+		return ""
+	}
+	cmd := CmdExecutor.Command("pvs", "--noheadings", "-o", "vg_name", devPath)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		klog.V(4).InfoS("could not look up volume group of LVM PV", "device", devPath, "err", err)
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+func classifyOwner(signatures map[string]string, hasChildren bool, vgName string) string {
+	label := signatures["LABEL"]
+	switch fsType := signatures["TYPE"]; fsType {
+	case cephBluestoreSignature:
+		return "Ceph OSD (bluestore)"
+	case lvmMemberSignature:
+		switch {
+		case vgName == "":
+			return "LVM PV"
+		case strings.HasPrefix(vgName, cephVGPrefix):
+			return fmt.Sprintf("Ceph OSD (LVM PV of VG %s)", vgName)
+		default:
+			return fmt.Sprintf("LVM PV of VG %s", vgName)
+		}
+	case mdRaidMemberSignature:
+		if label != "" {
+			return fmt.Sprintf("mdraid member of array %s", label)
+		}
+		return "mdraid member"
+	case luksSignature:
+		return "LUKS encrypted volume"
+	case zfsMemberSignature:
+		if label != "" {
+			return fmt.Sprintf("ZFS member of pool %s", label)
+		}
+		return "ZFS member"
+	case "":
+		ptType := signatures["PTTYPE"]
+		if ptType == "" {
+			return ""
+		}
+		if hasChildren {
+			return fmt.Sprintf("%s partition table with partitions", strings.ToUpper(ptType))
+		}
+		return fmt.Sprintf("%s partition table without partitions", strings.ToUpper(ptType))
+	default:
+		if label != "" {
+			return fmt.Sprintf("%s filesystem %q", fsType, label)
+		}
+		return fmt.Sprintf("%s filesystem", fsType)
+	}
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyOwner(t *testing.T) {
+	testcases := []struct {
+		label       string
+		signatures  map[string]string
+		hasChildren bool
+		vgName      string
+		expected    string
+	}{
+		{label: "no signatures", signatures: map[string]string{}, expected: ""},
+		{label: "bluestore", signatures: map[string]string{"TYPE": "ceph_bluestore"}, expected: "Ceph OSD (bluestore)"},
+		{label: "LVM PV without VG", signatures: map[string]string{"TYPE": "LVM2_member"}, expected: "LVM PV"},
+		{label: "LVM PV", signatures: map[string]string{"TYPE": "LVM2_member"}, vgName: "foo", expected: "LVM PV of VG foo"},
+		{
+			label:      "LVM based Ceph OSD",
+			signatures: map[string]string{"TYPE": "LVM2_member"},
+			vgName:     "ceph-0b7ad1cc-7e2c-4bd6-8a1d-5d0f9e1f7a12",
+			expected:   "Ceph OSD (LVM PV of VG ceph-0b7ad1cc-7e2c-4bd6-8a1d-5d0f9e1f7a12)",
+		},
+		{label: "mdraid member", signatures: map[string]string{"TYPE": "linux_raid_member"}, expected: "mdraid member"},
+		{label: "named mdraid member", signatures: map[string]string{"TYPE": "linux_raid_member", "LABEL": "host:md0"}, expected: "mdraid member of array host:md0"},
+		{label: "LUKS", signatures: map[string]string{"TYPE": "crypto_LUKS"}, expected: "LUKS encrypted volume"},
+		{label: "ZFS", signatures: map[string]string{"TYPE": "zfs_member", "LABEL": "tank"}, expected: "ZFS member of pool tank"},
+		{label: "filesystem", signatures: map[string]string{"TYPE": "xfs"}, expected: "xfs filesystem"},
+		{label: "labeled filesystem", signatures: map[string]string{"TYPE": "ext4", "LABEL": "data"}, expected: `ext4 filesystem "data"`},
+		{label: "empty GPT", signatures: map[string]string{"PTTYPE": "gpt", "PTUUID": "a1b2"}, expected: "GPT partition table without partitions"},
+		{label: "partitioned DOS", signatures: map[string]string{"PTTYPE": "dos"}, hasChildren: true, expected: "DOS partition table with partitions"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			assert.Equal(t, tc.expected, classifyOwner(tc.signatures, tc.hasChildren, tc.vgName))
+		})
+	}
+}
+
+func TestProbeSignatures(t *testing.T) {
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+
+	CmdExecutor = newFakeExecutor(`DEVNAME=/dev/sdb
+UUID=2a3c4f6e-1d2b-4c5d-9e8f-0a1b2c3d4e5f
+VERSION=LVM2 001
+TYPE=LVM2_member
+USAGE=raid
+`)
+	dev := BlockDevice{KName: "sdb"}
+	signatures, err := dev.ProbeSignatures()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DEVNAME": "/dev/sdb",
+		"UUID":    "2a3c4f6e-1d2b-4c5d-9e8f-0a1b2c3d4e5f",
+		"VERSION": "LVM2 001",
+		"TYPE":    "LVM2_member",
+		"USAGE":   "raid",
+	}, signatures)
+}
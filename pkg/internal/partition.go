@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// LSOPartitionLabelPrefix is the prefix of the GPT partition names of the partitions created by
+	// CreatePartitions. It marks them as partitions that are provisioned by LSO.
+	LSOPartitionLabelPrefix = "lso-part-"
+	// diskDeviceType and multipathDeviceType are the lsblk types of whole disks
+	diskDeviceType      = "disk"
+	multipathDeviceType = "mpath"
+	// MaxPartitions is the number of partitions that fit in the default GPT partition table
+	MaxPartitions = 128
+
+	mebibyte = 1024 * 1024
+	// partitionTableOverhead is the space that is not available for partitions: the first MiB holds the
+	// protective MBR and the primary GPT and aligns the first partition, the backup GPT takes up the end of the disk
+	partitionTableOverhead = 2 * mebibyte
+)
+
+// IsLSOPartition checks whether the device is a partition created by CreatePartitions
+func (b BlockDevice) IsLSOPartition() bool {
+	return b.Type == partitionDeviceType && strings.HasPrefix(b.PartLabel, LSOPartitionLabelPrefix)
+}
+
+// IsPartitionable checks whether the device is a whole disk that CreatePartitions can be used on
+func (b BlockDevice) IsPartitionable() bool {
+	return b.Type == diskDeviceType || b.Type == multipathDeviceType
+}
+
+// PartitionLayout returns the number and size in bytes of the partitions that a disk of diskSize bytes is split into,
+// either count partitions of equal size or as many partitions of partitionSize as fit. Sizes are multiples of 1MiB.
+// Returns an error if not a single partition fits.
+func PartitionLayout(diskSize int64, count int32, partitionSize int64) (int, int64, error) {
+	usable := diskSize - partitionTableOverhead
+	var size int64
+	var n int64
+	switch {
+	case count > 0:
+		n = int64(min(count, MaxPartitions))
+		size = usable / n / mebibyte * mebibyte
+	case partitionSize > 0:
+		size = partitionSize / mebibyte * mebibyte
+		if size > 0 {
+			n = min(usable/size, MaxPartitions)
+		}
+	default:
+		return 0, 0, fmt.Errorf("neither partition count nor size is set")
+	}
+	if n < 1 || size < mebibyte {
+		return 0, 0, fmt.Errorf("disk of %d bytes is too small for the requested partitions", diskSize)
+	}
+	return int(n), size, nil
+}
+
+// CreatePartitions writes a new GPT partition table with count partitions of size bytes to the device.
+// The partitions are named LSOPartitionLabelPrefix followed by their number.
+// The caller must make sure that the device is not in use.
+func (b BlockDevice) CreatePartitions(count int, size int64) error {
+	devPath, err := b.GetDevPath()
+	if err != nil {
+		return err
+	}
+	args := []string{"--clear"}
+	for i := 1; i <= count; i++ {
+		number := strconv.Itoa(i)
+		args = append(args,
+			fmt.Sprintf("--new=%s:0:+%dK", number, size/1024),
+			fmt.Sprintf("--change-name=%s:%s%s", number, LSOPartitionLabelPrefix, number),
+		)
+	}
+	args = append(args, devPath)
+	cmd := CmdExecutor.Command("sgdisk", args...)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to partition %s: %w, output: %q", devPath, err, output)
+	}
+	return nil
+}
+
+// PartitionDisk splits the device into partitions according to partitioning, unless a symlink in
+// symlinkDir already points to it because the whole device backs a PV. Devices with a filesystem or
+// partition table signature are only partitioned if forceWipe is set. It returns whether the device
+// was partitioned, and the number and size in bytes of the partitions.
+func (b BlockDevice) PartitionDisk(partitioning localv1.Partitioning, symlinkDir string, forceWipe bool) (bool, int, int64, error) {
+	if signature := b.Signature(); signature != "" && !forceWipe {
+		return false, 0, 0, fmt.Errorf("refusing to partition %s, it has a %s", b.Name, signature)
+	}
+	var count int
+	var partitionSize int64
+	partitioned, err := b.WithUnprovisionedDevice(symlinkDir, func() error {
+		size, err := b.GetSize()
+		if err != nil {
+			return err
+		}
+		var requestedCount int32
+		var requestedSize int64
+		if partitioning.Count != nil {
+			requestedCount = *partitioning.Count
+		} else if partitioning.PartitionSize != nil {
+			requestedSize = partitioning.PartitionSize.Value()
+		}
+		count, partitionSize, err = PartitionLayout(size, requestedCount, requestedSize)
+		if err != nil {
+			return err
+		}
+		klog.InfoS("partitioning device", "device", b.Name, "partitions", count, "partitionSize", partitionSize)
+		return b.CreatePartitions(count, partitionSize)
+	})
+	if err != nil || !partitioned {
+		return false, 0, 0, err
+	}
+	return true, count, partitionSize, nil
+}
+
+// WithUnprovisionedDevice runs fn while holding the PV creation lock of the device, so that the device
+// is not provisioned while fn changes it. fn is not run if a symlink in symlinkDir already points to
+// the device because it backs a PV. It returns whether fn was run.
+func (b BlockDevice) WithUnprovisionedDevice(symlinkDir string, fn func() error) (bool, error) {
+	devPath, err := b.GetDevPath()
+	if err != nil {
+		return false, err
+	}
+	pvLock, pvLocked, existingSymlinks, lockErr := GetPVCreationLock(devPath, symlinkDir)
+	defer func() {
+		if err := pvLock.Unlock(); err != nil {
+			klog.ErrorS(err, "failed to unlock device", "disk", devPath)
+		}
+	}()
+	if len(existingSymlinks) > 0 {
+		klog.InfoS("not changing device that is already provisioned", "device", b.Name, "symlinks", existingSymlinks)
+		return false, nil
+	} else if !pvLocked {
+		if lockErr != nil {
+			return false, lockErr
+		}
+		return false, fmt.Errorf("failed to acquire lock for %s", devPath)
+	}
+	return true, fn()
+}
+
+// WithUnprovisionedDevices runs fn while holding the PV creation locks of all devices, see
+// WithUnprovisionedDevice. fn is not run if any of the devices backs a PV. It returns whether fn was run.
+func WithUnprovisionedDevices(devices []BlockDevice, symlinkDir string, fn func() error) (bool, error) {
+	if len(devices) == 0 {
+		return true, fn()
+	}
+	ran := false
+	_, err := devices[0].WithUnprovisionedDevice(symlinkDir, func() error {
+		var err error
+		ran, err = WithUnprovisionedDevices(devices[1:], symlinkDir, fn)
+		return err
+	})
+	return ran, err
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestIsLSOPartition(t *testing.T) {
+	assert.True(t, BlockDevice{Type: "part", PartLabel: "lso-part-1"}.IsLSOPartition())
+	assert.False(t, BlockDevice{Type: "part", PartLabel: "data"}.IsLSOPartition())
+	assert.False(t, BlockDevice{Type: "disk", PartLabel: "lso-part-1"}.IsLSOPartition())
+	assert.True(t, BlockDevice{Type: "disk"}.IsPartitionable())
+	assert.True(t, BlockDevice{Type: "mpath"}.IsPartitionable())
+	assert.False(t, BlockDevice{Type: "part"}.IsPartitionable())
+}
+
+func TestPartitionLayout(t *testing.T) {
+	const gib = 1024 * mebibyte
+	testcases := []struct {
+		label         string
+		diskSize      int64
+		count         int32
+		partitionSize int64
+		expectedCount int
+		expectedSize  int64
+		expectedError bool
+	}{
+		{label: "equal partitions", diskSize: 100 * gib, count: 4, expectedCount: 4, expectedSize: 25*gib - mebibyte},
+		{label: "fixed size", diskSize: 100 * gib, partitionSize: 30 * gib, expectedCount: 3, expectedSize: 30 * gib},
+		{label: "fixed size rounded down to MiB", diskSize: 10 * gib, partitionSize: 3*gib + 1000, expectedCount: 3, expectedSize: 3 * gib},
+		{label: "count capped", diskSize: 100 * gib, count: 200, expectedCount: MaxPartitions, expectedSize: 100*gib/MaxPartitions - mebibyte},
+		{label: "size too large", diskSize: 10 * gib, partitionSize: 10 * gib, expectedError: true},
+		{label: "size below MiB", diskSize: 10 * gib, partitionSize: 1000, expectedError: true},
+		{label: "nothing set", diskSize: 10 * gib, expectedError: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			count, size, err := PartitionLayout(tc.diskSize, tc.count, tc.partitionSize)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCount, count)
+			assert.Equal(t, tc.expectedSize, size)
+			assert.LessOrEqual(t, int64(count)*size, tc.diskSize-partitionTableOverhead)
+		})
+	}
+}
+
+func TestCreatePartitions(t *testing.T) {
+	var args []string
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, cmdArgs ...string) utilexec.Cmd {
+				args = append([]string{cmd}, cmdArgs...)
+				return &testingexec.FakeCmd{
+					CombinedOutputScript: []testingexec.FakeAction{
+						func() ([]byte, []byte, error) { return nil, nil, nil },
+					},
+				}
+			},
+		},
+	}
+
+	err := BlockDevice{KName: "sdb"}.CreatePartitions(2, 4*mebibyte)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"sgdisk", "--clear",
+		"--new=1:0:+4096K", "--change-name=1:lso-part-1",
+		"--new=2:0:+4096K", "--change-name=2:lso-part-2",
+		"/dev/sdb",
+	}, args)
+}
+
+func TestPartitionDiskTooSmall(t *testing.T) {
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	// only find is run to look for existing symlinks, sgdisk must not be run
+	CmdExecutor = &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, cmdArgs ...string) utilexec.Cmd {
+				assert.Equal(t, "find", cmd)
+				return &testingexec.FakeCmd{
+					CombinedOutputScript: []testingexec.FakeAction{
+						func() ([]byte, []byte, error) { return nil, nil, nil },
+					},
+				}
+			},
+		},
+	}
+	disk := BlockDevice{KName: "null", Type: "disk", Size: "1048576"}
+	partitioning := localv1.Partitioning{PartitionSize: ptr.To(resource.MustParse("1Gi"))}
+	partitioned, _, _, err := disk.PartitionDisk(partitioning, t.TempDir(), false)
+	assert.Error(t, err)
+	assert.False(t, partitioned)
+}
+
+func TestPartitionDiskWithSignature(t *testing.T) {
+	oldExecutor := CmdExecutor
+	defer func() { CmdExecutor = oldExecutor }()
+	// no command must be run, the fake panics if one is
+	CmdExecutor = &testingexec.FakeExec{}
+	partitioning := localv1.Partitioning{Count: ptr.To[int32](2)}
+
+	for _, disk := range []BlockDevice{
+		{Name: "sdb", KName: "sdb", Type: "disk", Size: "10737418240", FSType: "xfs"},
+		{Name: "sdc", KName: "sdc", Type: "disk", Size: "10737418240", PTType: "gpt"},
+	} {
+		partitioned, _, _, err := disk.PartitionDisk(partitioning, t.TempDir(), false)
+		assert.ErrorContains(t, err, "refusing to partition "+disk.Name)
+		assert.False(t, partitioned)
+	}
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"cmp"
+	"path/filepath"
+	"strconv"
+)
+
+// GetSlot returns the name of the first /dev/disk/by-path link of the device in natural order,
+// which identifies the controller, port and enclosure slot the device is attached to.
+// Returns an empty string if the device has no by-path link.
+func (b *BlockDevice) GetSlot() (string, error) {
+	links, err := b.GetValidByPathSymlinks()
+	if err != nil {
+		return "", err
+	}
+	slot := ""
+	for _, link := range links {
+		name := filepath.Base(link)
+		if slot == "" || CompareNatural(name, slot) < 0 {
+			slot = name
+		}
+	}
+	return slot, nil
+}
+
+// CompareNatural compares a and b like strings, except that runs of digits are compared as numbers,
+// so that "pci-0000:3b:00.0-sas-phy2" sorts before "pci-0000:3b:00.0-sas-phy10"
+func CompareNatural(a, b string) int {
+	for a != "" && b != "" {
+		digitsA, digitsB := leadingDigits(a), leadingDigits(b)
+		if digitsA != "" && digitsB != "" {
+			numA, errA := strconv.ParseUint(digitsA, 10, 64)
+			numB, errB := strconv.ParseUint(digitsB, 10, 64)
+			if errA == nil && errB == nil && numA != numB {
+				return cmp.Compare(numA, numB)
+			}
+			if c := cmp.Compare(digitsA, digitsB); c != 0 {
+				return c
+			}
+			a, b = a[len(digitsA):], b[len(digitsB):]
+			continue
+		}
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
@@ -0,0 +1,16 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareNatural(t *testing.T) {
+	assert.Negative(t, CompareNatural("phy2", "phy10"))
+	assert.Positive(t, CompareNatural("phy10", "phy9"))
+	assert.Zero(t, CompareNatural("pci-0000:3b:00.0", "pci-0000:3b:00.0"))
+	assert.Negative(t, CompareNatural("lun-0", "lun-0-part1"))
+	assert.Negative(t, CompareNatural("phy01", "phy1"))
+	assert.Negative(t, CompareNatural("ata-1", "sas-1"))
+}
@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// systemMountPoints are the host mount points whose devices must never be provisioned
+	systemMountPoints = []string{"/", "/boot", "/var", "/sysroot"}
+	// rootMountPoints are the system mount points at least one of which must be on a block device.
+	// On RHCOS "/" is a composefs overlay and the deployment is on the device mounted at /sysroot.
+	rootMountPoints = []string{"/", "/sysroot"}
+	// sysClassBlockDir has a symlink for every block device, including partitions
+	sysClassBlockDir = "/sys/class/block"
+	// sysDevBlockDir has a symlink named major:minor for every block device
+	sysDevBlockDir = "/sys/dev/block"
+)
+
+// GetSystemDevices returns the kernel names of the devices the host operating system runs from.
+// It starts with the devices mounted at /, /boot, /var and /sysroot and walks down to the devices
+// they are built from (slaves) and their parent disks. The other partitions of those disks and
+// the devices built on top of any of them (holders) are added as well, but the walk never goes down
+// again from there, so that devices which merely share a holder with a system device are not added.
+// This covers partitions, dm, LVM, mdraid and multipath stacks.
+// All logical volumes of a volume group the system is built from, e.g. swap or /home next to the root
+// filesystem, and all physical volumes of that volume group are system devices as well. Logical volumes
+// of other volume groups are not, even if one of their physical volumes is on a system disk.
+// It returns an error rather than an incomplete set if / or /sysroot can't be traced to a block device.
+func GetSystemDevices() (sets.Set[string], error) {
+	mounted, err := getSystemMountDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	// the devices the system mounts are built from, including the other logical volumes of their
+	// volume groups, which may be the only users of some physical volumes
+	systemDevices := sets.New[string]()
+	systemVolumeGroups := sets.New[string]()
+	queue := sets.List(mounted)
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if systemDevices.Has(name) {
+				continue
+			}
+			systemDevices.Insert(name)
+			if vgUUID := lvmVolumeGroupUUID(name); vgUUID != "" {
+				systemVolumeGroups.Insert(vgUUID)
+			}
+			lower, err := getLowerDevices(name)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, lower...)
+		}
+		queue, err = getLogicalVolumes(systemVolumeGroups, systemDevices)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// and everything that shares a disk with them or is built on top of them
+	queue = sets.List(systemDevices)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		upper, err := getUpperDevices(name, systemVolumeGroups)
+		if err != nil {
+			return nil, err
+		}
+		for _, upperName := range upper {
+			if !systemDevices.Has(upperName) {
+				systemDevices.Insert(upperName)
+				queue = append(queue, upperName)
+			}
+		}
+	}
+	return systemDevices, nil
+}
+
+// getLogicalVolumes returns the device mapper devices that LVM created for the volume groups
+// and that are not in known yet
+func getLogicalVolumes(volumeGroups, known sets.Set[string]) ([]string, error) {
+	if volumeGroups.Len() == 0 {
+		return nil, nil
+	}
+	names, err := globDeviceNames(filepath.Join(sysClassBlockDir, "dm-*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device mapper devices: %w", err)
+	}
+	logicalVolumes := make([]string, 0)
+	for _, name := range names {
+		if !known.Has(name) && volumeGroups.Has(lvmVolumeGroupUUID(name)) {
+			logicalVolumes = append(logicalVolumes, name)
+		}
+	}
+	return logicalVolumes, nil
+}
+
+// getSystemMountDevices returns the kernel names of the devices mounted at systemMountPoints.
+// It fails if a device can't be resolved or if none of rootMountPoints is on a block device,
+// so that an unexpected mount layout never makes the system devices look like free disks.
+func getSystemMountDevices() (sets.Set[string], error) {
+	data, err := os.ReadFile(mountFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", mountFile, err)
+	}
+
+	devices := sets.New[string]()
+	rootFound := false
+	for _, mountInfo := range strings.Split(string(data), "\n") {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(mountInfo)
+		separator := -1
+		for i, field := range fields {
+			if field == "-" {
+				separator = i
+				break
+			}
+		}
+		if len(fields) < 5 || separator < 0 || len(fields) < separator+3 {
+			continue
+		}
+		mountPoint := fields[4]
+		isSystemMount := false
+		for _, systemMountPoint := range systemMountPoints {
+			if mountPoint == systemMountPoint {
+				isSystemMount = true
+				break
+			}
+		}
+		if !isSystemMount {
+			continue
+		}
+
+		name, err := resolveMountDevice(fields[2], fields[separator+2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve device of system mount point %s: %w", mountPoint, err)
+		}
+		if name == "" {
+			klog.V(4).InfoS("system mount point is not on a block device", "mountPoint", mountPoint, "source", fields[separator+2])
+			continue
+		}
+		devices.Insert(name)
+		for _, rootMountPoint := range rootMountPoints {
+			if mountPoint == rootMountPoint {
+				rootFound = true
+			}
+		}
+	}
+	if !rootFound {
+		return nil, fmt.Errorf("none of the mount points %v is on a block device in %s", rootMountPoints, mountFile)
+	}
+	return devices, nil
+}
+
+// resolveMountDevice returns the kernel name of the device with majorMinor,
+// or of the source device path for filesystems that report an anonymous device number (btrfs, overlay)
+func resolveMountDevice(majorMinor, source string) (string, error) {
+	if !strings.HasPrefix(majorMinor, "0:") {
+		devPath, err := FilePathEvalSymLinks(filepath.Join(sysDevBlockDir, majorMinor))
+		if err != nil {
+			return "", err
+		}
+		return filepath.Base(devPath), nil
+	}
+	if !strings.HasPrefix(source, "/dev/") {
+		return "", nil
+	}
+	devPath, err := FilePathEvalSymLinks(source)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(devPath), nil
+}
+
+// getLowerDevices returns the slaves and the parent disk of the device
+func getLowerDevices(name string) ([]string, error) {
+	sysDevDir := filepath.Join(sysClassBlockDir, name)
+	lower, err := globDeviceNames(filepath.Join(sysDevDir, "slaves", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slaves of %q: %w", name, err)
+	}
+
+	// the sysfs directory of a partition is nested in the directory of its disk
+	if _, err := os.Stat(filepath.Join(sysDevDir, "partition")); err == nil {
+		devPath, err := FilePathEvalSymLinks(sysDevDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find parent of partition %q: %w", name, err)
+		}
+		lower = append(lower, filepath.Base(filepath.Dir(devPath)))
+	}
+	return lower, nil
+}
+
+// getUpperDevices returns the holders and partitions of the device.
+// Holders created by LVM are skipped unless they belong to one of the system volume groups, see GetSystemDevices.
+func getUpperDevices(name string, systemVolumeGroups sets.Set[string]) ([]string, error) {
+	sysDevDir := filepath.Join(sysClassBlockDir, name)
+	holders, err := globDeviceNames(filepath.Join(sysDevDir, "holders", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holders of %q: %w", name, err)
+	}
+	upper := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		if vgUUID := lvmVolumeGroupUUID(holder); vgUUID == "" || systemVolumeGroups.Has(vgUUID) {
+			upper = append(upper, holder)
+		}
+	}
+
+	// partitions are subdirectories named after the disk, e.g. sda/sda1 or nvme0n1/nvme0n1p1
+	partitions, err := globDeviceNames(filepath.Join(sysDevDir, name+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %q: %w", name, err)
+	}
+	return append(upper, partitions...), nil
+}
+
+// globDeviceNames returns the base names of the paths matching pattern
+func globDeviceNames(pattern string) ([]string, error) {
+	paths, err := FilePathGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+	return names, nil
+}
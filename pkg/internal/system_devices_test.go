@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// newFakeSysfs creates a sysfs tree with the root filesystem on the LVM logical volume dm-0,
+// which is built from the mdraid mirror md127 of sda2 and sdc1, and /boot on sda1.
+// dm-1 is another logical volume in the same volume group and dm-3 is the swap of that volume group,
+// the only user of its physical volume nvme0n1. dm-2 is in a data volume group on sda4 and sdb is a data disk.
+// md126 mirrors sda3 on the boot disk with the data disk sdd.
+func newFakeSysfs(t *testing.T) string {
+	root := t.TempDir()
+	devices := map[string]string{
+		"sda":     "devices/pci0000:00/block/sda",
+		"sda1":    "devices/pci0000:00/block/sda/sda1",
+		"sda2":    "devices/pci0000:00/block/sda/sda2",
+		"sda3":    "devices/pci0000:00/block/sda/sda3",
+		"sda4":    "devices/pci0000:00/block/sda/sda4",
+		"sdb":     "devices/pci0000:00/block/sdb",
+		"sdb1":    "devices/pci0000:00/block/sdb/sdb1",
+		"sdc":     "devices/pci0000:00/block/sdc",
+		"sdc1":    "devices/pci0000:00/block/sdc/sdc1",
+		"sdd":     "devices/pci0000:00/block/sdd",
+		"md126":   "devices/virtual/block/md126",
+		"md127":   "devices/virtual/block/md127",
+		"dm-0":    "devices/virtual/block/dm-0",
+		"dm-1":    "devices/virtual/block/dm-1",
+		"dm-2":    "devices/virtual/block/dm-2",
+		"dm-3":    "devices/virtual/block/dm-3",
+		"nvme0n1": "devices/pci0000:01/block/nvme0n1",
+	}
+	partitions := []string{"sda1", "sda2", "sda3", "sda4", "sdb1", "sdc1"}
+	slaves := map[string][]string{
+		"md126": {"sda3", "sdd"},
+		"md127": {"sda2", "sdc1"},
+		"dm-0":  {"md127"},
+		"dm-1":  {"md127"},
+		"dm-2":  {"sda4"},
+		"dm-3":  {"nvme0n1"},
+	}
+	holders := map[string][]string{
+		"sda3":    {"md126"},
+		"sdd":     {"md126"},
+		"sda2":    {"md127"},
+		"sdc1":    {"md127"},
+		"sda4":    {"dm-2"},
+		"md127":   {"dm-0", "dm-1"},
+		"nvme0n1": {"dm-3"},
+	}
+	dmUUIDs := map[string]string{
+		"dm-0": "LVM-Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uNaB3dE5fG7hJ9kL1mN3pQ5rS7tU9vW1xY",
+		"dm-1": "LVM-Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uNcD4eF6gH8iJ0kL2mN4oP6qR8sT0uV2wX",
+		"dm-2": "LVM-Hq7wNc2KpX9aLm4RtV6yB1dF3gJ5sZ8eyT2uI4oP6aS8dF0gH2jK4lZ6xC8vB0nM",
+		"dm-3": "LVM-Zs0pGfqL3hBkDn8xW4cYtRvJ2mAoE9uNeF5gH7iJ9kL1mN3oP5qR7sT9uV1wX3yZ",
+	}
+	majorMinors := map[string]string{
+		"sda1": "8:1",
+		"sdb1": "8:17",
+		"dm-0": "253:0",
+	}
+
+	mkdir := func(path string) {
+		assert.NoError(t, os.MkdirAll(path, 0755))
+	}
+	mkdir(filepath.Join(root, "class", "block"))
+	mkdir(filepath.Join(root, "dev", "block"))
+	for name, path := range devices {
+		devDir := filepath.Join(root, path)
+		mkdir(filepath.Join(devDir, "slaves"))
+		mkdir(filepath.Join(devDir, "holders"))
+		assert.NoError(t, os.Symlink(devDir, filepath.Join(root, "class", "block", name)))
+	}
+	for _, name := range partitions {
+		assert.NoError(t, os.WriteFile(filepath.Join(root, devices[name], "partition"), []byte("1"), 0644))
+	}
+	for name, deps := range slaves {
+		for _, dep := range deps {
+			assert.NoError(t, os.Symlink(filepath.Join(root, devices[dep]), filepath.Join(root, devices[name], "slaves", dep)))
+		}
+	}
+	for name, deps := range holders {
+		for _, dep := range deps {
+			assert.NoError(t, os.Symlink(filepath.Join(root, devices[dep]), filepath.Join(root, devices[name], "holders", dep)))
+		}
+	}
+	for name, uuid := range dmUUIDs {
+		mkdir(filepath.Join(root, devices[name], "dm"))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, devices[name], "dm", "uuid"), []byte(uuid+"\n"), 0644))
+	}
+	for name, majorMinor := range majorMinors {
+		assert.NoError(t, os.Symlink(filepath.Join(root, devices[name]), filepath.Join(root, "dev", "block", majorMinor)))
+	}
+	return root
+}
+
+func TestGetSystemDevices(t *testing.T) {
+	origMountFile := mountFile
+	origSysClassBlockDir := sysClassBlockDir
+	origSysDevBlockDir := sysDevBlockDir
+	origGlob := FilePathGlob
+	origEval := FilePathEvalSymLinks
+	defer func() {
+		mountFile = origMountFile
+		sysClassBlockDir = origSysClassBlockDir
+		sysDevBlockDir = origSysDevBlockDir
+		FilePathGlob = origGlob
+		FilePathEvalSymLinks = origEval
+	}()
+	// the fake sysfs is a real directory tree
+	FilePathGlob = filepath.Glob
+	FilePathEvalSymLinks = filepath.EvalSymlinks
+
+	root := newFakeSysfs(t)
+	sysClassBlockDir = filepath.Join(root, "class", "block")
+	sysDevBlockDir = filepath.Join(root, "dev", "block")
+
+	mountFile = filepath.Join(root, "mountinfo")
+	mountInfo := `1 0 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/root rw
+2 1 8:1 / /boot rw,relatime shared:2 - ext4 /dev/sda1 rw
+3 1 0:22 / /proc rw,relatime shared:3 - proc proc rw
+4 1 8:17 / /var/mnt/data rw,relatime shared:4 - xfs /dev/sdb1 rw
+5 1 0:33 / /var rw,relatime shared:5 - overlay overlay rw
+`
+	assert.NoError(t, os.WriteFile(mountFile, []byte(mountInfo), 0644))
+
+	systemDevices, err := GetSystemDevices()
+	assert.NoError(t, err)
+	// dm-1 and dm-3 share the volume group with the root filesystem and so does nvme0n1.
+	// dm-2 is in another volume group, although its physical volume is on the boot disk.
+	// md126 is on the boot disk, but its other member sdd only shares it with the system.
+	assert.Equal(t, sets.New("dm-0", "dm-1", "dm-3", "md126", "md127", "nvme0n1",
+		"sda", "sda1", "sda2", "sda3", "sda4", "sdc", "sdc1"), systemDevices)
+
+	// "/" is a composefs overlay, the deployment is on /sysroot
+	mountInfo = `1 0 0:30 / / ro,relatime shared:1 - overlay composefs ro,lowerdir=/sysroot/ostree
+2 1 253:0 / /sysroot ro,relatime shared:2 - xfs /dev/mapper/root rw
+3 1 8:1 / /boot rw,relatime shared:3 - ext4 /dev/sda1 rw
+`
+	assert.NoError(t, os.WriteFile(mountFile, []byte(mountInfo), 0644))
+	systemDevices, err = GetSystemDevices()
+	assert.NoError(t, err)
+	assert.True(t, systemDevices.HasAll("dm-0", "md127", "sda", "sda1", "sdc"))
+
+	// the root filesystem is not on a block device and there is no /sysroot
+	mountInfo = `1 0 0:30 / / ro,relatime shared:1 - overlay composefs ro,lowerdir=/sysroot/ostree
+3 1 8:1 / /boot rw,relatime shared:3 - ext4 /dev/sda1 rw
+`
+	assert.NoError(t, os.WriteFile(mountFile, []byte(mountInfo), 0644))
+	_, err = GetSystemDevices()
+	assert.Error(t, err)
+
+	// the device of a system mount point can't be resolved
+	mountInfo = `1 0 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/root rw
+2 1 8:2 / /boot rw,relatime shared:2 - ext4 /dev/sda1 rw
+`
+	assert.NoError(t, os.WriteFile(mountFile, []byte(mountInfo), 0644))
+	_, err = GetSystemDevices()
+	assert.Error(t, err)
+
+	// no system mount points
+	assert.NoError(t, os.WriteFile(mountFile, []byte("3 1 0:22 / /proc rw,relatime shared:3 - proc proc rw\n"), 0644))
+	_, err = GetSystemDevices()
+	assert.Error(t, err)
+
+	// unreadable mountinfo
+	mountFile = filepath.Join(root, "missing")
+	_, err = GetSystemDevices()
+	assert.Error(t, err)
+}
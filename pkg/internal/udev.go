@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	// UdevModel is the udev property holding the untruncated device model
+	UdevModel = "ID_MODEL"
+	// UdevVendor is the udev property holding the untruncated device vendor
+	UdevVendor = "ID_VENDOR"
+)
+
+// udevDataDir is the udev database directory, bind-mounted from the host
+var udevDataDir = "/run/udev/data"
+
+// LoadUdevProperties reads the properties of the device from the udev database
+// into UdevProperties. The device needs to have MajMin set.
+func (b *BlockDevice) LoadUdevProperties() error {
+	if b.MajMin == "" {
+		return fmt.Errorf("major:minor numbers of device %q are unknown", b.KName)
+	}
+	properties, err := readUdevProperties(filepath.Join(udevDataDir, "b"+b.MajMin))
+	if err != nil {
+		return err
+	}
+	b.UdevProperties = properties
+	return nil
+}
+
+// UdevSettled checks whether udev has processed all queued events, without waiting for them.
+// udevadm is run in the mount namespace of the host, where the udev control socket is.
+func UdevSettled() (bool, error) {
+	if _, err := CmdExecutor.LookPath("nsenter"); err != nil {
+		return false, fmt.Errorf("udevadm settle requires nsenter: %w", err)
+	}
+	// with a timeout of 0, udevadm settle only reports whether the event queue is empty
+	cmd := CmdExecutor.Command("nsenter", "--mount=/proc/1/ns/mnt", "--", "udevadm", "settle", "--timeout=0")
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.ExitStatus() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to run udevadm settle: %w, output: %q", err, output)
+	}
+	return true, nil
+}
+
+// readUdevProperties parses the `E:KEY=VALUE` property lines of a udev database file
+func readUdevProperties(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open udev database entry %q: %w", path, err)
+	}
+	defer file.Close()
+
+	properties := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		property, ok := strings.CutPrefix(scanner.Text(), "E:")
+		if !ok {
+			continue
+		}
+		key, value, ok := strings.Cut(property, "=")
+		if !ok || key == "" {
+			continue
+		}
+		properties[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read udev database entry %q: %w", path, err)
+	}
+	return properties, nil
+}
+
+// GetModel returns the model as reported by lsblk, falling back to
+// the udev database when lsblk reports an empty model
+func (b BlockDevice) GetModel() string {
+	if b.Model != "" {
+		return b.Model
+	}
+	return b.UdevProperties[UdevModel]
+}
+
+// GetVendor returns the vendor as reported by lsblk, falling back to
+// the udev database when lsblk reports an empty vendor
+func (b BlockDevice) GetVendor() string {
+	if b.Vendor != "" {
+		return b.Vendor
+	}
+	return b.UdevProperties[UdevVendor]
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	utilexec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestLoadUdevProperties(t *testing.T) {
+	origUdevDataDir := udevDataDir
+	defer func() {
+		udevDataDir = origUdevDataDir
+	}()
+	udevDataDir = t.TempDir()
+
+	entry := `S:disk/by-id/virtio-0123456789
+S:disk/by-path/pci-0000:00:05.0
+W:3
+I:1234567
+E:ID_PATH=pci-0000:00:05.0
+E:ID_SERIAL=0123456789
+E:ID_MODEL_ID=0x1af4
+E:DM_UUID=
+E:ID_FS_LABEL=a=b
+G:systemd
+`
+	err := os.WriteFile(filepath.Join(udevDataDir, "b252:0"), []byte(entry), 0644)
+	assert.NoError(t, err)
+
+	dev := BlockDevice{KName: "vda", MajMin: "252:0"}
+	err = dev.LoadUdevProperties()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ID_PATH":     "pci-0000:00:05.0",
+		"ID_SERIAL":   "0123456789",
+		"ID_MODEL_ID": "0x1af4",
+		"DM_UUID":     "",
+		"ID_FS_LABEL": "a=b",
+	}, dev.UdevProperties)
+
+	// no database entry
+	dev = BlockDevice{KName: "vdb", MajMin: "252:16"}
+	assert.Error(t, dev.LoadUdevProperties())
+	assert.Nil(t, dev.UdevProperties)
+
+	// unknown major:minor
+	dev = BlockDevice{KName: "vdc"}
+	assert.Error(t, dev.LoadUdevProperties())
+}
+
+func TestGetModelAndVendor(t *testing.T) {
+	dev := BlockDevice{
+		Model:          "VBOX HARDDISK",
+		Vendor:         "ATA",
+		UdevProperties: map[string]string{UdevModel: "VBOX_HARDDISK_2", UdevVendor: "ATA_2"},
+	}
+	assert.Equal(t, "VBOX HARDDISK", dev.GetModel())
+	assert.Equal(t, "ATA", dev.GetVendor())
+
+	dev.Model = ""
+	dev.Vendor = ""
+	assert.Equal(t, "VBOX_HARDDISK_2", dev.GetModel())
+	assert.Equal(t, "ATA_2", dev.GetVendor())
+
+	dev.UdevProperties = nil
+	assert.Equal(t, "", dev.GetModel())
+	assert.Equal(t, "", dev.GetVendor())
+}
+
+func TestUdevSettled(t *testing.T) {
+	testcases := []struct {
+		label         string
+		err           error
+		lookPathErr   error
+		expected      bool
+		expectedError bool
+	}{
+		{label: "queue is empty", expected: true},
+		{label: "events are queued", err: &testingexec.FakeExitError{Status: 1}},
+		{label: "udevadm fails", err: &testingexec.FakeExitError{Status: 2}, expectedError: true},
+		{label: "nsenter is missing", lookPathErr: fmt.Errorf("not found"), expectedError: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			var args []string
+			fakeCmd := &testingexec.FakeCmd{
+				CombinedOutputScript: []testingexec.FakeAction{
+					func() ([]byte, []byte, error) { return nil, nil, tc.err },
+				},
+			}
+			oldExecutor := CmdExecutor
+			defer func() { CmdExecutor = oldExecutor }()
+			CmdExecutor = &testingexec.FakeExec{
+				CommandScript: []testingexec.FakeCommandAction{
+					func(cmd string, cmdArgs ...string) utilexec.Cmd {
+						args = append([]string{cmd}, cmdArgs...)
+						return fakeCmd
+					},
+				},
+				LookPathFunc: func(file string) (string, error) {
+					return "/usr/bin/" + file, tc.lookPathErr
+				},
+			}
+
+			settled, err := UdevSettled()
+			if tc.lookPathErr == nil {
+				assert.Equal(t, []string{"nsenter", "--mount=/proc/1/ns/mnt", "--", "udevadm", "settle", "--timeout=0"}, args)
+			} else {
+				assert.Empty(t, args)
+			}
+			assert.Equal(t, tc.expected, settled)
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package localmetrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift/local-storage-operator/assets"
+	"github.com/openshift/local-storage-operator/pkg/common"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPrometheusRuleAsset(t *testing.T) {
+	// a group with duplicate keys silently loses all but one of them when it is decoded leniently
+	file, err := assets.ReadFile(common.PrometheusRuleTemplate)
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.UnmarshalStrict(file, &monitoringv1.PrometheusRule{}))
+
+	rule, err := getPrometheusRule(strings.NewReplacer("${OBJECT_NAMESPACE}", "openshift-local-storage", "${DAEMONSET_NAME}", "diskmaker-manager"))
+	assert.NoError(t, err)
+	alerts := make([]string, 0)
+	for _, group := range rule.Spec.Groups {
+		for _, r := range group.Rules {
+			alerts = append(alerts, r.Alert)
+		}
+	}
+	assert.Contains(t, alerts, "LSODegradedRaidArray")
+	assert.Contains(t, alerts, "LSONoStableLocalVolumePath")
+}
@@ -27,6 +27,11 @@ var (
 		Help: "Total symlinks that became orphan after updating the Local Volume Set filter",
 	}, []string{"nodeName", "storageClass"})
 
+	metricLocalVolumeSetDegradedRaidArrays = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lso_lvset_degraded_raid_array_count",
+		Help: "Total software RAID arrays assembled by the Local Volume Set controller that are missing members",
+	}, []string{"nodeName", "storageClass"})
+
 	metricLocalVolumeSetDeletionTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "lso_lvset_deletion_timestamp",
 		Help: "Timestamp when the LocalVolumeSet was marked for deletion",
@@ -48,6 +53,12 @@ var (
 		Help: "Total device paths in LocalVolume spec that cannot be resolved on the node (e.g. symlink removed after OS upgrade)",
 	}, []string{"nodeName", "storageClass"})
 
+	// metrics shared by the LocalVolume and LocalVolumeSet controllers
+	metricBlockedDevices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lso_blocked_device_count",
+		Help: "Total devices matching a LocalVolume or LocalVolumeSet that were skipped because they are listed in a LocalVolumeDeviceBlocklist",
+	}, []string{"nodeName", "storageClass"})
+
 	LVDMetricsList = []prometheus.Collector{
 		metricDiscoveredDevicesByLocalVolumeDiscovery,
 	}
@@ -56,10 +67,12 @@ var (
 		metricLocalVolumeSetProvisionedPVs,
 		metricLocalVolumeSetUnmatchedDisks,
 		metricLocalVolumeSetOrphanedSymlinks,
+		metricLocalVolumeSetDegradedRaidArrays,
 		metricLocalVolumeSetDeletionTimestamp,
 		metricLocalVolumeProvisionedPVs,
 		metricLocalVolumeOrphanedSymlinks,
 		metricLocalVolumeMissingDevicePaths,
+		metricBlockedDevices,
 	}
 )
 
@@ -87,6 +100,12 @@ func SetLVSOrphanedSymlinksMetric(nodeName, storageClassName string, count int)
 		Set(float64(count))
 }
 
+func SetLVSDegradedRaidArrayMetric(nodeName, storageClassName string, count int) {
+	metricLocalVolumeSetDegradedRaidArrays.
+		With(prometheus.Labels{"nodeName": nodeName, "storageClass": storageClassName}).
+		Set(float64(count))
+}
+
 func SetLVSDeletionTimestampMetric(lvSetName string, ts int64) {
 	metricLocalVolumeSetDeletionTimestamp.
 		With(prometheus.Labels{"lvSetName": lvSetName}).
@@ -116,6 +135,18 @@ func SetLVMissingDevicePathMetric(nodeName, storageClassName string, count int)
 		Set(float64(count))
 }
 
+func SetBlockedDeviceMetric(nodeName, storageClassName string, count int) {
+	metricBlockedDevices.
+		With(prometheus.Labels{"nodeName": nodeName, "storageClass": storageClassName}).
+		Set(float64(count))
+}
+
+func BlockedDeviceGauge(nodeName, storageClassName string) prometheus.Gauge {
+	return metricBlockedDevices.With(prometheus.Labels{
+		"nodeName": nodeName, "storageClass": storageClassName,
+	})
+}
+
 func LVMissingDevicePathGauge(nodeName, storageClassName string) prometheus.Gauge {
 	return metricLocalVolumeMissingDevicePaths.With(prometheus.Labels{
 		"nodeName": nodeName, "storageClass": storageClassName,